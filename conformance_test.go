@@ -18,13 +18,23 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"math/rand"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -33,22 +43,104 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	"sigs.k8s.io/ingress-controller-conformance/test/commands"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/backendports"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/backendreadiness"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/basicauth"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/bodysize"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/caching"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/cachingannotations"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/canary"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/compression"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/conditionalrequests"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/cors"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/customerrorpages"
 	"sigs.k8s.io/ingress-controller-conformance/test/conformance/defaultbackend"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/defaultbackendrules"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/defaultingressclass"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/dualstack"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/errorpages"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/externalauth"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/externalnameservices"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/forwardedheaders"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/hostnames"
 	"sigs.k8s.io/ingress-controller-conformance/test/conformance/hostrules"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/https"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/httpsredirect"
 	"sigs.k8s.io/ingress-controller-conformance/test/conformance/ingressclass"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/ingressclasschanges"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/ingressstatus"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/ingressupdates"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/invalidtlssecrets"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/keepalive"
 	"sigs.k8s.io/ingress-controller-conformance/test/conformance/loadbalancing"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/multipleingresses"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/pathprecedence"
 	"sigs.k8s.io/ingress-controller-conformance/test/conformance/pathrules"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/pathtypes"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/ratelimiting"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/requestrobustness"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/requesttargets"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/rewrite"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/rollingupdates"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/sessionaffinity"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/snifallback"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/sourceranges"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/timeouts"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/tlssecretrotation"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/trailers"
+	"sigs.k8s.io/ingress-controller-conformance/test/conformance/urlencoding"
 	"sigs.k8s.io/ingress-controller-conformance/test/http"
 	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
 	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes/templates"
+	"sigs.k8s.io/ingress-controller-conformance/test/metrics"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+	"sigs.k8s.io/ingress-controller-conformance/test/state"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
 )
 
 var (
 	godogFormat        string
 	godogTags          string
 	godogStopOnFailure bool
+	failFast           bool
 	godogNoColors      bool
 	godogOutput        string
+
+	profile               string
+	supportedFeaturesPath string
+	skipFilePath          string
+
+	featureFilter string
+	runFilter     string
+
+	parallel int
+
+	repeat int
+
+	slowestSteps int
+
+	suiteTimeout time.Duration
+
+	shuffle     string
+	shuffleSeed int64
+
+	// outputMu serializes the output of features run concurrently
+	outputMu sync.Mutex
+
+	// selectedFeatures and selectedScenarios are the features and scenarios selected by the filters
+	selectedFeatures  []string
+	selectedScenarios []*report.ScenarioDefinition
+
+	outputFormat string
+	reports      stringList
+
+	metricsAddress string
+
+	readinessChecks string
+
+	annotationMappingsPath string
+	annotationKeys         stringList
 )
 
 func TestMain(m *testing.M) {
@@ -57,34 +149,284 @@ func TestMain(m *testing.M) {
 
 	flag.StringVar(&godogFormat, "format", "pretty", "Set godog format to use. Valid values are pretty and cucumber")
 	flag.StringVar(&godogTags, "tags", "", "Tags for conformance test")
+	flag.StringVar(&featureFilter, "feature", "", "Comma separated list of features to run, as paths or names of the feature files (e.g. host_rules)")
+	flag.StringVar(&runFilter, "run", "", "Regular expression matching the names of the scenarios to run")
+	flag.StringVar(&supportedFeaturesPath, "supported-features", "", "YAML file declaring the optional features supported by the ingress controller. Scenarios of other optional features are not run")
+	flag.StringVar(&skipFilePath, "skip-file", "", "YAML file listing the known gaps of the ingress controller: scenarios to skip and scenarios expected to fail, with a reason and a tracking URL. They do not fail the suite")
+	flag.StringVar(&profile, "profile", string(report.Experimental), "Conformance profile to test, including the profiles below it. Valid values are core, extended and experimental")
 	flag.BoolVar(&godogStopOnFailure, "stop-on-failure", false, "Stop when failure is found")
+	flag.BoolVar(&failFast, "fail-fast", false, "Stop the suite after the first failure of a scenario of the core profile or of a feature tagged @required, cancelling the scenarios in progress. All the scenarios are run when false")
+	flag.StringVar(&shuffle, "shuffle", "off", "Randomize the order of the features and scenarios. Valid values are off, on (random seed) or the seed of a previous run")
+	flag.IntVar(&parallel, "parallel", 1, "Number of features run concurrently. Features tagged @serial, or setting a default backend, run alone after the other features")
+	flag.IntVar(&repeat, "repeat", 1, "Number of times the selected scenarios are run, to find flaky scenarios. The pass ratio and the duration of each scenario, and the scenarios whose results differ between runs, are reported")
+	flag.IntVar(&slowestSteps, "slowest-steps", 10, "Number of the slowest steps, with their time waiting for routes to converge, printed at the end of the run and included in the JSON report. Zero disables it")
 	flag.BoolVar(&godogNoColors, "no-colors", false, "Disable colors in godog output")
 	flag.StringVar(&godogOutput, "output-directory", ".", "Output directory for test reports")
-	flag.StringVar(&kubernetes.IngressClassValue, "ingress-class", "conformance", "Sets the value of the annotation kubernetes.io/ingress.class in Ingress definitions")
+	flag.Var(&reports, "report", "Report of the results, as format:path. Valid formats are html, json and junit. This flag can be repeated to write multiple reports")
+	flag.StringVar(&report.ControllerName, "controller-name", "", "Name of the ingress controller, included in the reports. Detected from the deployment of the ingress controller when empty")
+	flag.StringVar(&report.ControllerVersion, "controller-version", "", "Version of the ingress controller, included in the reports. Detected from the deployment of the ingress controller when empty")
+	flag.StringVar(&metricsAddress, "metrics-address", "", "Address to expose Prometheus metrics of the scenarios in the /metrics path (e.g. :9090). Disabled when empty")
+	flag.StringVar(&tracing.Endpoint, "otlp-endpoint", "", "Base URL of the OTLP/HTTP endpoint of an OpenTelemetry collector receiving a trace of each scenario (e.g. http://localhost:4318). Disabled when empty")
+	flag.StringVar(&outputFormat, "output", "", "Additional format of the results written to the output directory. Valid values are sonobuoy")
+	flag.StringVar(&kubernetes.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. The in-cluster configuration or the default kubeconfig files are used when empty")
+	flag.StringVar(&kubernetes.KubeContext, "context", "", "Name of the kubeconfig context to use")
+	flag.StringVar(&kubernetes.ImpersonateUser, "as", "", "Username to impersonate in Kubernetes API requests")
+	flag.Var((*stringList)(&kubernetes.ImpersonateGroups), "as-group", "Group to impersonate in Kubernetes API requests. This flag can be repeated to specify multiple groups")
+	flag.StringVar(&kubernetes.API, "api", kubernetes.APIIngress, "API used to route the traffic of the scenarios. Valid values are ingress and gateway, which converts each Ingress to an equivalent Gateway and HTTPRoutes")
+	flag.StringVar(&kubernetes.GatewayClassName, "gateway-class", "conformance", "Sets the value of spec.gatewayClassName in the Gateways converted from the Ingresses when -api=gateway")
+	flag.StringVar(&kubernetes.IngressClassValue, "ingress-class", "conformance", "Sets the value of spec.ingressClassName in Ingress definitions without class. With a comma separated list of classes, the suite is run once per class and the results of the ingress controllers are compared")
+	flag.BoolVar(&kubernetes.EnableIngressClassAnnotation, "ingress-class-annotation", false, "Also set the legacy annotation kubernetes.io/ingress.class in Ingress definitions without class")
+	flag.StringVar(&kubernetes.ClusterDomain, "cluster-domain", "cluster.local", "DNS domain of the cluster, used to resolve the services referenced by ExternalName services and available as {{ .ClusterDomain }} in the manifests of the features")
+	flag.StringVar(&kubernetes.HostSuffix, "host-suffix", "", "Domain suffix of the hostnames, available as {{ .HostSuffix }} in the manifests of the features after a label unique to the run and the worker")
+	flag.StringVar(&annotationMappingsPath, "annotation-mappings", "", "YAML file translating the abstract annotations used by the Extended features to annotations of the ingress controller. The annotations of ingress-nginx are used when empty")
+	flag.Var(&annotationKeys, "annotation-key", "Key of the annotation of the ingress controller for an abstract annotation, as name=key (e.g. cors-enable=example.com/cors). This flag can be repeated")
 	flag.DurationVar(&kubernetes.WaitForIngressAddressTimeout, "wait-time-for-ingress-status", 5*time.Minute, "Maximum wait time for valid ingress status value")
+	flag.DurationVar(&kubernetes.WaitForIngressReadyTimeout, "wait-time-for-ingress-ready", 5*time.Minute, "Maximum wait time for the readiness checks of an Ingress")
+	flag.StringVar(&readinessChecks, "readiness-checks", "", "Comma separated list of checks run after an Ingress acquires an address. Valid values are annotation, conditions and probe")
+	flag.StringVar(&kubernetes.ReadinessAnnotation, "readiness-annotation", "", "Annotation, as key or key=value, set by the ingress controller on ready Ingresses")
+	flag.StringVar(&kubernetes.ReadinessConditionType, "readiness-condition", "Ready", "Type of the status condition set by the ingress controller on ready Ingresses")
 	flag.DurationVar(&kubernetes.WaitForEndpointsTimeout, "wait-time-for-ready", 5*time.Minute, "Maximum wait time for ready endpoints")
-	flag.BoolVar(&http.EnableDebug, "enable-http-debug", false, "Enable dump of requests and responses of HTTP requests (useful for debug)")
-	flag.BoolVar(&kubernetes.EnableOutputYamlDefinitions, "enable-output-yaml-definitions", false, "Dump yaml definitions of Kubernetes objects before creation")
-
+	flag.IntVar(&http.HTTPPort, "http-port", 80, "Port of the ingress controller used to send HTTP requests")
+	flag.IntVar(&http.HTTPSPort, "https-port", 443, "Port of the ingress controller used to send HTTPS requests")
+	flag.BoolVar(&http.ReuseConnections, "reuse-connections", true, "Reuse the connections to the ingress controller between requests (HTTP keep-alive), except in the scenarios that require new connections. When false, each request opens a new connection")
+	flag.IntVar(&http.MaxIdleConnsPerHost, "max-idle-connections", 4, "Maximum number of idle connections kept open per host between requests")
+	flag.DurationVar(&http.IdleConnTimeout, "idle-connection-timeout", 30*time.Second, "Maximum time an idle connection is kept open between requests")
+	flag.DurationVar(&http.TLSHandshakeTimeout, "tls-handshake-timeout", 10*time.Second, "Maximum duration of the TLS handshake of the connections to the ingress controller")
+	flag.DurationVar(&http.HTTPClientTimeout, "request-timeout", 10*time.Second, "Maximum duration of each request to the ingress controller, including reading the response body")
+	flag.StringVar(&http.SourceAddress, "source-address", "", "Local IP address or network interface name used to send HTTP requests")
+	flag.IntVar(&http.ProxyProtocolVersion, "proxy-protocol", 0, "PROXY protocol version (1 or 2) sent to the ingress controller. Zero disables the PROXY protocol")
+	flag.StringVar(&http.IPFamily, "ip-family", http.IPFamilyDual, "Address family used to connect to the ingress controller. Valid values are ipv4, ipv6 and dual")
+	flag.BoolVar(&http.EnableDebug, "enable-http-debug", false, "Enable dump of requests and responses of HTTP requests, also enabled by -v=4 (useful for debug)")
+	flag.IntVar(&state.ConvergenceSuccesses, "convergence-successes", 3, "Number of consecutive equal responses required to consider a route converged")
+	flag.DurationVar(&state.ConvergenceMaxWait, "max-wait", 30*time.Second, "Maximum wait time for a route to converge")
+	flag.Float64Var(&state.MaxErrorRate, "max-error-rate", 0.01, "Maximum fraction of the requests sent in the background that can fail, e.g. during a rolling update of a backend")
+	flag.IntVar(&state.RateLimitStatusCode, "rate-limit-status-code", 429, "Status code of the responses to the requests rejected by a rate limit")
+	flag.IntVar(&state.CanaryWeightTolerance, "canary-weight-tolerance", 10, "Maximum difference, in percentage points, between the share of the requests served by a canary and its weight")
+	flag.DurationVar(&state.ScenarioTimeout, "scenario-timeout", 0, "Maximum duration of a scenario. Zero means no limit")
+	flag.DurationVar(&suiteTimeout, "suite-timeout", 0, "Maximum duration of the conformance suite. Requests in flight are cancelled and no more features are run when it expires. Zero means no limit")
+	flag.DurationVar(&state.ConvergenceRetryDelay, "retry-delay", time.Second, "Wait time between requests while a route has not converged")
+	flag.BoolVar(&kubernetes.EnableOutputYamlDefinitions, "enable-output-yaml-definitions", false, "Dump yaml definitions of Kubernetes objects before creation, also enabled by -v=3")
+	flag.StringVar(&kubernetes.ControllerSelector, "controller-selector", "", "Label selector of the ingress controller pods. Their logs are collected when a scenario fails, and their deployment is recorded in the reports")
+	flag.Int64Var(&kubernetes.ControllerLogLines, "controller-log-lines", 200, "Maximum number of log lines collected from each container of the ingress controller pods when a scenario fails")
+	flag.StringVar(&kubernetes.RunID, "run-id", "", "Identifier of the run, set in the "+kubernetes.RunIDLabel+" label of the objects created by the suite. Generated from the time of the run when empty")
+	flag.BoolVar(&kubernetes.KeepResources, "keep-resources", false, "Keep the namespaces created by the scenarios, and all the objects inside, to debug failures")
+
+	flag.Usage = usage
 	flag.Parse()
 
+	// the Ingresses rendered by the commands also use the API and the annotations of the ingress controller
+	if !sets.NewString(kubernetes.APIs...).Has(kubernetes.API) {
+		klog.Fatalf("the API '%v' is not supported", kubernetes.API)
+	}
+
+	if annotationMappingsPath != "" {
+		if err := kubernetes.LoadAnnotationMappings(annotationMappingsPath); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
+	if err := kubernetes.SetAnnotationKeys(annotationKeys); err != nil {
+		klog.Fatal(err)
+	}
+
+	// commands are run instead of the conformance tests
+	if flag.NArg() != 0 {
+		if err := commands.Run(flag.Arg(0), flag.Args()[1:]); err != nil {
+			klog.Fatal(err)
+		}
+
+		os.Exit(0)
+	}
+
+	// comparison mode, the suite is run once per IngressClass
+	if classes := strings.Split(kubernetes.IngressClassValue, ","); len(classes) > 1 {
+		os.Exit(runPerIngressClass(classes))
+	}
+
+	if kubernetes.RunID == "" {
+		kubernetes.RunID = kubernetes.NewRunID()
+	}
+
+	if err := kubernetes.ValidateRunID(kubernetes.RunID); err != nil {
+		klog.Fatal(err)
+	}
+
+	if http.ProxyProtocolVersion < 0 || http.ProxyProtocolVersion > 2 {
+		klog.Fatalf("the PROXY protocol version %v is not supported", http.ProxyProtocolVersion)
+	}
+
+	if !sets.NewString(http.IPFamilies...).Has(http.IPFamily) {
+		klog.Fatalf("the address family '%v' is not supported", http.IPFamily)
+	}
+
+	if readinessChecks != "" {
+		kubernetes.ReadinessChecks = strings.Split(readinessChecks, ",")
+	}
+
+	if err := kubernetes.ValidateReadinessChecks(); err != nil {
+		klog.Fatal(err)
+	}
+
+	if parallel < 1 {
+		klog.Fatalf("the number of features run concurrently must be greater than zero (%v)", parallel)
+	}
+
+	if repeat < 1 {
+		klog.Fatalf("the number of runs of the scenarios must be greater than zero (%v)", repeat)
+	}
+
+	if repeat > 1 {
+		report.Environment["Repetitions"] = strconv.Itoa(repeat)
+	}
+
+	if slowestSteps < 0 {
+		klog.Fatalf("the number of the slowest steps must not be negative (%v)", slowestSteps)
+	}
+
+	report.SlowestStepsCount = slowestSteps
+
+	if state.ConvergenceSuccesses < 1 {
+		klog.Fatalf("the number of convergence successes must be greater than zero (%v)", state.ConvergenceSuccesses)
+	}
+
+	if len(kubernetes.ImpersonateGroups) != 0 && kubernetes.ImpersonateUser == "" {
+		klog.Fatal("impersonating groups requires a username to impersonate")
+	}
+
 	validFormats := sets.NewString("cucumber", "pretty")
 	if !validFormats.Has(godogFormat) {
 		klog.Fatalf("the godog format '%v' is not supported", godogFormat)
 	}
 
-	err := setup()
+	selectedProfile, err := report.ParseProfile(profile)
 	if err != nil {
 		klog.Fatal(err)
 	}
 
-	if err := kubernetes.CleanupNamespaces(kubernetes.KubeClient); err != nil {
-		klog.Fatalf("error deleting temporal namespaces: %v", err)
+	// scenarios of profiles above the selected one are not run
+	report.SelectedProfile = selectedProfile
+	if godogTags == "" {
+		godogTags = report.ProfileTags(selectedProfile)
+	} else {
+		godogTags = fmt.Sprintf("%v && %v", report.ProfileTags(selectedProfile), godogTags)
 	}
 
-	go handleSignals()
+	runRegexp, err := regexp.Compile(runFilter)
+	if err != nil {
+		klog.Fatalf("the regular expression of the scenarios to run is not valid: %v", err)
+	}
+
+	selectedFeatures, err = selectFeatures(featureFilter)
+	if err != nil {
+		klog.Fatal(err)
+	}
 
-	os.Exit(m.Run())
+	shuffleSeed, err = parseShuffle(shuffle)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	if shuffleSeed != 0 {
+		klog.InfoS("Running features and scenarios in random order, use --shuffle=<seed> to repeat the same order", "seed", shuffleSeed)
+		report.Environment["Shuffle seed"] = strconv.FormatInt(shuffleSeed, 10)
+
+		rand.New(rand.NewSource(shuffleSeed)).Shuffle(len(selectedFeatures), func(i, j int) {
+			selectedFeatures[i], selectedFeatures[j] = selectedFeatures[j], selectedFeatures[i]
+		})
+	}
+
+	scenarios, err := report.ParseFeatures(selectedFeatures)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	for _, scenario := range scenarios {
+		if runRegexp.MatchString(scenario.Name) {
+			selectedScenarios = append(selectedScenarios, scenario)
+		}
+	}
+
+	if skipFilePath != "" {
+		report.KnownGaps, err = report.LoadSkipList(skipFilePath)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		// skipped scenarios are reported without running them
+		selectedScenarios = report.KnownGaps.RecordSkipped(selectedScenarios, godogTags)
+	}
+
+	if supportedFeaturesPath != "" {
+		supportedFeatures, err := report.LoadSupportedFeatures(supportedFeaturesPath)
+		if err != nil {
+			klog.Fatal(err)
+		}
+
+		// scenarios of unsupported features are reported without running them
+		supportedFeatures.RecordUnsupported(selectedScenarios, godogTags)
+
+		godogTags = fmt.Sprintf("%v && %v", godogTags, supportedFeatures.Tags())
+	}
+
+	report.IngressClass = kubernetes.IngressClassValue
+
+	validReports := sets.NewString("html", "json", "junit")
+	for _, r := range reports {
+		kv := strings.SplitN(r, ":", 2)
+		if len(kv) != 2 || kv[1] == "" || !validReports.Has(kv[0]) {
+			klog.Fatalf("the report '%v' is not supported (valid formats are %v)", r, strings.Join(validReports.List(), ", "))
+		}
+	}
+
+	validOutputs := sets.NewString("", "sonobuoy")
+	if !validOutputs.Has(outputFormat) {
+		klog.Fatalf("the output format '%v' is not supported", outputFormat)
+	}
+
+	if metricsAddress != "" {
+		if err := metrics.Serve(metricsAddress); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
+	err = setup()
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	if err := kubernetes.NewRunAnchor(context.Background(), kubernetes.KubeClient, runDescription()); err != nil {
+		klog.Fatal(err)
+	}
+
+	var cancel context.CancelFunc
+	if suiteTimeout > 0 {
+		state.SuiteContext, cancel = context.WithTimeout(context.Background(), suiteTimeout)
+	} else {
+		state.SuiteContext, cancel = context.WithCancel(context.Background())
+	}
+
+	go handleSignals(cancel)
+
+	if failFast {
+		var once sync.Once
+		report.OnRequiredFailure = func(scenario *report.Scenario) {
+			once.Do(func() {
+				klog.InfoS("A required scenario failed, stopping the suite (-fail-fast)", "feature", scenario.Feature, "scenario", scenario.Name)
+				cancel()
+			})
+		}
+	}
+
+	code := m.Run()
+	cancel()
+
+	if !kubernetes.KeepResources {
+		if err := kubernetes.DeleteRunAnchor(context.Background(), kubernetes.KubeClient); err != nil {
+			klog.Fatal(err)
+		}
+	}
+
+	os.Exit(code)
 }
 
 func setup() error {
@@ -98,45 +440,459 @@ func setup() error {
 		return fmt.Errorf("error loading client: %v", err)
 	}
 
+	kubernetes.DynamicClient, err = kubernetes.LoadDynamicClient()
+	if err != nil {
+		return fmt.Errorf("error loading dynamic client: %v", err)
+	}
+
+	version, err := kubernetes.KubeClient.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("error reading Kubernetes version: %v", err)
+	}
+
+	report.Environment["Kubernetes version"] = version.GitVersion
+	report.Environment["Kubernetes platform"] = version.Platform
+
+	detectController(context.Background())
+	report.Environment["API"] = kubernetes.API
+	report.Environment["Run ID"] = kubernetes.RunID
+
+	klog.InfoS("Labeling the objects created by the suite with the run identifier, use the cleanup command to delete them after an aborted run", "runID", kubernetes.RunID)
+
 	return nil
 }
 
+// detectController records the ingress controller and the cloud provider in the reports, without replacing
+// the name and version of the controller set with flags. Detection errors are logged, not fatal.
+func detectController(ctx context.Context) {
+	provider, err := kubernetes.CloudProvider(ctx, kubernetes.KubeClient)
+	if err != nil {
+		klog.ErrorS(err, "Detecting the cloud provider")
+	}
+
+	if provider != "" {
+		report.Environment["Cloud provider"] = provider
+	}
+
+	controller, err := kubernetes.DetectController(ctx, kubernetes.KubeClient)
+	if err != nil {
+		klog.ErrorS(err, "Detecting the ingress controller")
+	}
+
+	if report.ControllerName == "" {
+		report.ControllerName = controller.Name
+	}
+
+	if report.ControllerVersion == "" {
+		report.ControllerVersion = controller.Version
+	}
+
+	report.ControllerClass = controller.Class
+	report.ControllerDeployment = controller.Deployment
+	report.ControllerImages = controller.Images
+	report.ControllerReplicas = controller.Replicas
+}
+
+// runPerIngressClass runs the suite once per IngressClass, one after the other, in child processes with
+// the flags of this one, except the ones describing a single ingress controller. It writes the JSON reports
+// of the runs, and their comparison, to the output directory, and returns the exit code of the suite.
+func runPerIngressClass(classes []string) int {
+	var args []string
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "ingress-class", "report", "run-id", "controller-name", "controller-version", "controller-selector":
+			return
+		}
+
+		if list, ok := f.Value.(*stringList); ok {
+			for _, value := range *list {
+				args = append(args, fmt.Sprintf("-%v=%v", f.Name, value))
+			}
+
+			return
+		}
+
+		args = append(args, fmt.Sprintf("-%v=%v", f.Name, f.Value.String()))
+	})
+
+	code := 0
+
+	var results []*report.ConformanceReport
+	for _, class := range classes {
+		class = strings.TrimSpace(class)
+
+		path := filepath.Join(godogOutput, fmt.Sprintf("report-%v.json", class))
+
+		klog.InfoS("Running the suite", "ingressClass", class, "report", path)
+
+		cmd := exec.Command(os.Args[0], append(args, "-ingress-class="+class, "-report=json:"+path)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			code = 1
+
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				klog.ErrorS(err, "Running the suite", "ingressClass", class)
+				continue
+			}
+		}
+
+		result, err := report.LoadJSON(path)
+		if err != nil {
+			klog.ErrorS(err, "Reading the report of the suite", "ingressClass", class)
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	comparison := report.Compare(results)
+	if err := comparison.RenderText(os.Stdout); err != nil {
+		klog.Fatal(err)
+	}
+
+	path := filepath.Join(godogOutput, "comparison.html")
+	if err := comparison.WriteHTML(path); err != nil {
+		klog.Fatal(err)
+	}
+
+	klog.InfoS("Comparison of the ingress controllers written", "path", path)
+
+	return code
+}
+
+// runDescription returns the description of the run kept in its anchor: the environment of the reports, with
+// keys valid in ConfigMaps (e.g. kubernetes-version), and the start time
+func runDescription() map[string]string {
+	description := map[string]string{
+		"started": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	for key, value := range report.Environment {
+		description[strings.ToLower(strings.ReplaceAll(key, " ", "-"))] = value
+	}
+
+	return description
+}
+
 // Generated code. DO NOT EDIT.
 var (
 	features = map[string]func(*godog.ScenarioContext){
-		"features/default_backend.feature": defaultbackend.InitializeScenario,
-		"features/host_rules.feature":      hostrules.InitializeScenario,
-		"features/path_rules.feature":      pathrules.InitializeScenario,
-		"features/ingress_class.feature":   ingressclass.InitializeScenario,
-		"features/load_balancing.feature":  loadbalancing.InitializeScenario,
+		"features/default_backend.feature":        defaultbackend.InitializeScenario,
+		"features/host_rules.feature":             hostrules.InitializeScenario,
+		"features/path_rules.feature":             pathrules.InitializeScenario,
+		"features/ingress_class.feature":          ingressclass.InitializeScenario,
+		"features/load_balancing.feature":         loadbalancing.InitializeScenario,
+		"features/session_affinity.feature":       sessionaffinity.InitializeScenario,
+		"features/forwarded_headers.feature":      forwardedheaders.InitializeScenario,
+		"features/dual_stack.feature":             dualstack.InitializeScenario,
+		"features/default_ingress_class.feature":  defaultingressclass.InitializeScenario,
+		"features/path_types.feature":             pathtypes.InitializeScenario,
+		"features/path_precedence.feature":        pathprecedence.InitializeScenario,
+		"features/hostnames.feature":              hostnames.InitializeScenario,
+		"features/default_backend_rules.feature":  defaultbackendrules.InitializeScenario,
+		"features/multiple_ingresses.feature":     multipleingresses.InitializeScenario,
+		"features/backend_ports.feature":          backendports.InitializeScenario,
+		"features/external_name_services.feature": externalnameservices.InitializeScenario,
+		"features/backend_readiness.feature":      backendreadiness.InitializeScenario,
+		"features/rolling_updates.feature":        rollingupdates.InitializeScenario,
+		"features/ingress_updates.feature":        ingressupdates.InitializeScenario,
+		"features/https.feature":                  https.InitializeScenario,
+		"features/tls_secret_rotation.feature":    tlssecretrotation.InitializeScenario,
+		"features/invalid_tls_secrets.feature":    invalidtlssecrets.InitializeScenario,
+		"features/sni_fallback.feature":           snifallback.InitializeScenario,
+		"features/https_redirect.feature":         httpsredirect.InitializeScenario,
+		"features/ingress_status.feature":         ingressstatus.InitializeScenario,
+		"features/ingress_class_changes.feature":  ingressclasschanges.InitializeScenario,
+		"features/request_robustness.feature":     requestrobustness.InitializeScenario,
+		"features/request_targets.feature":        requesttargets.InitializeScenario,
+		"features/url_encoding.feature":           urlencoding.InitializeScenario,
+		"features/keep_alive.feature":             keepalive.InitializeScenario,
+		"features/compression.feature":            compression.InitializeScenario,
+		"features/conditional_requests.feature":   conditionalrequests.InitializeScenario,
+		"features/trailers.feature":               trailers.InitializeScenario,
+		"features/caching.feature":                caching.InitializeScenario,
+		"features/caching_annotations.feature":    cachingannotations.InitializeScenario,
+		"features/cors.feature":                   cors.InitializeScenario,
+		"features/rewrite.feature":                rewrite.InitializeScenario,
+		"features/rate_limiting.feature":          ratelimiting.InitializeScenario,
+		"features/source_ranges.feature":          sourceranges.InitializeScenario,
+		"features/basic_auth.feature":             basicauth.InitializeScenario,
+		"features/external_auth.feature":          externalauth.InitializeScenario,
+		"features/canary.feature":                 canary.InitializeScenario,
+		"features/timeouts.feature":               timeouts.InitializeScenario,
+		"features/body_size.feature":              bodysize.InitializeScenario,
+		"features/error_pages.feature":            errorpages.InitializeScenario,
+		"features/custom_error_pages.feature":     customerrorpages.InitializeScenario,
 	}
 )
 
 func TestSuite(t *testing.T) {
-	var failed bool
-	for feature, scenarioContext := range features {
-		err := testFeature(feature, scenarioContext)
-		if err != nil {
-			if godogStopOnFailure {
-				t.Fatal(err)
+	var concurrent, serial []string
+	for _, feature := range selectedFeatures {
+		if isSerial(feature) {
+			serial = append(serial, feature)
+		} else {
+			concurrent = append(concurrent, feature)
+		}
+	}
+
+	var errs []error
+	for i := 1; i <= repeat; i++ {
+		if (len(errs) != 0 && godogStopOnFailure) || state.SuiteContext.Err() != nil {
+			break
+		}
+
+		if repeat > 1 {
+			report.Repetition = i
+			klog.InfoS("Running the scenarios", "repetition", i, "of", repeat)
+		}
+
+		errs = append(errs, runFeatures(concurrent, parallel)...)
+		if len(errs) == 0 || !godogStopOnFailure {
+			errs = append(errs, runFeatures(serial, 1)...)
+		}
+	}
+
+	if scenarios := report.Results.Warnings(); len(scenarios) != 0 {
+		fmt.Printf("\nWarnings:\n")
+		for _, scenario := range scenarios {
+			for _, warning := range scenario.Warnings {
+				fmt.Printf("  %v: %v: %v\n", scenario.Feature, scenario.Name, warning.Message)
 			}
+		}
+	}
+
+	if steps := report.Results.SlowestSteps(slowestSteps); len(steps) != 0 {
+		fmt.Printf("\nSlowest steps:\n")
+		if err := report.RenderSlowestSteps(os.Stdout, steps); err != nil {
+			t.Fatal(err)
+		}
+	}
 
-			failed = true
+	if stability := report.Results.Stability(); stability != nil {
+		if err := report.RenderStability(os.Stdout, stability); err != nil {
+			t.Fatal(err)
 		}
 	}
 
-	if failed {
+	if len(errs) != 0 && godogStopOnFailure {
+		t.Fatal(errs[0])
+	}
+
+	if outputFormat == "sonobuoy" {
+		if err := report.Results.WriteSonobuoy(godogOutput); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, r := range reports {
+		kv := strings.SplitN(r, ":", 2)
+		if err := writeReport(kv[0], kv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(errs) != 0 {
 		t.Fatal("at least one step/scenario failed")
 	}
 }
 
-func testFeature(feature string, scenarioInitializer func(*godog.ScenarioContext)) error {
+// runFeatures tests the features, up to parallel at the same time, and returns the errors
+// of the features that failed. No more features are started after a failure when the
+// suite stops on failures.
+func runFeatures(paths []string, parallel int) []error {
+	var mu sync.Mutex
+	var errs []error
+
+	var wg sync.WaitGroup
+
+	// each feature is run by a free worker, whose index keeps its hostnames apart from the other workers
+	workers := make(chan int, parallel)
+	for worker := 1; worker <= parallel; worker++ {
+		workers <- worker
+	}
+
+	for _, feature := range paths {
+		worker := <-workers
+
+		mu.Lock()
+		stop := godogStopOnFailure && len(errs) != 0
+		mu.Unlock()
+
+		if stop {
+			workers <- worker
+			break
+		}
+
+		if err := state.SuiteContext.Err(); err != nil {
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("the suite was stopped before testing %v: %w", feature, err))
+			mu.Unlock()
+			workers <- worker
+			break
+		}
+
+		wg.Add(1)
+		go func(feature string, worker int) {
+			defer func() {
+				workers <- worker
+				wg.Done()
+			}()
+
+			for _, path := range featurePaths(feature) {
+				if err := testFeature(path, features[feature], worker); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}
+		}(feature, worker)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// isSerial returns true if the feature is tagged @serial, or if a manifest of its scenarios
+// sets a default backend, because its scenarios interfere with the scenarios of other features
+func isSerial(feature string) bool {
+	for _, scenario := range selectedScenarios {
+		if scenario.Feature != feature {
+			continue
+		}
+
+		if scenario.DefaultBackend {
+			return true
+		}
+
+		for _, tag := range scenario.Tags {
+			if tag == "@serial" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func writeReport(format, path string) error {
+	switch format {
+	case "html":
+		return report.Results.WriteHTML(path)
+	case "json":
+		return report.Results.WriteJSON(path)
+	case "junit":
+		return report.Results.WriteJUnit(path)
+	}
+
+	return fmt.Errorf("the report format '%v' is not supported", format)
+}
+
+// selectFeatures returns the sorted paths of the features selected by a comma separated list
+// of paths or names of feature files, or all the features when the list is empty
+func selectFeatures(filter string) ([]string, error) {
+	var paths []string
+	for feature := range features {
+		paths = append(paths, feature)
+	}
+
+	sort.Strings(paths)
+
+	if filter == "" {
+		return paths, nil
+	}
+
+	selected := sets.NewString()
+	for _, name := range strings.Split(filter, ",") {
+		name = strings.TrimSpace(name)
+
+		var found bool
+		for _, path := range paths {
+			base := filepath.Base(path)
+			if name == path || name == base || name == strings.TrimSuffix(base, ".feature") {
+				selected.Insert(path)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("the feature '%v' does not exist", name)
+		}
+	}
+
+	return selected.List(), nil
+}
+
+// parseShuffle returns the seed used to randomize the order of the features and scenarios,
+// zero when the order is not randomized
+func parseShuffle(value string) (int64, error) {
+	switch value {
+	case "off":
+		return 0, nil
+	case "on":
+		return time.Now().UnixNano(), nil
+	}
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seed == 0 {
+		return 0, fmt.Errorf("the shuffle value '%v' is not valid (valid values are off, on or a non-zero seed)", value)
+	}
+
+	return seed, nil
+}
+
+// featurePaths returns the paths of a feature run by godog. When the scenarios are filtered
+// by name, or skipped, each one is run using the line of the feature file that defines it.
+func featurePaths(feature string) []string {
+	if runFilter == "" && len(report.KnownGaps.Skip) == 0 {
+		return []string{feature}
+	}
+
+	var paths []string
+
+	lines := sets.NewInt()
+	for _, scenario := range selectedScenarios {
+		if scenario.Feature != feature || lines.Has(scenario.Line) {
+			continue
+		}
+
+		lines.Insert(scenario.Line)
+		paths = append(paths, fmt.Sprintf("%v:%v", feature, scenario.Line))
+	}
+
+	if shuffleSeed != 0 {
+		rand.New(rand.NewSource(shuffleSeed)).Shuffle(len(paths), func(i, j int) {
+			paths[i], paths[j] = paths[j], paths[i]
+		})
+	}
+
+	return paths
+}
+
+func testFeature(feature string, scenarioInitializer func(*godog.ScenarioContext), worker int) error {
 	var testOutput io.Writer
 	// default output is stdout
 	testOutput = os.Stdout
 
+	// the output of features run concurrently is printed when they finish
+	if parallel > 1 {
+		var buf bytes.Buffer
+		testOutput = &buf
+
+		defer func() {
+			outputMu.Lock()
+			defer outputMu.Unlock()
+
+			_, _ = io.Copy(os.Stdout, &buf)
+		}()
+	}
+
 	if godogFormat == "cucumber" {
-		rf := path.Join(godogOutput, fmt.Sprintf("%v-report.json", filepath.Base(feature)))
+		rf := path.Join(godogOutput, fmt.Sprintf("%v-report.json", strings.Replace(filepath.Base(feature), ":", "-", 1)))
 		file, err := os.Create(rf)
 		if err != nil {
 			return fmt.Errorf("error creating report file %v: %w", rf, err)
@@ -158,28 +914,78 @@ func testFeature(feature string, scenarioInitializer func(*godog.ScenarioContext
 		NoColors:      godogNoColors,
 		Output:        testOutput,
 		Concurrency:   1, // do not run tests concurrently
+		Randomize:     shuffleSeed,
 	}
 
 	exitCode := godog.TestSuite{
-		Name:                "conformance",
-		ScenarioInitializer: scenarioInitializer,
-		Options:             &opts,
+		Name: "conformance",
+		ScenarioInitializer: func(ctx *godog.ScenarioContext) {
+			scenarioInitializer(ctx)
+			state.RegisterWorker(ctx, worker)
+			state.RegisterVariables(ctx)
+			state.RegisterRequestMatrix(ctx)
+			report.Register(ctx)
+			metrics.Register(ctx)
+			tracing.Register(ctx)
+		},
+		Options: &opts,
 	}.Run()
-	if exitCode > 0 {
+	// the scenarios expected to fail do not fail the suite
+	if exitCode > 0 && !report.Results.OnlyExpectedFailures(strings.SplitN(feature, ":", 2)[0], report.Repetition) {
 		return fmt.Errorf("unexpected exit code testing %v: %v", feature, exitCode)
 	}
 
 	return nil
 }
 
-func handleSignals() {
+// usage prints the flags of the suite and the commands that can be run instead of the conformance tests
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %v: [flags] [command [command flags]]\n", os.Args[0])
+	flag.PrintDefaults()
+
+	fmt.Fprintf(out, "\nCommands:\n")
+	for _, command := range commands.Commands() {
+		fmt.Fprintf(out, "  %-40v %v\n", command.Name, command.Description)
+	}
+}
+
+// stringList is a flag that can be repeated to specify multiple values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// handleSignals stops the suite on the first SIGINT or SIGTERM, failing the scenarios
+// in progress and writing the reports with the partial results. A second signal exits
+// immediately after deleting the namespaces created by the scenarios.
+func handleSignals(cancel context.CancelFunc) {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-signals
+
+	klog.InfoS("Interrupted, stopping the scenarios in progress (send the signal again to exit immediately)", "signal", sig)
+	cancel()
+
 	<-signals
 
-	if err := kubernetes.CleanupNamespaces(kubernetes.KubeClient); err != nil {
+	if kubernetes.KeepResources {
+		os.Exit(1)
+	}
+
+	if err := kubernetes.CleanupNamespaces(context.Background(), kubernetes.KubeClient); err != nil {
 		klog.Fatalf("error deleting temporal namespaces: %v", err)
 	}
 
+	if err := kubernetes.DeleteRunAnchor(context.Background(), kubernetes.KubeClient); err != nil {
+		klog.Fatal(err)
+	}
+
 	os.Exit(1)
 }
@@ -0,0 +1,122 @@
+@sig-network @conformance @core @release-1.19
+Feature: Backend service ports
+  The backend of an Ingress path references a port of its Service by number,
+  in the `port.number` field, or by name, in the `port.name` field. Exactly
+  one of the fields is set.
+
+  The Service forwards the traffic to the target port of its pods, defined
+  by number or by the name of a container port. The ingress controller must
+  resolve the ports of the Service, and the endpoints of the target port.
+
+  https://kubernetes.io/docs/concepts/services-networking/ingress/#resource-backend
+
+  Background:
+    Given a new random namespace
+
+  Scenario: An Ingress referencing the port of a backend service by number should send traffic to the backend service
+    (port number 8080, target port number 3000)
+
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: port-number-backend-ports
+      spec:
+        rules:
+          - host: "port-number-backend-ports{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: port-number
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://port-number-backend-ports${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "port-number" service
+
+  Scenario: An Ingress referencing the port of a backend service by name should send traffic to the backend service
+    (port name http, target port number 3000)
+
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: port-name-backend-ports
+      spec:
+        rules:
+          - host: "port-name-backend-ports{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: port-name
+                      port:
+                        name: http
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://port-name-backend-ports${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "port-name" service
+
+  Scenario: An Ingress referencing the port of a backend service by number should send traffic to a named target port
+    (port number 8080, target port name echo-http)
+
+    Given an Ingress resource with backend services targeting the container port "echo-http"
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: port-number-target-name-backend-ports
+      spec:
+        rules:
+          - host: "port-number-target-name-backend-ports{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: port-number-target-name
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://port-number-target-name-backend-ports${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "port-number-target-name" service
+
+  Scenario: An Ingress referencing the port of a backend service by name should send traffic to a named target port
+    (port name http, target port name echo-http)
+
+    Given an Ingress resource with backend services targeting the container port "echo-http"
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: port-name-target-name-backend-ports
+      spec:
+        rules:
+          - host: "port-name-target-name-backend-ports{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: port-name-target-name
+                      port:
+                        name: http
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://port-name-target-name-backend-ports${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "port-name-target-name" service
@@ -0,0 +1,52 @@
+@sig-network @conformance @core @release-1.19
+Feature: Backend readiness
+  Ingress controllers send traffic only to the ready endpoints of a backend
+  service. When a backend service has no ready endpoints, because its pods
+  are not running or fail their readiness probe, the ingress controller must
+  return an error status code (502 or 503) without waiting for the request
+  to time out.
+
+  Traffic to the backend service resumes once its endpoints are ready again,
+  without changes to the Ingress.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: backend-readiness
+      spec:
+        rules:
+          - host: "backend-readiness{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: readiness-backend
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with a backend service without ready endpoints should return an error status code
+    (readiness-backend has no ready endpoints)
+
+    Given The backend deployment "readiness-backend" for the ingress resource is scaled to 0
+    When I send a "GET" request to "http://backend-readiness${hostSuffix}/"
+    Then the response status-code must be 502 or 503
+    And the response must be received in less than 10 seconds
+
+  Scenario: An Ingress with a backend service should send traffic to the backend service once its endpoints are ready again
+    (readiness-backend has ready endpoints after having none)
+
+    Given The backend deployment "readiness-backend" for the ingress resource is scaled to 0
+    When I send a "GET" request to "http://backend-readiness${hostSuffix}/"
+    Then the response status-code must be 502 or 503
+    Given The backend deployment "readiness-backend" for the ingress resource is scaled to 1
+    When I send a "GET" request to "http://backend-readiness${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "readiness-backend" service
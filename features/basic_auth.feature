@@ -0,0 +1,65 @@
+@sig-network @basic-auth @extended
+Feature: Basic authentication
+  Ingress controllers may require the clients to authenticate with the
+  Basic HTTP authentication scheme, when an annotation of the Ingress
+  references a secret with the users and their passwords. Requests without
+  valid credentials are rejected with a 401 (Unauthorized) response and a
+  WWW-Authenticate header with the realm of the Ingress.
+
+  Basic authentication is not part of the Ingress spec. The secret contains
+  an htpasswd file in its auth key, with passwords hashed with the Apache
+  MD5 algorithm ($apr1$). The abstract annotations are translated to the
+  annotations of the ingress controller (ingress-nginx by default).
+
+  https://www.rfc-editor.org/rfc/rfc7617
+
+  Background:
+    Given a new random namespace
+    Given a basic authentication secret named "basic-auth" with the user "conformance" and the password "s3cr3t"
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: basic-auth
+        annotations:
+          conformance.ingress.k8s.io/auth-type: basic
+          conformance.ingress.k8s.io/auth-secret: basic-auth
+          conformance.ingress.k8s.io/auth-realm: Conformance
+      spec:
+        rules:
+          - host: "basic-auth{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: basic-auth
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with basic authentication should reject requests without credentials
+    When I send a "GET" request to "http://basic-auth${hostSuffix}/"
+    Then the response status-code must be 401
+    And the response must ask for basic credentials of the realm "Conformance"
+
+  Scenario: An Ingress with basic authentication should send requests with valid credentials to the backend service
+    Given the requests send the credentials of the user "conformance" with the password "s3cr3t"
+    When I send a "GET" request to "http://basic-auth${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "basic-auth" service
+
+  Scenario Outline: An Ingress with basic authentication should reject requests with invalid credentials
+    Given the requests send the credentials of the user "<user>" with the password "<password>"
+    When I send a "GET" request to "http://basic-auth${hostSuffix}/"
+    Then the response status-code must be 401
+    And the response must ask for basic credentials of the realm "Conformance"
+
+    Examples:
+      | user        | password |
+      | conformance | wrong    |
+      | unknown     | s3cr3t   |
+      | conformance |          |
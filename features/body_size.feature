@@ -0,0 +1,50 @@
+@sig-network @body-size @extended
+Feature: Request body size limit
+  Ingress controllers may limit the size of the request bodies when an
+  annotation of the Ingress configures a maximum size. Requests with larger
+  bodies are rejected with a 413 (Content Too Large) response, without
+  reaching the backend service. Requests with smaller bodies are sent to the
+  backend service with their whole body.
+
+  The limit of the request bodies is not part of the Ingress spec. The size
+  in the annotation uses the units of ingress-nginx (1m is 1 MiB). The
+  abstract annotations are translated to the annotations of the ingress
+  controller (ingress-nginx by default).
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: body-size
+        annotations:
+          conformance.ingress.k8s.io/proxy-body-size: "1m"
+      spec:
+        rules:
+          - host: "body-size{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: body-size
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with a body size limit should send the requests with smaller bodies to the backend service
+    Given the requests send a body of 512 KB
+    When I send a "POST" request to "http://body-size${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "body-size" service
+    And the request body must have 512 KB
+
+  Scenario: An Ingress with a body size limit should reject the requests with larger bodies
+    Given the requests send a body of 2048 KB
+    When I send a "POST" request to "http://body-size${hostSuffix}/"
+    Then the response status-code must be 413
+    And the response must not be served by the "body-size" service
@@ -0,0 +1,47 @@
+@sig-network @conformance @core @release-1.19
+Feature: Caching
+  Caching is not part of the Ingress spec. Ingress controllers must send
+  every request to the backend services by default, even when their
+  responses are cacheable, since serving cached responses would change
+  the behavior of the applications exposed by the Ingress.
+
+  Each echo response contains a random nonce, so a response sent more
+  than once was served from a cache instead of the backend service.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: caching
+      spec:
+        rules:
+          - host: "caching{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: caching
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should send every request to the backend service
+    When I send 10 "GET" requests to "http://caching${hostSuffix}/"
+    Then all the responses status-code must be 200
+    And every request must reach the backend service
+
+  Scenario: An Ingress should send every request to the backend service even when the responses are cacheable
+    When I send 10 "GET" requests to "http://caching${hostSuffix}/cacheable?set-header=Cache-Control:max-age=600"
+    Then all the responses status-code must be 200
+    And every request must reach the backend service
+
+  Scenario: An Ingress should send every request to the backend service even when the responses are cacheable by shared caches
+    When I send 10 "GET" requests to "http://caching${hostSuffix}/public?set-header=Cache-Control:public&set-header=Cache-Control:s-maxage=600"
+    Then all the responses status-code must be 200
+    And every request must reach the backend service
@@ -0,0 +1,48 @@
+@sig-network @caching-annotations @extended
+Feature: Caching annotations
+  Ingress controllers may cache the responses of the backend services when
+  an annotation of the Ingress enables it. Cacheable responses are then
+  sent from the cache, without sending the request to the backend service,
+  and responses that must not be stored are never cached.
+
+  Caching is not part of the Ingress spec. The annotations used in this
+  feature are the ones supported by ingress-nginx, which requires a cache
+  zone named conformance-cache in the http-snippet of its ConfigMap
+  (e.g. proxy_cache_path /tmp/conformance-cache keys_zone=conformance-cache:10m).
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: caching-annotations
+        annotations:
+          nginx.ingress.kubernetes.io/configuration-snippet: |
+            proxy_cache conformance-cache;
+            proxy_cache_valid 200 10m;
+      spec:
+        rules:
+          - host: "caching-annotations{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: caching-annotations
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with caching enabled should send cacheable responses from its cache
+    When I send 10 "GET" requests to "http://caching-annotations${hostSuffix}/cacheable?set-header=Cache-Control:max-age=600"
+    Then all the responses status-code must be 200
+    And some responses must be served from a cache
+
+  Scenario: An Ingress with caching enabled should send every request to the backend service when the responses must not be stored
+    When I send 10 "GET" requests to "http://caching-annotations${hostSuffix}/no-store?set-header=Cache-Control:no-store"
+    Then all the responses status-code must be 200
+    And every request must reach the backend service
@@ -0,0 +1,226 @@
+@sig-network @canary @extended
+Feature: Canary
+  Ingress controllers may split the traffic of a host and path between the
+  backend service of an Ingress and the backend service of a canary Ingress,
+  defined for the same host and path with an annotation. The canary receives
+  a share of the requests given by its weight, the requests with a header
+  with a given value, or the requests with a cookie set to always. The other
+  requests are sent to the backend service of the main Ingress.
+
+  Canaries are not part of the Ingress spec. The weighted split is checked
+  statistically over a burst of concurrent requests: the share of the
+  requests served by each service can differ from its weight by up to the
+  percentage points in the -canary-weight-tolerance flag. The abstract
+  annotations are translated to the annotations of the ingress controller
+  (ingress-nginx by default).
+
+  Scenario: An Ingress with a weighted canary should send a share of the requests to the canary service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-weighted
+      spec:
+        rules:
+          - host: "canary-weighted{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-weighted-stable
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-weighted-canary
+        annotations:
+          conformance.ingress.k8s.io/canary: "true"
+          conformance.ingress.k8s.io/canary-weight: "20"
+      spec:
+        rules:
+          - host: "canary-weighted{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-weighted-canary
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Then the requests to "http://canary-weighted${hostSuffix}/" must eventually be served by the "canary-weighted-canary" service
+    When I send 200 requests to "http://canary-weighted${hostSuffix}/" with 10 concurrent clients
+    Then all the responses status-code must be 200
+    And the "canary-weighted-canary" service must serve 20% of the requests
+    And the "canary-weighted-stable" service must serve 80% of the requests
+
+  Scenario Outline: An Ingress with a canary by header should send the requests with the header value to the canary service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-header
+      spec:
+        rules:
+          - host: "canary-header{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-header-stable
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-header-canary
+        annotations:
+          conformance.ingress.k8s.io/canary: "true"
+          conformance.ingress.k8s.io/canary-by-header: "X-Canary"
+          conformance.ingress.k8s.io/canary-by-header-value: "enabled"
+      spec:
+        rules:
+          - host: "canary-header{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-header-canary
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given the requests send the "X-Canary" header with "<value>"
+    Then the requests to "http://canary-header${hostSuffix}/" must eventually be served by the "<service>" service
+    And the response status-code must be 200
+
+    Examples:
+      | value    | service              |
+      | enabled  | canary-header-canary |
+      | disabled | canary-header-stable |
+
+  Scenario: An Ingress with a canary by header should send the requests without the header to the main service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-no-header
+      spec:
+        rules:
+          - host: "canary-no-header{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-no-header-stable
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-no-header-canary
+        annotations:
+          conformance.ingress.k8s.io/canary: "true"
+          conformance.ingress.k8s.io/canary-by-header: "X-Canary"
+          conformance.ingress.k8s.io/canary-by-header-value: "enabled"
+      spec:
+        rules:
+          - host: "canary-no-header{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-no-header-canary
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://canary-no-header${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "canary-no-header-stable" service
+
+  Scenario Outline: An Ingress with a canary by cookie should send the requests with the cookie set to always to the canary service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-cookie
+      spec:
+        rules:
+          - host: "canary-cookie{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-cookie-stable
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: canary-cookie-canary
+        annotations:
+          conformance.ingress.k8s.io/canary: "true"
+          conformance.ingress.k8s.io/canary-by-cookie: "canary"
+      spec:
+        rules:
+          - host: "canary-cookie{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: canary-cookie-canary
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given the requests send the "Cookie" header with "<cookie>"
+    Then the requests to "http://canary-cookie${hostSuffix}/" must eventually be served by the "<service>" service
+    And the response status-code must be 200
+
+    Examples:
+      | cookie        | service              |
+      | canary=always | canary-cookie-canary |
+      | canary=never  | canary-cookie-stable |
+      | other=always  | canary-cookie-stable |
@@ -0,0 +1,64 @@
+@sig-network @compression @extended
+Feature: Compression
+  Ingress controllers may compress the responses of the backend services
+  for the clients that accept a content encoding, and must send the
+  responses compressed by the backend services as they are, or decoded.
+  Either way, the body must not be corrupted and its Content-Length,
+  when present, must be the size of the body sent.
+
+  Clients that do not accept any content encoding must receive the
+  responses without content encoding.
+
+  The echoserver pads its response up to the size query parameter, and
+  compresses it with gzip when the encoding query parameter is set and
+  the request accepts it.
+
+  https://www.rfc-editor.org/rfc/rfc9110#section-8.4
+  https://www.rfc-editor.org/rfc/rfc9110#section-12.5.3
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: compression
+      spec:
+        rules:
+          - host: "compression{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: compression
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress may compress the responses of the backend service for clients accepting gzip
+    Given the requests accept the "gzip" content encoding
+    When I send a "GET" request to "http://compression${hostSuffix}/?size=4KB"
+    Then the response status-code must be 200
+    And the response must be served by the "compression" service
+    And the decoded response body must be 4096 bytes
+    And the response Content-Length must match its body
+
+  Scenario: An Ingress should send the responses compressed by the backend service without corrupting them
+    Given the requests accept the "gzip" content encoding
+    When I send a "GET" request to "http://compression${hostSuffix}/?size=4KB&encoding=gzip"
+    Then the response status-code must be 200
+    And the response must be served by the "compression" service
+    And the decoded response body must be 4096 bytes
+    And the response Content-Length must match its body
+
+  Scenario: An Ingress should not compress the responses for clients not accepting any content encoding
+    When I send a "GET" request to "http://compression${hostSuffix}/?size=4KB&encoding=gzip"
+    Then the response status-code must be 200
+    And the response must be served by the "compression" service
+    And the response body must not be encoded
+    And the decoded response body must be 4096 bytes
+    And the response Content-Length must match its body
@@ -0,0 +1,77 @@
+@sig-network @conditional-requests @extended
+Feature: Range and conditional requests
+  Ingress controllers must forward the Range, If-None-Match and
+  If-Modified-Since headers of the requests to the backend services, and
+  send their 206 (Partial Content), 304 (Not Modified) and 416 (Range Not
+  Satisfiable) responses as they are. Proxies buffering or caching the
+  responses must not break these semantics.
+
+  The /content path of the echoserver serves 1024 bytes repeating
+  0123456789abcdef, with ETag and Last-Modified headers.
+
+  https://www.rfc-editor.org/rfc/rfc9110#section-13
+  https://www.rfc-editor.org/rfc/rfc9110#section-14
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: conditional-requests
+      spec:
+        rules:
+          - host: "conditional-requests{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: conditional-requests
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should send the complete content to requests without conditions
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 200
+    And the response header "X-Echo-Service" must be "conditional-requests"
+    And the decoded response body must be 1024 bytes
+
+  Scenario: An Ingress should send partial content for a range request
+    Given the requests send the "Range" header with "bytes=0-9"
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 206
+    And the response header "Content-Range" must be "bytes 0-9/1024"
+    And the response body must be "0123456789"
+
+  Scenario: An Ingress should send partial content for a suffix range request
+    Given the requests send the "Range" header with "bytes=-4"
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 206
+    And the response header "Content-Range" must be "bytes 1020-1023/1024"
+    And the response body must be "cdef"
+
+  Scenario: An Ingress should send the response to an unsatisfiable range request
+    Given the requests send the "Range" header with "bytes=2048-4095"
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 416
+
+  Scenario: An Ingress should send not modified responses to requests with the ETag of the content
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 200
+    Given the requests send the "If-None-Match" header with the "ETag" of the response
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 304
+    And the response body must be ""
+
+  Scenario: An Ingress should send not modified responses to requests with the modification time of the content
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 200
+    Given the requests send the "If-Modified-Since" header with the "Last-Modified" of the response
+    When I send a "GET" request to "http://conditional-requests${hostSuffix}/content"
+    Then the response status-code must be 304
+    And the response body must be ""
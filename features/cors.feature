@@ -0,0 +1,79 @@
+@sig-network @cors @extended
+Feature: CORS
+  Ingress controllers may answer the Cross-Origin Resource Sharing (CORS)
+  requests of browsers when an annotation of the Ingress enables it, so the
+  backend services do not need to implement it. Preflight requests, sent with
+  the OPTIONS method, are answered with the origins, methods and headers
+  allowed by the annotations, and the responses to cross-origin requests
+  allow their origin.
+
+  CORS is not part of the Ingress spec, and the defaults of the ingress
+  controllers differ. The scenarios only check the values configured with
+  the annotations, which are translated to the annotations of the ingress
+  controller (ingress-nginx by default).
+
+  https://fetch.spec.whatwg.org/#http-cors-protocol
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: cors
+        annotations:
+          conformance.ingress.k8s.io/cors-enable: "true"
+          conformance.ingress.k8s.io/cors-allow-origin: "https://allowed.example.com"
+          conformance.ingress.k8s.io/cors-allow-methods: "GET, PUT, POST"
+          conformance.ingress.k8s.io/cors-allow-headers: "X-Conformance, Content-Type"
+          conformance.ingress.k8s.io/cors-allow-credentials: "true"
+          conformance.ingress.k8s.io/cors-max-age: "600"
+      spec:
+        rules:
+          - host: "cors{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: cors
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with CORS enabled should answer preflight requests from an allowed origin
+    Given the requests send the "Origin" header with "https://allowed.example.com"
+    And the requests send the "Access-Control-Request-Method" header with "PUT"
+    And the requests send the "Access-Control-Request-Headers" header with "X-Conformance"
+    When I send a "OPTIONS" request to "http://cors${hostSuffix}/"
+    Then the response status-code must be 200 or 204
+    And the response header "Access-Control-Allow-Origin" must be "https://allowed.example.com"
+    And the response header "Access-Control-Allow-Methods" must list "PUT"
+    And the response header "Access-Control-Allow-Headers" must list "X-Conformance"
+    And the response header "Access-Control-Allow-Credentials" must be "true"
+    And the response header "Access-Control-Max-Age" must be "600"
+
+  Scenario: An Ingress with CORS enabled should allow cross-origin requests from an allowed origin
+    Given the requests send the "Origin" header with "https://allowed.example.com"
+    When I send a "GET" request to "http://cors${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "cors" service
+    And the response header "Access-Control-Allow-Origin" must be "https://allowed.example.com"
+    And the response header "Access-Control-Allow-Credentials" must be "true"
+
+  Scenario: An Ingress with CORS enabled should not allow cross-origin requests from other origins
+    Given the requests send the "Origin" header with "https://other.example.com"
+    When I send a "GET" request to "http://cors${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "cors" service
+    And the response header "Access-Control-Allow-Origin" must not be "https://other.example.com"
+    And the response header "Access-Control-Allow-Origin" must not be "*"
+
+  Scenario: An Ingress with CORS enabled should send requests without origin to the backend service unchanged
+    When I send a "GET" request to "http://cors${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "cors" service
+    And the request method must be "GET"
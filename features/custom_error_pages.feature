@@ -0,0 +1,58 @@
+@sig-network @custom-error-pages @extended @serial
+Feature: Custom error pages
+  Ingress controllers may replace the error responses of the backend
+  services with the responses of another service, when annotations of the
+  Ingress list the intercepted status codes and the service of the error
+  pages. The request is sent to the service of the error pages with the
+  status code in the X-Code header, and the client receives its response
+  with the original status code. Other responses are not intercepted.
+
+  Custom error pages are not part of the Ingress spec. The X-Echo-Status
+  header of the request sets the status code of the echoserver, both in
+  the backend service and in the service of the error pages. The abstract
+  annotations are translated to the annotations of the ingress controller
+  (ingress-nginx by default).
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: custom-error-pages
+        annotations:
+          conformance.ingress.k8s.io/custom-http-errors: "503"
+          conformance.ingress.k8s.io/default-backend: custom-error-pages-default
+      spec:
+        defaultBackend:
+          service:
+            name: custom-error-pages-default
+            port:
+              number: 8080
+        rules:
+          - host: "custom-error-pages{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: custom-error-pages-app
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with custom error pages should send the intercepted errors to the service of the error pages
+    Given the requests send the "X-Echo-Status" header with "503"
+    When I send a "GET" request to "http://custom-error-pages${hostSuffix}/"
+    Then the response status-code must be 503
+    And the response must be served by the "custom-error-pages-default" service
+    And the request header "X-Code" must be "503"
+
+  Scenario: An Ingress with custom error pages should not intercept other responses
+    Given the requests send the "X-Echo-Status" header with "500"
+    When I send a "GET" request to "http://custom-error-pages${hostSuffix}/"
+    Then the response status-code must be 500
+    And the response must be served by the "custom-error-pages-app" service
@@ -1,4 +1,4 @@
-@sig-network @conformance @release-1.19
+@sig-network @conformance @core @serial @release-1.19
 Feature: Default backend
   An Ingress with no rules sends all traffic to the single default backend.
   The default backend is part of the Ingress resource spec field `defaultBackend`.
@@ -37,10 +37,10 @@ Feature: Default backend
       | User-Agent | Go-http-client/1.1 |
 
     Examples:
-      | method | host      | path     |
-      | GET    | my-host   |          |
-      | GET    | my-host   | sub-path |
-      | POST   | some-host |          |
-      | PUT    |           | resource |
-      | DELETE | some-host | resource |
-      | PATCH  | my-host   | resource |
+      | method | host                   | path     |
+      | GET    | my-host${hostSuffix}   |          |
+      | GET    | my-host${hostSuffix}   | sub-path |
+      | POST   | some-host${hostSuffix} |          |
+      | PUT    |                        | resource |
+      | DELETE | some-host${hostSuffix} | resource |
+      | PATCH  | my-host${hostSuffix}   | resource |
@@ -0,0 +1,61 @@
+@sig-network @conformance @core @serial @release-1.19
+Feature: Default backend with rules
+  An Ingress with rules may also define a default backend in the
+  `defaultBackend` field of its spec.
+
+  Requests matching no host or path of the rules are routed to the default
+  backend of the Ingress. Without a default backend, the ingress controller
+  handles them, returning a 404 status code.
+
+  Background:
+    Given an Ingress resource in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: default-backend-rules
+      spec:
+        rules:
+          - host: "default-backend-rules{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress without default backend should return 404 for requests matching no rule
+    (the ingress controller handles requests without a matching rule)
+
+    When I send a "GET" request to "http://default-backend-rules${hostSuffix}/bar"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a default backend should send requests matching no path of a rule host to the default backend
+    (default backend matches request /bar of host default-backend-rules)
+
+    Given the Ingress default backend is the "default-backend" service
+    When I send a "GET" request to "http://default-backend-rules${hostSuffix}/bar"
+    Then the response status-code must be 200
+    And the response must be served by the "default-backend" service
+    And the request path must be "/bar"
+
+  Scenario: An Ingress with a default backend should send requests matching no rule host to the default backend
+    (default backend matches request /foo of host other-default-backend-rules)
+
+    Given the Ingress default backend is the "default-backend" service
+    When I send a "GET" request to "http://other-default-backend-rules${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "default-backend" service
+
+  Scenario: An Ingress with a default backend should send requests matching a rule to the backend of the rule
+    (prefix /foo of host default-backend-rules is preferred to the default backend)
+
+    Given the Ingress default backend is the "default-backend" service
+    When I send a "GET" request to "http://default-backend-rules${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
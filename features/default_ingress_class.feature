@@ -0,0 +1,31 @@
+@sig-network @conformance @core @release-1.19
+Feature: Default ingress class
+  An IngressClass can be marked as the default of the cluster with the annotation
+  ingressclass.kubernetes.io/is-default-class. New Ingresses without class are
+  assigned the default IngressClass, and only the controller of that class should
+  implement them.
+  
+  https://kubernetes.io/docs/concepts/services-networking/ingress/#default-ingress-class
+
+  Scenario: An Ingress without class should only be implemented by the controller of the default IngressClass
+    Given an Ingress resource without class in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: default-ingress-class
+      spec:
+        rules:
+          - host: "default-ingress-class{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: default-ingress-class
+                      port:
+                        number: 8080
+      """
+    Then The Ingress must be assigned the default IngressClass of the cluster
+    And The Ingress status must only contain the IP address or FQDN if the tested ingress class is the default
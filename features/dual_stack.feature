@@ -0,0 +1,41 @@
+@sig-network @dual-stack @experimental
+Feature: Dual-stack
+  An Ingress exposed by an ingress controller running in a dual-stack cluster
+  should be reachable using both IPv4 and IPv6 addresses.
+  
+  This feature requires a cluster and an ingress controller with IPv4 and IPv6
+  support. Exclude the @dual-stack tag in clusters with a single address family.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: dual-stack
+      spec:
+        rules:
+          - host: dual-stack{{ .HostSuffix }}
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: echo-service
+                      port:
+                        number: 8080
+      """
+
+  Scenario: An Ingress should be reachable using an IPv4 address
+    Given The Ingress status shows an "ipv4" address or FQDN where it is exposed
+    When I send a "GET" request to "http://dual-stack${hostSuffix}" using "ipv4"
+    Then the response status-code must be 200
+    And the request must be sent to an "ipv4" address
+
+  Scenario: An Ingress should be reachable using an IPv6 address
+    Given The Ingress status shows an "ipv6" address or FQDN where it is exposed
+    When I send a "GET" request to "http://dual-stack${hostSuffix}" using "ipv6"
+    Then the response status-code must be 200
+    And the request must be sent to an "ipv6" address
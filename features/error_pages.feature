@@ -0,0 +1,56 @@
+@sig-network @conformance @core @release-1.19
+Feature: Error pages
+  Ingress controllers answer the requests they cannot send to a backend
+  service with a response generated by the ingress controller, with an
+  error status code: 404 (Not Found) when no rule matches the request,
+  502 (Bad Gateway) when the backend service does not accept the connection
+  and 503 (Service Unavailable) when the backend service has no endpoints.
+  The body of the error responses, if any, must declare its Content-Type.
+
+  Background:
+    Given a new random namespace
+    Given a backend service "closed-port" of the Ingress "error-pages" with the closed target port 3999
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: error-pages
+      spec:
+        rules:
+          - host: "error-pages{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /app
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: error-pages-app
+                      port:
+                        number: 8080
+                - path: /closed
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: closed-port
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should answer 404 to the requests without matching rule
+    When I send a "GET" request to "http://error-pages${hostSuffix}/missing"
+    Then the response status-code must be 404
+    And the response must not be served by the "error-pages-app" service
+    And the response must declare the Content-Type of its body
+
+  Scenario: An Ingress should answer 502 when the backend service does not accept the connection
+    When I send a "GET" request to "http://error-pages${hostSuffix}/closed"
+    Then the response status-code must be 502
+    And the response must declare the Content-Type of its body
+
+  Scenario: An Ingress should answer 503 when the backend service has no endpoints
+    Given The backend deployment "error-pages-app" for the ingress resource is scaled to 0
+    When I send a "GET" request to "http://error-pages${hostSuffix}/app"
+    Then the response status-code must be 503
+    And the response must declare the Content-Type of its body
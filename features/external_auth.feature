@@ -0,0 +1,70 @@
+@sig-network @external-auth @extended
+Feature: External authentication
+  Ingress controllers may delegate the authentication of the requests to an
+  external service, configured with an annotation of the Ingress. For each
+  request, the ingress controller sends a subrequest with the headers of the
+  request to the authentication service. Approved requests (2xx) are sent to
+  the backend service, with the headers of the response of the authentication
+  service listed in another annotation. Denied requests are answered with
+  the status of the authentication service, 401 (Unauthorized) or
+  403 (Forbidden), without reaching the backend service.
+
+  External authentication is not part of the Ingress spec. The /auth endpoint
+  of the echoserver is the authentication service: it approves the requests
+  with the X-Echo-Auth: allow header, returning the user in the X-Auth-User
+  header. The abstract annotations are translated to the annotations of the
+  ingress controller (ingress-nginx by default).
+
+  Background:
+    Given a new random namespace
+    Given an authentication service named "external-auth-service"
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: external-auth
+        annotations:
+          conformance.ingress.k8s.io/auth-url: "http://external-auth-service.{{ .Namespace }}.svc.{{ .ClusterDomain }}:8080/auth"
+          conformance.ingress.k8s.io/auth-response-headers: X-Auth-User
+      spec:
+        rules:
+          - host: "external-auth{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: external-auth
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with external authentication should send approved requests to the backend service
+    Given the requests send the "X-Echo-Auth" header with "allow"
+    And the requests send the "X-Echo-Auth-User" header with "alice"
+    When I send a "GET" request to "http://external-auth${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "external-auth" service
+    And the request header "X-Auth-User" must be "alice"
+
+  Scenario: An Ingress with external authentication should replace the auth-response headers sent by the client
+    Given the requests send the "X-Echo-Auth" header with "allow"
+    And the requests send the "X-Auth-User" header with "mallory"
+    When I send a "GET" request to "http://external-auth${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "external-auth" service
+    And the request header "X-Auth-User" must be "conformance"
+
+  Scenario: An Ingress with external authentication should reject requests without credentials with the status of the authentication service
+    When I send a "GET" request to "http://external-auth${hostSuffix}/"
+    Then the response status-code must be 401
+    And the response must not be served by the "external-auth" service
+
+  Scenario: An Ingress with external authentication should reject denied requests with the status of the authentication service
+    Given the requests send the "X-Echo-Auth" header with "deny"
+    When I send a "GET" request to "http://external-auth${hostSuffix}/"
+    Then the response status-code must be 403
+    And the response must not be served by the "external-auth" service
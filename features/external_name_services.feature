@@ -0,0 +1,69 @@
+@sig-network @external-name-services @extended
+Feature: ExternalName services
+  A Service of type ExternalName is an alias of a DNS name, without
+  endpoints. Ingress controllers supporting ExternalName services as
+  backends proxy the requests to the external host, on the port of the
+  backend.
+
+  Ingress controllers differ in their support of ExternalName services:
+  some proxy the requests, others reject the backend. When the external host
+  cannot be resolved, the ingress controller must return an error status
+  code instead of waiting for the request to time out.
+
+  Background:
+    Given a new random namespace
+
+  Scenario: An Ingress with an ExternalName service backend should send traffic to the external host
+    (external-name is an alias of the DNS name of the external-name-target service)
+
+    Given an ExternalName service "external-name" for the "external-name-target" service
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: external-name-services
+      spec:
+        rules:
+          - host: "external-name-services{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: external-name
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://external-name-services${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "external-name-target" service
+
+  Scenario: An Ingress with an ExternalName service backend should return an error status code when the external host cannot be resolved
+    (unresolvable-external-name is an alias of the unresolvable.invalid DNS name)
+
+    Given an ExternalName service "unresolvable-external-name" for the host "unresolvable.invalid"
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: unresolvable-external-name-services
+      spec:
+        rules:
+          - host: "unresolvable-external-name-services{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: unresolvable-external-name
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://unresolvable-external-name-services${hostSuffix}/"
+    Then the response status-code must be 502 or 503
@@ -0,0 +1,56 @@
+@sig-network @forwarded-headers @extended
+Feature: Forwarded headers
+  An ingress controller proxying a request should inform the backend service
+  about the original request using the X-Forwarded-* headers or the standard
+  Forwarded header (RFC 7239).
+  
+  The client address seen by the ingress controller must be appended to the
+  X-Forwarded-For chain.
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "conformance-tls" for the "forwarded-headers${hostSuffix}" hostname
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: forwarded-headers
+      spec:
+        tls:
+          - hosts:
+              - forwarded-headers{{ .HostSuffix }}
+            secretName: conformance-tls
+        rules:
+          - host: forwarded-headers{{ .HostSuffix }}
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: echo-service
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should inform the backend about the original HTTP request
+    When I send a "GET" request to "http://forwarded-headers${hostSuffix}/sub-path"
+    Then the response status-code must be 200
+    And the request must be forwarded with the "http" protocol
+    And the request must be forwarded for the "forwarded-headers${hostSuffix}" host
+    And the client IP address must be appended to the forwarded chain
+
+  Scenario: An Ingress should inform the backend about the original HTTPS request
+    When I send a "GET" request to "https://forwarded-headers${hostSuffix}/sub-path"
+    Then the response status-code must be 200
+    And the request must be forwarded with the "https" protocol
+    And the request must be forwarded for the "forwarded-headers${hostSuffix}" host
+    And the client IP address must be appended to the forwarded chain
+
+  Scenario: An Ingress should not forward the X-Forwarded-For chain sent by the client unchanged
+    Given the client sends the header "X-Forwarded-For" with value "203.0.113.10"
+    When I send a "GET" request to "http://forwarded-headers${hostSuffix}"
+    Then the response status-code must be 200
+    And the client IP address must be appended to the forwarded chain
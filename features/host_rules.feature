@@ -1,4 +1,4 @@
-@sig-network @conformance @release-1.19
+@sig-network @conformance @core @release-1.19
 Feature: Host rules
   An Ingress may define routing rules based on the request host.
   
@@ -7,7 +7,7 @@ Feature: Host rules
 
   Background:
     Given a new random namespace
-    Given a self-signed TLS secret named "conformance-tls" for the "foo.bar.com" hostname
+    Given a self-signed TLS secret named "conformance-tls" for the "foo.bar.com${hostSuffix}" hostname
     Given an Ingress resource
       """
       apiVersion: networking.k8s.io/v1
@@ -17,10 +17,10 @@ Feature: Host rules
       spec:
         tls:
           - hosts:
-              - foo.bar.com
+              - foo.bar.com{{ .HostSuffix }}
             secretName: conformance-tls
         rules:
-          - host: "*.foo.com"
+          - host: "*.foo.com{{ .HostSuffix }}"
             http:
               paths:
                 - path: /
@@ -31,7 +31,7 @@ Feature: Host rules
                       port:
                         number: 8080
 
-          - host: foo.bar.com
+          - host: foo.bar.com{{ .HostSuffix }}
             http:
               paths:
                 - path: /
@@ -47,42 +47,20 @@ Feature: Host rules
   Scenario: An Ingress with a host rule should send TLS traffic to the matching backend service
     (host foo.bar.com matches request foo.bar.com)
 
-    When I send a "GET" request to "https://foo.bar.com"
-    Then the secure connection must verify the "foo.bar.com" hostname
+    When I send a "GET" request to "https://foo.bar.com${hostSuffix}"
+    Then the secure connection must verify the "foo.bar.com${hostSuffix}" hostname
     And the response status-code must be 200
     And the response must be served by the "foo-bar-com" service
-    And the request host must be "foo.bar.com"
+    And the request host must be "foo.bar.com${hostSuffix}"
 
-  Scenario: An Ingress with a host rule should send traffic to the matching backend service
-    (host foo.bar.com matches request foo.bar.com)
-
-    When I send a "GET" request to "http://foo.bar.com"
-    And the response status-code must be 200
-    And the response must be served by the "foo-bar-com" service
-    And the request host must be "foo.bar.com"
-
-  Scenario: An Ingress with a host rule should not route traffic when hostname does not match
-    (host foo.bar.com does not match request subdomain.bar.com)
-
-    When I send a "GET" request to "http://subdomain.bar.com"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with a wildcard host rule should send traffic to the matching backend service
-    (Matches based on shared suffix)
-
-    When I send a "GET" request to "http://bar.foo.com"
-    Then the response status-code must be 200
-    And the response must be served by the "wildcard-foo-com" service
-    And the request host must be "bar.foo.com"
-
-  Scenario: An Ingress with a wildcard host rule should not route traffic matching on more than a single dns label
-    (No match, wildcard only covers a single DNS label)
-
-    When I send a "GET" request to "http://baz.bar.foo.com"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with a wildcard host rule should not route traffic matching no dns label
-    (No match, wildcard only covers a single DNS label)
+  Scenario: An Ingress with host rules should send traffic to the backend service of the matching host
+    (host foo.bar.com matches request foo.bar.com, but not subdomain.bar.com)
+    (wildcard host *.foo.com matches a single DNS label, like bar.foo.com, but not baz.bar.foo.com or foo.com)
 
-    When I send a "GET" request to "http://foo.com"
-    Then the response status-code must be 404
+    When I send "GET" requests to the hosts and paths, the responses must match
+      | host                           | path | status | service          | request host             |
+      | foo.bar.com${hostSuffix}       | /    | 200    | foo-bar-com      | foo.bar.com${hostSuffix} |
+      | subdomain.bar.com${hostSuffix} | /    | 404    |                  |                          |
+      | bar.foo.com${hostSuffix}       | /    | 200    | wildcard-foo-com | bar.foo.com${hostSuffix} |
+      | baz.bar.foo.com${hostSuffix}   | /    | 404    |                  |                          |
+      | foo.com${hostSuffix}           | /    | 404    |                  |                          |
@@ -0,0 +1,76 @@
+@sig-network @conformance @core @release-1.19
+Feature: Hostnames
+  The host of an Ingress rule may be a precise hostname or a wildcard.
+  A wildcard hostname is a hostname with a single wildcard label as
+  its first label, like *.example.com.
+
+  A wildcard host matches a request host when the request host has the
+  same suffix as the wildcard rule and a single label in place of the
+  wildcard. Precise host rules take precedence over wildcard rules.
+
+  Background:
+    Given an Ingress resource in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: hostnames
+      spec:
+        rules:
+          - host: "*.example.com{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: wildcard-example-com
+                      port:
+                        number: 8080
+
+          - host: "exact.example.com{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: exact-example-com
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with a wildcard host rule should send traffic for a single label to the matching backend service
+    (host *.example.com matches request foo.example.com)
+
+    When I send a "GET" request to "http://foo.example.com${hostSuffix}"
+    Then the response status-code must be 200
+    And the response must be served by the "wildcard-example-com" service
+    And the request host must be "foo.example.com${hostSuffix}"
+
+  Scenario: An Ingress with a wildcard host rule should not send traffic for more than a single label
+    (host *.example.com does not match request bar.foo.example.com)
+
+    When I send a "GET" request to "http://bar.foo.example.com${hostSuffix}"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a wildcard host rule should not send traffic for the bare domain
+    (host *.example.com does not match request example.com)
+
+    When I send a "GET" request to "http://example.com${hostSuffix}"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a wildcard host rule should not send traffic for a different suffix
+    (host *.example.com does not match request foo.example.org)
+
+    When I send a "GET" request to "http://foo.example.org${hostSuffix}"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with precise and wildcard host rules should prefer the precise host rule
+    (host exact.example.com is preferred to host *.example.com for request exact.example.com)
+
+    When I send a "GET" request to "http://exact.example.com${hostSuffix}"
+    Then the response status-code must be 200
+    And the response must be served by the "exact-example-com" service
+    And the request host must be "exact.example.com${hostSuffix}"
@@ -0,0 +1,83 @@
+@sig-network @conformance @core @release-1.19
+Feature: TLS termination
+  An Ingress secures the traffic of its hosts with the certificates of the
+  TLS secrets in the `tls` field of its spec. The ingress controller
+  terminates the TLS connections, presenting the certificate of the secret
+  that matches the hostname requested with SNI.
+
+  Plain HTTP requests for a TLS host are either redirected to HTTPS or
+  served in plain text, depending on the configuration of the ingress
+  controller. Both are conformant, as long as the request reaches the
+  backend service.
+
+  https://kubernetes.io/docs/concepts/services-networking/ingress/#tls
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "https-termination-tls" for the "https-termination${hostSuffix}" hostname
+    Given a self-signed TLS secret named "other-https-termination-tls" for the "other-https-termination${hostSuffix}" hostname
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: https-termination
+      spec:
+        tls:
+          - hosts:
+              - https-termination{{ .HostSuffix }}
+            secretName: https-termination-tls
+          - hosts:
+              - other-https-termination{{ .HostSuffix }}
+            secretName: other-https-termination-tls
+        rules:
+          - host: "https-termination{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: https-termination
+                      port:
+                        number: 8080
+
+          - host: "other-https-termination{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: other-https-termination
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with TLS should present the certificate of the secret of the host
+    (https-termination-tls secret matches request https-termination)
+
+    When I send a "GET" request to "https://https-termination${hostSuffix}/"
+    Then the secure connection must verify the "https-termination${hostSuffix}" hostname
+    And the response certificate must be the one of the "https-termination-tls" secret
+    And the response status-code must be 200
+    And the response must be served by the "https-termination" service
+    And the response should include the "Strict-Transport-Security" header
+    And the Server header of the response should not reveal a version
+
+  Scenario: An Ingress with TLS should select the certificate of the secret using the requested hostname
+    (other-https-termination-tls secret matches request other-https-termination)
+
+    When I send a "GET" request to "https://other-https-termination${hostSuffix}/"
+    Then the secure connection must verify the "other-https-termination${hostSuffix}" hostname
+    And the response certificate must be the one of the "other-https-termination-tls" secret
+    And the response status-code must be 200
+    And the response must be served by the "other-https-termination" service
+
+  Scenario: An Ingress with TLS should redirect plain HTTP requests to HTTPS or serve them in plain text
+    (request http://https-termination/ reaches https-termination, following redirects)
+
+    When I send a "GET" request to "http://https-termination${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "https-termination" service
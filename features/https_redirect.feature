@@ -0,0 +1,60 @@
+@sig-network @https-redirect @extended
+Feature: HTTPS redirect
+  An Ingress may terminate TLS for its hosts. The Ingress specification
+  does not define how plain HTTP requests to a TLS host are handled, so
+  redirecting them to HTTPS is an extended feature.
+
+  When the ingress controller redirects plain HTTP requests of a TLS host,
+  the redirect is permanent (301 or 308) and the location keeps the host,
+  the path and the query string of the request, only changing its scheme.
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "https-redirect" for the "https-redirect${hostSuffix}" hostname
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: https-redirect
+      spec:
+        tls:
+          - hosts:
+              - https-redirect{{ .HostSuffix }}
+            secretName: https-redirect
+        rules:
+          - host: "https-redirect{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: https-redirect
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with TLS should redirect plain HTTP requests to HTTPS
+    (request http://https-redirect/ is redirected to https://https-redirect/)
+
+    When I send a "GET" request to "http://https-redirect${hostSuffix}/" without following redirects
+    Then the response status-code must be 308 or 301
+    And the response must redirect to "https://https-redirect${hostSuffix}/"
+
+  Scenario: An Ingress with TLS should keep the path and query string of plain HTTP requests redirected to HTTPS
+    (request http://https-redirect/foo/bar?baz=qux&quux is redirected to https://https-redirect/foo/bar?baz=qux&quux)
+
+    When I send a "GET" request to "http://https-redirect${hostSuffix}/foo/bar?baz=qux&quux" without following redirects
+    Then the response status-code must be 308 or 301
+    And the response must redirect to "https://https-redirect${hostSuffix}/foo/bar?baz=qux&quux"
+
+  Scenario: An Ingress with TLS should serve the plain HTTP requests redirected to HTTPS
+    (request http://https-redirect/foo?bar=baz reaches https-redirect, following redirects)
+
+    When I send a "GET" request to "http://https-redirect${hostSuffix}/foo?bar=baz"
+    Then the secure connection must verify the "https-redirect${hostSuffix}" hostname
+    And the response status-code must be 200
+    And the response must be served by the "https-redirect" service
+    And the request path must be "/foo"
@@ -1,4 +1,4 @@
-@sig-network @conformance @release-1.19
+@sig-network @conformance @core @release-1.19
 Feature: Ingress class
   Ingresses can be implemented by different controllers, often with different configuration.
   Each Ingress definition could specify a class, a reference to an IngressClass resource that contains
@@ -16,7 +16,7 @@ Feature: Ingress class
       spec:
         ingressClassName: some-invalid-class-name
         rules:
-          - host: "ingress-class"
+          - host: "ingress-class{{ .HostSuffix }}"
             http:
               paths:
                 - path: /
@@ -0,0 +1,51 @@
+@sig-network @conformance @core @release-1.19
+Feature: Ingress class changes
+  The class of an Ingress can change after its creation. When the Ingress
+  no longer belongs to the ingress controller, the ingress controller stops
+  sending its traffic and removes the addresses it published in its status.
+  When the Ingress belongs to the ingress controller again, its traffic is
+  sent to its backend services and its status shows the addresses again.
+
+  https://kubernetes.io/docs/concepts/services-networking/ingress/#ingress-class
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: ingress-class-changes
+      spec:
+        rules:
+          - host: "ingress-class-changes{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: ingress-class-changes
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://ingress-class-changes${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "ingress-class-changes" service
+
+  Scenario: An Ingress changed to another class should not send traffic to its backend service
+    (the class of the Ingress is changed to an IngressClass of another controller)
+
+    When the class of the Ingress is changed to "ingress-class-changes-other-class"
+    Then the Ingress status must not show an IP address or FQDN within 120 seconds
+    And the requests to "http://ingress-class-changes${hostSuffix}/" must eventually return status-code 404
+
+  Scenario: An Ingress changed back to the class of the ingress controller should send traffic to its backend service
+    (the class of the Ingress is changed to another IngressClass and back)
+
+    When the class of the Ingress is changed to "ingress-class-changes-other-class"
+    Then the Ingress status must not show an IP address or FQDN within 120 seconds
+    When the class of the Ingress is changed back to the class of the ingress controller
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    And the requests to "http://ingress-class-changes${hostSuffix}/" must eventually be served by the "ingress-class-changes" service
@@ -0,0 +1,47 @@
+@sig-network @conformance @core @release-1.19
+Feature: Ingress status
+  The ingress controller publishes the IP addresses or hostnames where an
+  Ingress is exposed in the `status.loadBalancer.ingress` field of the
+  Ingress, shortly after its creation.
+
+  When the Ingress no longer belongs to the ingress controller, because its
+  class changed, the ingress controller removes the addresses it published.
+
+  https://kubernetes.io/docs/concepts/services-networking/ingress/#ingress-class
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: ingress-status
+      spec:
+        rules:
+          - host: "ingress-status{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: ingress-status
+                      port:
+                        number: 8080
+      """
+
+  Scenario: An Ingress should show a reachable IP address or FQDN in its status after its creation
+    (the address is published within 120 seconds)
+
+    Then the Ingress status must show an IP address or FQDN within 120 seconds
+    When I send a "GET" request to "http://ingress-status${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "ingress-status" service
+
+  Scenario: An Ingress should not show an IP address or FQDN in its status once it belongs to another class
+    (the class of the Ingress is changed to an IngressClass that does not exist)
+
+    Then the Ingress status must show an IP address or FQDN within 120 seconds
+    When the class of the Ingress is changed to "ingress-status-other-class"
+    Then the Ingress status must not show an IP address or FQDN within 120 seconds
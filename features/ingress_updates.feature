@@ -0,0 +1,110 @@
+@sig-network @conformance @core @release-1.19
+Feature: Ingress updates
+  The ingress controller must follow the changes of an Ingress after its
+  creation. Requests are routed according to the new rules of an updated
+  Ingress, and are no longer routed to its backends once it is deleted.
+
+  Changes are expected to reach the ingress controller within the
+  convergence window set with the -max-wait flag.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: ingress-updates
+      spec:
+        rules:
+          - host: "ingress-updates{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /bar
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: bar-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://ingress-updates${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: An updated Ingress should send traffic to the new backend service of a path
+    (prefix /foo is changed from foo-prefix to updated-foo-prefix)
+
+    When the Ingress resource is updated
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: ingress-updates
+      spec:
+        rules:
+          - host: "ingress-updates{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: updated-foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /bar
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: bar-prefix
+                      port:
+                        number: 8080
+      """
+    Then the requests to "http://ingress-updates${hostSuffix}/foo" must eventually be served by the "updated-foo-prefix" service
+    When I send a "GET" request to "http://ingress-updates${hostSuffix}/bar"
+    Then the response status-code must be 200
+    And the response must be served by the "bar-prefix" service
+
+  Scenario: An updated Ingress should not send traffic for a removed path
+    (prefix /bar is removed)
+
+    When the Ingress resource is updated
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: ingress-updates
+      spec:
+        rules:
+          - host: "ingress-updates{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+      """
+    Then the requests to "http://ingress-updates${hostSuffix}/bar" must eventually return status-code 404
+    When I send a "GET" request to "http://ingress-updates${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: A deleted Ingress should not send traffic to its backend services
+    (the Ingress is deleted)
+
+    When the Ingress resource is deleted
+    Then the requests to "http://ingress-updates${hostSuffix}/foo" must eventually return status-code 404
@@ -0,0 +1,84 @@
+@sig-network @conformance @core @release-1.19
+Feature: Invalid TLS secrets
+  The TLS secrets referenced in the `tls` field of an Ingress may not exist,
+  or may not contain a valid certificate and key. The ingress controller
+  must not present a certificate valid for the hosts of those secrets: it
+  either presents its default certificate or terminates the TLS handshake.
+
+  The other hosts of the Ingress, with valid TLS secrets, must keep working.
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "valid-tls-secrets" for the "valid-tls-secrets${hostSuffix}" hostname
+    Given a malformed TLS secret named "malformed-tls-secrets"
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: invalid-tls-secrets
+      spec:
+        tls:
+          - hosts:
+              - valid-tls-secrets{{ .HostSuffix }}
+            secretName: valid-tls-secrets
+          - hosts:
+              - missing-tls-secrets{{ .HostSuffix }}
+            secretName: missing-tls-secrets
+          - hosts:
+              - malformed-tls-secrets{{ .HostSuffix }}
+            secretName: malformed-tls-secrets
+        rules:
+          - host: "valid-tls-secrets{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: valid-tls-secrets
+                      port:
+                        number: 8080
+
+          - host: "missing-tls-secrets{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: missing-tls-secrets
+                      port:
+                        number: 8080
+
+          - host: "malformed-tls-secrets{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: malformed-tls-secrets
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with a TLS secret that does not exist should not present a certificate for the host
+    (missing-tls-secrets secret does not exist)
+
+    Then the secure connection to "https://missing-tls-secrets${hostSuffix}/" must not present a certificate valid for the "missing-tls-secrets${hostSuffix}" hostname
+
+  Scenario: An Ingress with a malformed TLS secret should not present a certificate for the host
+    (malformed-tls-secrets secret does not contain valid PEM data)
+
+    Then the secure connection to "https://malformed-tls-secrets${hostSuffix}/" must not present a certificate valid for the "malformed-tls-secrets${hostSuffix}" hostname
+
+  Scenario: An Ingress with invalid TLS secrets should keep sending TLS traffic for the hosts with valid TLS secrets
+    (valid-tls-secrets secret matches request valid-tls-secrets)
+
+    When I send a "GET" request to "https://valid-tls-secrets${hostSuffix}/"
+    Then the secure connection must verify the "valid-tls-secrets${hostSuffix}" hostname
+    And the response certificate must be the one of the "valid-tls-secrets" secret
+    And the response status-code must be 200
+    And the response must be served by the "valid-tls-secrets" service
@@ -0,0 +1,45 @@
+@sig-network @keep-alive @extended
+Feature: Keep-alive connections
+  Ingress controllers should keep the connections of the clients open
+  between requests, and may keep their connections to the backend pods
+  open too (upstream keep-alive), instead of opening a new connection
+  for each request.
+
+  The echoserver reports the connection of each request it receives,
+  and the number of requests received in that connection.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: keep-alive
+      spec:
+        rules:
+          - host: "keep-alive{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: keep-alive
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should keep the connections of the clients open between requests
+    Given the requests keep their connections open
+    When I send a "GET" request to "http://keep-alive${hostSuffix}/"
+    Then the response status-code must be 200
+    When I send a "GET" request to "http://keep-alive${hostSuffix}/"
+    Then the response status-code must be 200
+    And the request must reuse the connection of a previous request
+
+  Scenario: An Ingress should reuse its connections to the backend pods
+    When I send 20 requests to "http://keep-alive${hostSuffix}/"
+    Then all the responses status-code must be 200
+    And the connections to the backend pods must be reused
@@ -0,0 +1,90 @@
+@sig-network @multiple-ingresses @extended
+Feature: Multiple Ingresses
+  Multiple Ingress resources may define rules for the same host. The
+  ingress controller merges their rules, so the paths of every Ingress are
+  served on the host.
+
+  When two Ingress resources define the same path for the same host, the
+  rule of the oldest Ingress, by creation timestamp, is used. Merging
+  Ingresses and resolving their conflicts is not part of the Ingress spec,
+  the conflict resolution of this feature is the one of ingress-nginx.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: multiple-ingresses-older
+      spec:
+        rules:
+          - host: "multiple-ingresses{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /conflict
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: older-conflict
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Given a newer Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: multiple-ingresses-newer
+      spec:
+        rules:
+          - host: "multiple-ingresses{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /bar
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: bar-prefix
+                      port:
+                        number: 8080
+
+                - path: /conflict
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: newer-conflict
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress sharing a host with another Ingress should send traffic for its path to its backend service
+    (prefix /foo of the older Ingress matches request /foo)
+
+    When I send a "GET" request to "http://multiple-ingresses${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: An Ingress sharing a host with an older Ingress should send traffic for its path to its backend service
+    (prefix /bar of the newer Ingress matches request /bar)
+
+    When I send a "GET" request to "http://multiple-ingresses${hostSuffix}/bar"
+    Then the response status-code must be 200
+    And the response must be served by the "bar-prefix" service
+
+  Scenario: Ingresses defining the same path for the same host should send traffic to the backend service of the oldest Ingress
+    (prefix /conflict of the older Ingress is preferred to prefix /conflict of the newer Ingress)
+
+    When I send a "GET" request to "http://multiple-ingresses${hostSuffix}/conflict"
+    Then the response status-code must be 200
+    And the response must be served by the "older-conflict" service
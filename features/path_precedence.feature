@@ -0,0 +1,86 @@
+@sig-network @conformance @core @release-1.19
+Feature: Path precedence
+  When more than one path of the rules of a host matches the request path,
+  the longest matching path takes precedence. If two matching paths have
+  the same length, an Exact path takes precedence over a Prefix path.
+
+  Background:
+    Given an Ingress resource in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: path-precedence
+      spec:
+        rules:
+          - host: "path-precedence{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: root-prefix
+                      port:
+                        number: 8080
+
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /foo
+                  pathType: Exact
+                  backend:
+                    service:
+                      name: foo-exact
+                      port:
+                        number: 8080
+
+                - path: /foo/bar
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-bar-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with an exact and a prefix path of the same length should prefer the exact path
+    (exact /foo is preferred to prefix /foo for request /foo)
+
+    When I send a "GET" request to "http://path-precedence${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-exact" service
+
+  Scenario: An Ingress with an exact and a prefix path of the same length should use the prefix path for subpaths
+    (prefix /foo matches request /foo/ and exact /foo does not)
+
+    When I send a "GET" request to "http://path-precedence${hostSuffix}/foo/"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: An Ingress with nested prefix paths should prefer the longest matching path
+    (prefix /foo/bar is preferred to prefix /foo and prefix / for request /foo/bar/baz)
+
+    When I send a "GET" request to "http://path-precedence${hostSuffix}/foo/bar/baz"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-bar-prefix" service
+
+  Scenario: An Ingress with several matching paths should send each request to the backend service of the most specific path
+    When I send "GET" requests to the "http://path-precedence${hostSuffix}" paths, they must be served by the backend services
+      | path         | service        |
+      | /            | root-prefix    |
+      | /other       | root-prefix    |
+      | /foobar      | root-prefix    |
+      | /foo         | foo-exact      |
+      | /foo/        | foo-prefix     |
+      | /foo/baz     | foo-prefix     |
+      | /foo/barbaz  | foo-prefix     |
+      | /foo/bar     | foo-bar-prefix |
+      | /foo/bar/    | foo-bar-prefix |
+      | /foo/bar/baz | foo-bar-prefix |
@@ -1,4 +1,4 @@
-@sig-network @conformance @release-1.19
+@sig-network @conformance @core @release-1.19
 Feature: Path rules
   An Ingress may define routing rules based on the request path.
   
@@ -14,7 +14,7 @@ Feature: Path rules
         name: path-rules
       spec:
         rules:
-          - host: "exact-path-rules"
+          - host: "exact-path-rules{{ .HostSuffix }}"
             http:
               paths:
                 - path: /foo
@@ -25,7 +25,7 @@ Feature: Path rules
                       port:
                         number: 8080
 
-          - host: "prefix-path-rules"
+          - host: "prefix-path-rules{{ .HostSuffix }}"
             http:
               paths:
                 - path: /foo
@@ -52,7 +52,7 @@ Feature: Path rules
                       port:
                         number: 8080
 
-          - host: "mixed-path-rules"
+          - host: "mixed-path-rules{{ .HostSuffix }}"
             http:
               paths:
                 - path: /foo
@@ -71,7 +71,7 @@ Feature: Path rules
                       port:
                         number: 8080
 
-          - host: "trailing-slash-path-rules"
+          - host: "trailing-slash-path-rules{{ .HostSuffix }}"
             http:
               paths:
                 - path: /aaa/bbb/
@@ -92,108 +92,45 @@ Feature: Path rules
       """
     Then The Ingress status shows the IP address or FQDN where it is exposed
 
-  Scenario: An Ingress with exact path rules should send traffic to the matching backend service
+  Scenario: An Ingress with exact path rules should only send traffic to the backend service of the exact path
     (exact /foo matches request /foo)
-
-    When I send a "GET" request to "http://exact-path-rules/foo"
-    Then the response status-code must be 200
-    And the response must be served by the "foo-exact" service
-
-  Scenario: An Ingress with exact path rules should not match requests with trailing slash
-    (exact /foo does not match request /foo/)
-
-    When I send a "GET" request to "http://exact-path-rules/foo/"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with exact path rules should be case sensitive
-    (exact /foo does not match request /FOO)
-
-    When I send a "GET" request to "http://exact-path-rules/FOO"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with exact path rules should not match any other label
-    (exact /foo does not match request /bar)
-
-    When I send a "GET" request to "http://exact-path-rules/bar"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with prefix path rules should send traffic to the matching backend service
-    (prefix /foo matches request /foo)
-
-    When I send a "GET" request to "http://prefix-path-rules/foo"
-    Then the response status-code must be 200
-    And the response must be served by the "foo-prefix" service
-
-  Scenario: An Ingress with prefix path rules should ignore the request trailing slash and send traffic to the matching backend service
-    (prefix /foo matches request /foo/)
-
-    When I send a "GET" request to "http://prefix-path-rules/foo/"
-    Then the response status-code must be 200
-    And the response must be served by the "foo-prefix" service
-
-  Scenario: An Ingress with prefix path rules should be case sensitive
-    (prefix /foo does not match request /FOO)
-
-    When I send a "GET" request to "http://prefix-path-rules/FOO"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with prefix path rules should match multiple labels, match the longest path, and send traffic to the matching backend service
-    (prefix /aaa/bbb matches request /aaa/bbb)
-
-    When I send a "GET" request to "http://prefix-path-rules/aaa/bbb"
-    Then the response status-code must be 200
-    And the response must be served by the "aaa-slash-bbb-prefix" service
-
-  Scenario: An Ingress with prefix path rules should match multiple labels, match the longest path, and subpaths and send traffic to the matching backend service
-    (prefix /aaa/bbb matches request /aaa/bbb/ccc)
-
-    When I send a "GET" request to "http://prefix-path-rules/aaa/bbb/ccc"
-    Then the response status-code must be 200
-    And the response must be served by the "aaa-slash-bbb-prefix" service
-
-  Scenario: An Ingress with prefix path rules should and send traffic to the matching backend service
-    (prefix /aaa matches request /aaa/ccc)
-
-    When I send a "GET" request to "http://prefix-path-rules/aaa/ccc"
-    Then the response status-code must be 200
-    And the response must be served by the "aaa-prefix" service
-
-  Scenario: An Ingress with prefix path rules should match each labels string prefix
-    (prefix /aaa does not match request /aaaccc)
-
-    When I send a "GET" request to "http://prefix-path-rules/aaaccc"
-    Then the response status-code must be 404
-
-  Scenario: An Ingress with prefix path rules should ignore the request trailing slash and send traffic to the matching backend service
-    (prefix /foo matches request /foo/)
-
-    When I send a "GET" request to "http://prefix-path-rules/foo/"
-    Then the response status-code must be 200
-    And the response must be served by the "foo-prefix" service
+    (exact /foo does not match request /foo/, /FOO or /bar)
+
+    When I send "GET" requests to the hosts and paths, the responses must match
+      | host                          | path  | status | service   |
+      | exact-path-rules${hostSuffix} | /foo  | 200    | foo-exact |
+      | exact-path-rules${hostSuffix} | /foo/ | 404    |           |
+      | exact-path-rules${hostSuffix} | /FOO  | 404    |           |
+      | exact-path-rules${hostSuffix} | /bar  | 404    |           |
+
+  Scenario: An Ingress with prefix path rules should send traffic to the backend service of the longest matching prefix
+    (prefix /foo matches request /foo and /foo/, but not /FOO)
+    (prefix /aaa/bbb matches request /aaa/bbb and /aaa/bbb/ccc)
+    (prefix /aaa matches request /aaa/ccc, but not /aaaccc as it matches each label string prefix)
+
+    When I send "GET" requests to the hosts and paths, the responses must match
+      | host                           | path         | status | service              |
+      | prefix-path-rules${hostSuffix} | /foo         | 200    | foo-prefix           |
+      | prefix-path-rules${hostSuffix} | /foo/        | 200    | foo-prefix           |
+      | prefix-path-rules${hostSuffix} | /FOO         | 404    |                      |
+      | prefix-path-rules${hostSuffix} | /aaa/bbb     | 200    | aaa-slash-bbb-prefix |
+      | prefix-path-rules${hostSuffix} | /aaa/bbb/ccc | 200    | aaa-slash-bbb-prefix |
+      | prefix-path-rules${hostSuffix} | /aaa/ccc     | 200    | aaa-prefix           |
+      | prefix-path-rules${hostSuffix} | /aaaccc      | 404    |                      |
 
   Scenario: An Ingress with mixed path rules should send traffic to the matching backend service where Exact is preferred
     (exact /foo matches request /foo)
 
-    When I send a "GET" request to "http://mixed-path-rules/foo"
-    Then the response status-code must be 200
-    And the response must be served by the "foo-exact" service
-
-  Scenario: An Ingress with a trailing slashes in a prefix path rule should ignore the trailing slash and send traffic to the matching backend service
-    (prefix /aaa/bbb/ matches request /aaa/bbb)
-
-    When I send a "GET" request to "http://trailing-slash-path-rules/aaa/bbb"
-    Then the response status-code must be 200
-    And the response must be served by the "aaa-slash-bbb-slash-prefix" service
-
-  Scenario: An Ingress with a trailing slashes in a prefix path rule should ignore the trailing slash and send traffic to the matching backend service
-    (prefix /aaa/bbb/ matches request /aaa/bbb/)
-
-    When I send a "GET" request to "http://trailing-slash-path-rules/aaa/bbb/"
-    Then the response status-code must be 200
-    And the response must be served by the "aaa-slash-bbb-slash-prefix" service
+    When I send "GET" requests to the hosts and paths, the responses must match
+      | host                          | path | status | service   |
+      | mixed-path-rules${hostSuffix} | /foo | 200    | foo-exact |
 
-  Scenario: An Ingress with a trailing slashes in an exact path rule should not match requests without a trailing slash
+  Scenario: An Ingress with trailing slashes in its path rules should ignore the trailing slash of prefix paths only
+    (prefix /aaa/bbb/ matches request /aaa/bbb and /aaa/bbb/)
     (exact /foo/ does not match request /foo)
 
-    When I send a "GET" request to "http://trailing-slash-path-rules/foo"
-    Then the response status-code must be 404
+    When I send "GET" requests to the hosts and paths, the responses must match
+      | host                                   | path      | status | service                    |
+      | trailing-slash-path-rules${hostSuffix} | /aaa/bbb  | 200    | aaa-slash-bbb-slash-prefix |
+      | trailing-slash-path-rules${hostSuffix} | /aaa/bbb/ | 200    | aaa-slash-bbb-slash-prefix |
+      | trailing-slash-path-rules${hostSuffix} | /foo      | 404    |                            |
@@ -0,0 +1,188 @@
+@sig-network @conformance @core @release-1.19
+Feature: Path types
+  Each path of an Ingress rule has a path type that defines how the path
+  is matched against the request path.
+
+  Exact matches the URL path exactly and with case sensitivity.
+
+  Prefix matches based on a URL path prefix split by "/". Matching is done
+  on a path element by element basis: /foo matches /foo and /foo/bar, but
+  not /foobar.
+
+  ImplementationSpecific matching is up to the IngressClass. It must match
+  at least the path itself, and other requests are routed by the rest of
+  the rules of the host.
+
+  Background:
+    Given an Ingress resource in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: path-types
+      spec:
+        rules:
+          - host: "exact-path-types{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Exact
+                  backend:
+                    service:
+                      name: foo-exact
+                      port:
+                        number: 8080
+
+                - path: /bar/
+                  pathType: Exact
+                  backend:
+                    service:
+                      name: bar-slash-exact
+                      port:
+                        number: 8080
+
+          - host: "prefix-path-types{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /aaa/bbb
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: aaa-slash-bbb-prefix
+                      port:
+                        number: 8080
+
+          - host: "root-prefix-path-types{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: root-prefix
+                      port:
+                        number: 8080
+
+          - host: "implementation-specific-path-types{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /impl
+                  pathType: ImplementationSpecific
+                  backend:
+                    service:
+                      name: impl-implementation-specific
+                      port:
+                        number: 8080
+
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: fallback-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress with an exact path type should send traffic to the matching backend service
+    (exact /foo matches request /foo)
+
+    When I send a "GET" request to "http://exact-path-types${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-exact" service
+    And the request path must be "/foo"
+
+  Scenario: An Ingress with an exact path type should not match requests with an additional trailing slash
+    (exact /foo does not match request /foo/)
+
+    When I send a "GET" request to "http://exact-path-types${hostSuffix}/foo/"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with an exact path type should not match requests with a longer path
+    (exact /foo does not match request /foo/bar)
+
+    When I send a "GET" request to "http://exact-path-types${hostSuffix}/foo/bar"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with an exact path type with a trailing slash should send traffic to the matching backend service
+    (exact /bar/ matches request /bar/)
+
+    When I send a "GET" request to "http://exact-path-types${hostSuffix}/bar/"
+    Then the response status-code must be 200
+    And the response must be served by the "bar-slash-exact" service
+
+  Scenario: An Ingress with an exact path type with a trailing slash should not match requests without the trailing slash
+    (exact /bar/ does not match request /bar)
+
+    When I send a "GET" request to "http://exact-path-types${hostSuffix}/bar"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a prefix path type should send traffic to the matching backend service
+    (prefix /foo matches request /foo)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: An Ingress with a prefix path type should match subpaths element by element
+    (prefix /foo matches request /foo/bar)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/foo/bar"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+    And the request path must be "/foo/bar"
+
+  Scenario: An Ingress with a prefix path type should not match a partial path element
+    (prefix /foo does not match request /foobar)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/foobar"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a multiple element prefix path type should match subpaths element by element
+    (prefix /aaa/bbb matches request /aaa/bbb/ccc)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/aaa/bbb/ccc"
+    Then the response status-code must be 200
+    And the response must be served by the "aaa-slash-bbb-prefix" service
+
+  Scenario: An Ingress with a multiple element prefix path type should not match a partial last path element
+    (prefix /aaa/bbb does not match request /aaa/bbbccc)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/aaa/bbbccc"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a multiple element prefix path type should not match a parent path
+    (prefix /aaa/bbb does not match request /aaa)
+
+    When I send a "GET" request to "http://prefix-path-types${hostSuffix}/aaa"
+    Then the response status-code must be 404
+
+  Scenario: An Ingress with a root prefix path type should match every request path
+    (prefix / matches request /any/path)
+
+    When I send a "GET" request to "http://root-prefix-path-types${hostSuffix}/any/path"
+    Then the response status-code must be 200
+    And the response must be served by the "root-prefix" service
+    And the request path must be "/any/path"
+
+  Scenario: An Ingress with an implementation specific path type should send traffic for the path to the matching backend service
+    (implementation specific /impl matches request /impl)
+
+    When I send a "GET" request to "http://implementation-specific-path-types${hostSuffix}/impl"
+    Then the response status-code must be 200
+    And the response must be served by the "impl-implementation-specific" service
+
+  Scenario: An Ingress with an implementation specific path type should send other requests to the rest of the rules
+    (prefix / matches request /other when implementation specific /impl does not)
+
+    When I send a "GET" request to "http://implementation-specific-path-types${hostSuffix}/other"
+    Then the response status-code must be 200
+    And the response must be served by the "fallback-prefix" service
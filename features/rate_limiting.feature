@@ -0,0 +1,66 @@
+@sig-network @rate-limiting @extended
+Feature: Rate limiting
+  Ingress controllers may limit the rate of the requests sent by each client
+  when an annotation of the Ingress enables it. The requests above the limit
+  are rejected with a 429 (Too Many Requests) response, or the status code
+  in the -rate-limit-status-code flag, optionally with a Retry-After header.
+  The requests allowed by the limit are sent to the backend service.
+
+  Rate limiting is not part of the Ingress spec, and the ingress controllers
+  allow bursts of different sizes. The scenarios send bursts of concurrent
+  requests much larger than the limit. The abstract annotations are
+  translated to the annotations of the ingress controller (ingress-nginx by
+  default).
+
+  Scenario: An Ingress with a rate limit should reject the requests above the limit
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: rate-limited
+        annotations:
+          conformance.ingress.k8s.io/rate-limit-rps: "1"
+      spec:
+        rules:
+          - host: "rate-limited{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: rate-limited
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send 50 requests to "http://rate-limited${hostSuffix}/" with 10 concurrent clients
+    Then some of the responses must be rejected by the rate limit
+    And the rejected responses must have a valid Retry-After header, if any
+
+  Scenario: An Ingress without rate limit should not reject a burst of requests
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: not-rate-limited
+      spec:
+        rules:
+          - host: "not-rate-limited{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: not-rate-limited
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send 50 requests to "http://not-rate-limited${hostSuffix}/" with 10 concurrent clients
+    Then all the responses status-code must be 200
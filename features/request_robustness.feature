@@ -0,0 +1,64 @@
+@sig-network @request-robustness @extended
+Feature: Request robustness
+  The ingress controller is the first HTTP server receiving the requests
+  of the clients, so it must reject the requests it cannot forward safely
+  instead of sending them to the backend services.
+
+  Requests with header sections larger than the limits of the ingress
+  controller are rejected with a client error (4xx), like 400 or 431.
+
+  Requests framing their body ambiguously, with different Content-Length
+  headers or with both Transfer-Encoding and Content-Length headers, are
+  rejected with a client error (4xx). Otherwise the ingress controller and
+  the backend may disagree on where the next request in the connection
+  starts, and a request could be smuggled to the backend.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: request-robustness
+      spec:
+        rules:
+          - host: "request-robustness{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: request-robustness
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://request-robustness${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "request-robustness" service
+
+  Scenario: An Ingress should reject requests with a very large header
+    (a single header of 65536 bytes)
+
+    When I send a raw "GET" request to "request-robustness${hostSuffix}" with a header of 65536 bytes
+    Then the raw response status-code must be a client error
+
+  Scenario: An Ingress should reject requests with a very large header section
+    (256 headers of 1024 bytes)
+
+    When I send a raw "GET" request to "request-robustness${hostSuffix}" with 256 headers of 1024 bytes
+    Then the raw response status-code must be a client error
+
+  Scenario: An Ingress should reject requests with different Content-Length headers
+    (Content-Length: 11 and Content-Length: 10)
+
+    When I send a raw "POST" request to "request-robustness${hostSuffix}" with different Content-Length headers
+    Then the raw response status-code must be a client error
+
+  Scenario: An Ingress should reject requests with both Transfer-Encoding and Content-Length headers
+    (Transfer-Encoding: chunked and Content-Length: 6)
+
+    When I send a raw "POST" request to "request-robustness${hostSuffix}" with Transfer-Encoding and Content-Length headers
+    Then the raw response status-code must be a client error
@@ -0,0 +1,87 @@
+@sig-network @request-targets @extended
+Feature: Request targets
+  The request-target of an HTTP/1.1 request line is not always a path.
+  Clients may send the absolute-form (GET http://host/path HTTP/1.1),
+  which servers must accept, and requests without a Host header, which
+  servers must reject with a 400 (Bad Request) status code.
+
+  Percent-encoded slashes are not path separators, so they must not be
+  decoded to resolve dot-segments and send the request to a backend
+  service of another path.
+
+  https://www.rfc-editor.org/rfc/rfc7230#section-5.3
+  https://www.rfc-editor.org/rfc/rfc7230#section-5.4
+  https://www.rfc-editor.org/rfc/rfc3986#section-2.2
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: request-targets
+      spec:
+        rules:
+          - host: "request-targets{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /admin
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: admin-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://request-targets${hostSuffix}/foo"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+
+  Scenario: An Ingress should send requests with an absolute-form request-target to the matching backend service
+    (absolute-form http://request-targets/foo matches request /foo)
+
+    When I send the raw HTTP request to "request-targets${hostSuffix}"
+      """
+      GET http://request-targets${hostSuffix}/foo HTTP/1.1
+      Host: request-targets${hostSuffix}
+      Connection: close
+      """
+    Then the raw response status-code must be 200
+    And the raw response must be served by the "foo-prefix" service
+
+  Scenario: An Ingress should reject HTTP/1.1 requests without a Host header
+    (HTTP/1.1 request without Host header is a bad request)
+
+    When I send the raw HTTP request to "request-targets${hostSuffix}"
+      """
+      GET /foo HTTP/1.1
+      Connection: close
+      """
+    Then the raw response status-code must be 400
+
+  Scenario Outline: An Ingress should not decode percent-encoded slashes to resolve dot-segments
+    (<path> does not match prefix /admin)
+
+    When I send the raw HTTP request to "request-targets${hostSuffix}"
+      """
+      GET <path> HTTP/1.1
+      Host: request-targets${hostSuffix}
+      Connection: close
+      """
+    Then the raw response must not be served by the "admin-prefix" service
+
+    Examples:
+      | path                |
+      | /foo/..%2fadmin     |
+      | /foo/..%2Fadmin     |
+      | /foo/%2e%2e%2fadmin |
@@ -0,0 +1,106 @@
+@sig-network @rewrite @extended
+Feature: Path rewrite
+  Ingress controllers may rewrite the path of the requests before sending
+  them to the backend services, when an annotation of the Ingress enables
+  it. The rewrite target replaces the path matched by a regular expression,
+  and can reference its capture groups as $1, $2...
+
+  Path rewrites are not part of the Ingress spec. The paths of the rules
+  are regular expressions, so their path type is ImplementationSpecific.
+  The abstract annotations are translated to the annotations of the ingress
+  controller (ingress-nginx by default), and the scenarios are reported as
+  unsupported when the ingress controller does not translate them.
+
+  Scenario: An Ingress with a rewrite target should strip the prefix of the path
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: rewrite-prefix
+        annotations:
+          conformance.ingress.k8s.io/use-regex: "true"
+          conformance.ingress.k8s.io/rewrite-target: /$2
+      spec:
+        rules:
+          - host: "rewrite-prefix{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /strip(/|$)(.*)
+                  pathType: ImplementationSpecific
+                  backend:
+                    service:
+                      name: rewrite-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://rewrite-prefix${hostSuffix}/strip/foo/bar"
+    Then the response status-code must be 200
+    And the response must be served by the "rewrite-prefix" service
+    And the request path must be "/foo/bar"
+    When I send a "GET" request to "http://rewrite-prefix${hostSuffix}/strip"
+    Then the response status-code must be 200
+    And the response must be served by the "rewrite-prefix" service
+    And the request path must be "/"
+
+  Scenario: An Ingress with a rewrite target should keep the query of the request
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: rewrite-query
+        annotations:
+          conformance.ingress.k8s.io/use-regex: "true"
+          conformance.ingress.k8s.io/rewrite-target: /$2
+      spec:
+        rules:
+          - host: "rewrite-query{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /strip(/|$)(.*)
+                  pathType: ImplementationSpecific
+                  backend:
+                    service:
+                      name: rewrite-query
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://rewrite-query${hostSuffix}/strip/foo?bar=baz"
+    Then the response status-code must be 200
+    And the response must be served by the "rewrite-query" service
+    And the request path must be "/foo?bar=baz"
+
+  Scenario: An Ingress with a rewrite target should replace the capture groups of the path
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: rewrite-capture
+        annotations:
+          conformance.ingress.k8s.io/use-regex: "true"
+          conformance.ingress.k8s.io/rewrite-target: /version/$1/$2
+      spec:
+        rules:
+          - host: "rewrite-capture{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /api/v([0-9]+)/(.*)
+                  pathType: ImplementationSpecific
+                  backend:
+                    service:
+                      name: rewrite-capture
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://rewrite-capture${hostSuffix}/api/v2/users/42"
+    Then the response status-code must be 200
+    And the response must be served by the "rewrite-capture" service
+    And the request path must be "/version/2/users/42"
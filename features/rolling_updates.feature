@@ -0,0 +1,42 @@
+@sig-network @rolling-updates @extended
+Feature: Rolling updates
+  The pods of a backend service are replaced during a rolling update of
+  their deployment. The ingress controller must follow the changes of the
+  endpoints of the service, so requests keep being served while pods are
+  created and terminated.
+
+  A small fraction of the requests may fail while the endpoints change, up
+  to the maximum error rate set with the -max-error-rate flag.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: rolling-updates
+      spec:
+        rules:
+          - host: "rolling-updates{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: rolling-update-backend
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Then The backend deployment "rolling-update-backend" for the ingress resource is scaled to 2
+
+  Scenario: An Ingress should keep sending traffic to a backend service during a rolling update of its pods
+    (rolling-update-backend pods are restarted while requests are sent)
+
+    When I send a "GET" request to "http://rolling-updates${hostSuffix}/"
+    Then the response status-code must be 200
+    Given I keep sending "GET" requests to "http://rolling-updates${hostSuffix}/" in the background
+    When The backend deployment "rolling-update-backend" for the ingress resource is restarted
+    Then the requests sent in the background must fail at most at the maximum error rate
@@ -0,0 +1,45 @@
+@sig-network @session-affinity @extended
+Feature: Session affinity
+  An Ingress exposing a backend service with multiple replicas may keep a client
+  on the same pod, using a cookie set in the first response (sticky sessions).
+  
+  Session affinity is not part of the Ingress spec. The annotations used in this
+  feature are the ones supported by ingress-nginx.
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: session-affinity
+        annotations:
+          nginx.ingress.kubernetes.io/affinity: cookie
+          nginx.ingress.kubernetes.io/session-cookie-name: INGRESSCOOKIE
+      spec:
+        rules:
+          - host: "session-affinity{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: echo-service
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    Then The backend deployment "echo-service" for the ingress resource is scaled to 3
+
+  Scenario: An Ingress with cookie based session affinity should send all the requests of a client to the same pod
+    Given the client keeps cookies between requests
+    When I send a "GET" request to "http://session-affinity${hostSuffix}"
+    Then the response status-code must be 200
+    And the response must set a cookie named "INGRESSCOOKIE"
+    And I save the pod serving the request as "firstPod"
+    When I send 20 requests to "http://session-affinity${hostSuffix}"
+    Then all the requests must be served by the same pod
+    When I send a "GET" request to "http://session-affinity${hostSuffix}"
+    Then the request must be served by the "${firstPod}" pod
@@ -0,0 +1,51 @@
+@sig-network @default-certificate @extended
+Feature: SNI fallback
+  The ingress controller selects the certificate presented in a TLS
+  handshake using the server name sent by the client (SNI). When the server
+  name matches no host of the Ingresses, the ingress controller either
+  presents its default certificate or terminates the handshake.
+
+  The certificate of an Ingress must not be presented for other server
+  names, and the same default certificate must be presented for all the
+  server names without a matching host.
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "sni-fallback" for the "sni-fallback${hostSuffix}" hostname
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: sni-fallback
+      spec:
+        tls:
+          - hosts:
+              - sni-fallback{{ .HostSuffix }}
+            secretName: sni-fallback
+        rules:
+          - host: "sni-fallback{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: sni-fallback
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: A TLS handshake with a server name matching no host should not present the certificate of an Ingress
+    (server name unknown-sni-fallback matches no host)
+
+    When I send a "GET" request to "https://sni-fallback${hostSuffix}/" with the TLS server name "unknown-sni-fallback${hostSuffix}"
+    Then the TLS handshake must be terminated or must not present the certificate of the "sni-fallback" secret
+
+  Scenario: TLS handshakes with server names matching no host should present the same default certificate
+    (server names unknown-sni-fallback and other-unknown-sni-fallback match no host)
+
+    When I send a "GET" request to "https://sni-fallback${hostSuffix}/" with the TLS server name "unknown-sni-fallback${hostSuffix}"
+    And I send a "GET" request to "https://sni-fallback${hostSuffix}/" with the TLS server name "other-unknown-sni-fallback${hostSuffix}"
+    Then the TLS handshakes must be terminated or must present the same certificate
@@ -0,0 +1,128 @@
+@sig-network @source-ranges @extended
+Feature: Source IP ranges
+  Ingress controllers may restrict the clients allowed to send requests to
+  the backend services by their IP address, when an annotation of the
+  Ingress lists the allowed or denied ranges, in CIDR notation. Requests
+  from other clients are rejected with a 403 (Forbidden) response.
+
+  Source IP ranges are not part of the Ingress spec. The abstract
+  annotations are translated to the annotations of the ingress controller
+  (ingress-nginx by default).
+
+  The scenarios do not depend on the address of the client: they use the
+  ranges of every address, and 192.0.2.0/24 (TEST-NET-1), which is never
+  the address of a client. The ingress controller must observe the address
+  of the client, e.g. using a load balancer that preserves it, the PROXY
+  protocol (-proxy-protocol flag) or running the suite inside the cluster
+  (job command). The -source-address flag selects the address used to send
+  the requests.
+
+  Scenario: An Ingress should reject the requests from clients outside of the allowed ranges
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: allow-other-range
+        annotations:
+          conformance.ingress.k8s.io/allow-source-range: "192.0.2.0/24"
+      spec:
+        rules:
+          - host: "allow-other-range{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: allow-other-range
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://allow-other-range${hostSuffix}/"
+    Then the response status-code must be 403
+
+  Scenario: An Ingress should send the requests from clients in the allowed ranges to the backend service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: allow-any-range
+        annotations:
+          conformance.ingress.k8s.io/allow-source-range: "0.0.0.0/0,::/0"
+      spec:
+        rules:
+          - host: "allow-any-range{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: allow-any-range
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://allow-any-range${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "allow-any-range" service
+
+  Scenario: An Ingress should reject the requests from clients in the denied ranges
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: deny-any-range
+        annotations:
+          conformance.ingress.k8s.io/deny-source-range: "0.0.0.0/0,::/0"
+      spec:
+        rules:
+          - host: "deny-any-range{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: deny-any-range
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://deny-any-range${hostSuffix}/"
+    Then the response status-code must be 403
+
+  Scenario: An Ingress should send the requests from clients outside of the denied ranges to the backend service
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: deny-other-range
+        annotations:
+          conformance.ingress.k8s.io/deny-source-range: "192.0.2.0/24"
+      spec:
+        rules:
+          - host: "deny-other-range{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: deny-other-range
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://deny-other-range${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response must be served by the "deny-other-range" service
@@ -0,0 +1,102 @@
+@sig-network @timeouts @extended
+Feature: Timeouts
+  Ingress controllers may configure how long they wait for the backend
+  services with annotations of the Ingress. When a backend service does not
+  send its response within the read timeout, the request is answered with a
+  504 (Gateway Timeout) response once the timeout expires. Responses sent
+  within the timeout, and slow responses when no timeout is configured, must
+  be sent to the client.
+
+  Timeouts are not part of the Ingress spec. The delay query parameter of the
+  echoserver delays its responses. The send timeout only expires when a
+  backend service stops reading a request, and the connect timeout when the
+  connections to a backend cannot be established, so they are configured but
+  not exercised. The abstract annotations are translated to the annotations
+  of the ingress controller (ingress-nginx by default).
+
+  Scenario: An Ingress with a read timeout should answer 504 when the backend service does not respond in time
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: read-timeout-expired
+        annotations:
+          conformance.ingress.k8s.io/proxy-connect-timeout: "2"
+          conformance.ingress.k8s.io/proxy-read-timeout: "2"
+          conformance.ingress.k8s.io/proxy-send-timeout: "2"
+      spec:
+        rules:
+          - host: "read-timeout-expired{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: read-timeout-expired
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://read-timeout-expired${hostSuffix}/?delay=6s"
+    Then the response status-code must be 504
+    And the response must be received in at least 2 seconds
+    And the response must be received in less than 6 seconds
+
+  Scenario: An Ingress with a read timeout should send the responses received in time
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: read-timeout-not-expired
+        annotations:
+          conformance.ingress.k8s.io/proxy-read-timeout: "4"
+      spec:
+        rules:
+          - host: "read-timeout-not-expired{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: read-timeout-not-expired
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://read-timeout-not-expired${hostSuffix}/?delay=2s"
+    Then the response status-code must be 200
+    And the response must be served by the "read-timeout-not-expired" service
+    And the response must be received in at least 2 seconds
+
+  Scenario: An Ingress without timeouts should wait for slow backend services
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: default-timeouts
+      spec:
+        rules:
+          - host: "default-timeouts{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: default-timeouts
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+    When I send a "GET" request to "http://default-timeouts${hostSuffix}/?delay=5s"
+    Then the response status-code must be 200
+    And the response must be served by the "default-timeouts" service
+    And the response must be received in at least 5 seconds
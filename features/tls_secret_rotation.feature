@@ -0,0 +1,49 @@
+@sig-network @tls-secret-rotation @extended
+Feature: TLS secret rotation
+  The certificate of a TLS secret referenced by an Ingress can be replaced,
+  for example when it is renewed before expiring. The ingress controller
+  must present the new certificate, without changes to the Ingress and
+  without failing the requests sent while the certificate changes.
+
+  The new certificate is expected to be presented within the convergence
+  window set with the -max-wait flag, and the requests sent in the meantime
+  may fail up to the maximum error rate set with the -max-error-rate flag.
+
+  Background:
+    Given a new random namespace
+    Given a self-signed TLS secret named "tls-secret-rotation" for the "tls-secret-rotation${hostSuffix}" hostname
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: tls-secret-rotation
+      spec:
+        tls:
+          - hosts:
+              - tls-secret-rotation{{ .HostSuffix }}
+            secretName: tls-secret-rotation
+        rules:
+          - host: "tls-secret-rotation{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: tls-secret-rotation
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should present the new certificate of a renewed TLS secret
+    (the certificate of the tls-secret-rotation secret is replaced)
+
+    When I send a "GET" request to "https://tls-secret-rotation${hostSuffix}/"
+    Then the response status-code must be 200
+    And the response certificate must be the one of the "tls-secret-rotation" secret
+    Given I keep sending "GET" requests to "https://tls-secret-rotation${hostSuffix}/" in the background
+    When the TLS secret "tls-secret-rotation" is renewed for the "tls-secret-rotation${hostSuffix}" hostname
+    Then the requests to "https://tls-secret-rotation${hostSuffix}/" must eventually present the certificate of the "tls-secret-rotation" secret
+    And the requests sent in the background must fail at most at the maximum error rate
@@ -0,0 +1,59 @@
+@sig-network @trailers @extended
+Feature: Trailers
+  Ingress controllers must forward the trailers of chunked requests to the
+  backend services, and the trailers of chunked responses to the clients.
+  Trailers are sent after the body of a message, like the Grpc-Status of
+  gRPC responses, and they are lost when a controller buffers the body or
+  uses HTTP/1.1 connections to the backend services without the chunked
+  transfer encoding.
+
+  The /trailers path of the echoserver reads the request body and echoes
+  back the trailers received, and sends the trailers in the trailer query
+  parameter after the response body.
+
+  https://www.rfc-editor.org/rfc/rfc9110#section-6.5
+  https://www.rfc-editor.org/rfc/rfc9112#section-7.1.2
+
+  Background:
+    Given a new random namespace
+    Given an Ingress resource
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: trailers
+      spec:
+        rules:
+          - host: "trailers{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: trailers
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario: An Ingress should forward the trailers of a request to the backend service
+    Given the requests send the "X-Request-Checksum" trailer with "1234"
+    When I send a "POST" request to "http://trailers${hostSuffix}/trailers"
+    Then the response status-code must be 200
+    And the response must be served by the "trailers" service
+    And the request trailer "X-Request-Checksum" must be "1234"
+
+  Scenario: An Ingress should forward the trailers of a response to the client
+    When I send a "GET" request to "http://trailers${hostSuffix}/trailers?trailer=X-Response-Checksum:5678"
+    Then the response status-code must be 200
+    And the response must be served by the "trailers" service
+    And the response trailer "X-Response-Checksum" must be "5678"
+
+  Scenario: An Ingress should forward the trailers of a request and its response
+    Given the requests send the "X-Request-Checksum" trailer with "1234"
+    When I send a "POST" request to "http://trailers${hostSuffix}/trailers?trailer=X-Response-Checksum:5678"
+    Then the response status-code must be 200
+    And the response must be served by the "trailers" service
+    And the request trailer "X-Request-Checksum" must be "1234"
+    And the response trailer "X-Response-Checksum" must be "5678"
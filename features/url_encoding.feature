@@ -0,0 +1,83 @@
+@sig-network @url-encoding @extended
+Feature: URL encoding
+  The path and the query of a request must reach the backend service as
+  they were sent by the client. Percent-encoded characters (%20, %2F),
+  plus signs and query parameters must not be decoded or normalized in a
+  way that changes the resource requested from the backend service.
+
+  Fragments are never sent by clients, so they cannot affect routing.
+
+  https://www.rfc-editor.org/rfc/rfc3986#section-2
+  https://www.rfc-editor.org/rfc/rfc3986#section-3.5
+
+  Background:
+    Given an Ingress resource in a new random namespace
+      """
+      apiVersion: networking.k8s.io/v1
+      kind: Ingress
+      metadata:
+        name: url-encoding
+      spec:
+        rules:
+          - host: "url-encoding{{ .HostSuffix }}"
+            http:
+              paths:
+                - path: /foo
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: foo-prefix
+                      port:
+                        number: 8080
+
+                - path: /
+                  pathType: Prefix
+                  backend:
+                    service:
+                      name: root-prefix
+                      port:
+                        number: 8080
+      """
+    Then The Ingress status shows the IP address or FQDN where it is exposed
+
+  Scenario Outline: An Ingress should send percent-encoded paths to the backend service without decoding them
+    (<path> matches prefix /foo and reaches the backend unchanged)
+
+    When I send a "GET" request to "http://url-encoding${hostSuffix}<path>"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+    And the request raw path must be "<path>"
+
+    Examples:
+      | path               |
+      | /foo/hello%20world |
+      | /foo/a%2Fb         |
+      | /foo/a+b           |
+      | /foo/caf%C3%A9     |
+      | /foo/100%25        |
+
+  Scenario Outline: An Ingress should send query strings to the backend service without decoding them
+    (<path>?<query> matches prefix /foo and reaches the backend unchanged)
+
+    When I send a "GET" request to "http://url-encoding${hostSuffix}<path>?<query>"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+    And the request raw path must be "<path>"
+    And the request raw query must be "<query>"
+
+    Examples:
+      | path     | query                   |
+      | /foo     | q=hello%20world         |
+      | /foo     | q=a+b                   |
+      | /foo     | q=a%2Fb&r=%26           |
+      | /foo     | first=1&first=2&second= |
+      | /foo/bar | redirect=/admin         |
+
+  Scenario: An Ingress should not receive fragments of the request URL
+    (fragment #section is not sent)
+
+    When I send a "GET" request to "http://url-encoding${hostSuffix}/foo#section"
+    Then the response status-code must be 200
+    And the response must be served by the "foo-prefix" service
+    And the request raw path must be "/foo"
+    And the request raw query must be ""
@@ -48,6 +48,10 @@ import (
 
 var codeGenTemplate *template.Template
 
+// sharedSteps contains the expressions of the steps registered for all the features,
+// which do not require a definition in the go file of each feature
+var sharedSteps []*regexp.Regexp
+
 func main() {
 	var (
 		update            bool
@@ -55,6 +59,7 @@ func main() {
 		conformancePath   string
 		generatorTemplate string
 		testMainPath      string
+		sharedStepsPath   string
 
 		basePackage string
 	)
@@ -63,6 +68,7 @@ func main() {
 	flag.StringVar(&conformancePath, "conformance-path", "test/conformance", "path to conformance test package location")
 	flag.StringVar(&generatorTemplate, "code-generator-template", "hack/codegen.tmpl", "path to the go template for code generation")
 	flag.StringVar(&testMainPath, "test-main", "conformance_test.go", "path to the TestMain go file")
+	flag.StringVar(&sharedStepsPath, "shared-steps", "test/state", "path to the package registering the steps shared by all the features")
 	flag.StringVar(&basePackage, "base-package", "sigs.k8s.io/ingress-controller-conformance", "base go package")
 
 	flag.Parse()
@@ -85,6 +91,11 @@ func main() {
 		log.Fatalf("Unexpected error parsing template: %v", err)
 	}
 
+	sharedSteps, err = extractSharedSteps(sharedStepsPath)
+	if err != nil {
+		log.Fatalf("Unexpected error reading shared steps from %v: %v", sharedStepsPath, err)
+	}
+
 	// 3. if features is a directory, iterate and search for files with extension .feature
 	if len(features) == 1 && files.IsDir(features[0]) {
 		root := filepath.Dir(features[0])
@@ -367,6 +378,69 @@ func extractFuncs(filePath string) ([]Function, error) {
 	return funcs, nil
 }
 
+// extractSharedSteps reads the go source code of a package and returns the
+// expressions of the steps it registers with ctx.Step
+func extractSharedSteps(dir string) ([]*regexp.Regexp, error) {
+	fset := token.NewFileSet()
+
+	pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var exprs []*regexp.Regexp
+	var exprErr error
+	for _, pkg := range pkgs {
+		ast.Inspect(pkg, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Step" {
+				return true
+			}
+
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+
+			expr, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				exprErr = err
+				return false
+			}
+
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				exprErr = err
+				return false
+			}
+
+			exprs = append(exprs, re)
+			return true
+		})
+	}
+
+	if exprErr != nil {
+		return nil, exprErr
+	}
+
+	return exprs, nil
+}
+
+func isSharedStep(text string) bool {
+	for _, re := range sharedSteps {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func updateGoTestFile(filePath string, newFuncs []Function) error {
 	fileSet := token.NewFileSet()
 
@@ -777,6 +851,9 @@ func parseSteps(steps []*messages.Pickle_PickleStep, funcDefs []Function) []Func
 
 	for _, step := range steps {
 		text := step.Text
+		if isSharedStep(text) {
+			continue
+		}
 
 		expr := snippetExprCleanup.ReplaceAllString(text, "\\$1")
 		expr = snippetNumbers.ReplaceAllString(expr, "(\\d+)")
@@ -0,0 +1,51 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// authHandler is a tiny external authentication service, used by the ingress controllers that
+// authenticate the requests with a subrequest (auth request). The decision is taken from the
+// X-Echo-Auth header of the original request, forwarded by the ingress controller:
+// allow approves the request with a 200 response and the X-Auth-User header, the user in the
+// X-Echo-Auth-User header (default conformance); deny rejects it with 403 (Forbidden), and
+// requests without the header are rejected with 401 (Unauthorized).
+func authHandler(w http.ResponseWriter, r *http.Request) {
+	decision := r.Header.Get("X-Echo-Auth")
+	fmt.Printf("Authenticating request made to %s to client (%s): %q\n", r.RequestURI, r.RemoteAddr, decision)
+
+	w.Header().Set("X-Echo-Service", context.Service)
+
+	switch decision {
+	case "allow":
+		user := r.Header.Get("X-Echo-Auth-User")
+		if user == "" {
+			user = "conformance"
+		}
+
+		w.Header().Set("X-Auth-User", user)
+		w.WriteHeader(http.StatusOK)
+	case "":
+		w.Header().Set("WWW-Authenticate", `Bearer realm="conformance"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	default:
+		w.WriteHeader(http.StatusForbidden)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	gocontext "context"
+	"net/http"
+	"sync/atomic"
+)
+
+// ConnectionAssertions contains the counters of the connection of the request, to tell
+// if the ingress controller reuses its connections to the backend (keep-alive)
+type ConnectionAssertions struct {
+	// ID sequence number of the connection, the first connection accepted by the echoserver is 1
+	ID uint64 `json:"id"`
+	// Requests number of requests received in the connection, including this one
+	Requests uint64 `json:"requests"`
+	// Accepted number of connections accepted by the echoserver in all its listeners
+	Accepted uint64 `json:"accepted"`
+}
+
+var acceptedConnections atomic.Uint64
+
+// connectionCounters counts the requests of a connection
+type connectionCounters struct {
+	id       uint64
+	requests atomic.Uint64
+}
+
+type connectionCountersContextKey struct{}
+
+// countConnection counts a new connection and makes its counters available to the handlers of its requests
+func countConnection(ctx gocontext.Context) gocontext.Context {
+	counters := &connectionCounters{id: acceptedConnections.Add(1)}
+	return gocontext.WithValue(ctx, connectionCountersContextKey{}, counters)
+}
+
+// connectionToAssertions counts the request in its connection and returns the counters of the connection
+func connectionToAssertions(r *http.Request) *ConnectionAssertions {
+	counters, ok := r.Context().Value(connectionCountersContextKey{}).(*connectionCounters)
+	if !ok {
+		return nil
+	}
+
+	return &ConnectionAssertions{
+		ID:       counters.id,
+		Requests: counters.requests.Add(1),
+		Accepted: acceptedConnections.Load(),
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// contentPattern is repeated to fill the content served by contentHandler, so the bytes of a range can be predicted
+const contentPattern = "0123456789abcdef"
+
+// contentModTime is the last modification time of the content served by contentHandler
+var contentModTime = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// contentHandler serves a static content of the size in the size query parameter (default 1KB), filled with
+// contentPattern, with ETag and Last-Modified headers. It answers range and conditional requests with 206,
+// 304 and 416 status codes. The service and the pod of the echoserver are sent in response headers.
+func contentHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("Serving content for request made to %s to client (%s)\n", r.RequestURI, r.RemoteAddr)
+
+	size := 1 << 10
+	if value := r.URL.Query().Get("size"); value != "" {
+		n, err := parseSize(value)
+		if err != nil {
+			processError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		size = n
+	}
+
+	content := bytes.Repeat([]byte(contentPattern), size/len(contentPattern)+1)[:size]
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("ETag", fmt.Sprintf(`"content-%d"`, size))
+	w.Header().Set("X-Echo-Service", context.Service)
+	w.Header().Set("X-Echo-Pod", context.Pod)
+
+	http.ServeContent(w, r, "", contentModTime, bytes.NewReader(content))
+}
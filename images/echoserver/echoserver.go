@@ -17,12 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"regexp"
@@ -38,9 +42,30 @@ type RequestAssertions struct {
 	Proto   string              `json:"proto"`
 	Headers map[string][]string `json:"headers"`
 
+	// Protocol of the listener that received the request: http, https, h2c or grpc
+	Protocol string `json:"protocol"`
+
+	// RequestLine, RawPath and RawQuery are the request as received, before it is decoded or normalized
+	RequestLine string `json:"requestLine"`
+	RawPath     string `json:"rawPath"`
+	RawQuery    string `json:"rawQuery"`
+
+	// Nonce is a random value unique to each response, so a response served from a cache can be told apart
+	Nonce string `json:"nonce"`
+
 	Context `json:",inline"`
 
 	TLS *TLSAssertions `json:"tls,omitempty"`
+
+	ProxyProtocol *ProxyProtocolAssertions `json:"proxyProtocol,omitempty"`
+
+	Connection *ConnectionAssertions `json:"connection,omitempty"`
+
+	// Trailers of the request, only read by the /trailers endpoint
+	Trailers map[string][]string `json:"trailers,omitempty"`
+
+	// BodySize is the size of the request body, read by the echo handler
+	BodySize int64 `json:"bodySize"`
 }
 
 // TLSAssertions contains information about the TLS connection.
@@ -50,6 +75,8 @@ type TLSAssertions struct {
 	ServerName         string   `json:"serverName"`
 	NegotiatedProtocol string   `json:"negotiatedProtocol,omitempty"`
 	CipherSuite        string   `json:"cipherSuite"`
+
+	ClientCertificate *ClientCertificateAssertions `json:"clientCertificate,omitempty"`
 }
 
 type preserveSlashes struct {
@@ -67,6 +94,9 @@ type Context struct {
 	Ingress   string `json:"ingress"`
 	Service   string `json:"service"`
 	Pod       string `json:"pod"`
+	PodIP     string `json:"podIP,omitempty"`
+	Node      string `json:"node,omitempty"`
+	Zone      string `json:"zone,omitempty"`
 }
 
 var context Context
@@ -82,16 +112,34 @@ func main() {
 		httpsPort = "8443"
 	}
 
+	h2cPort := os.Getenv("H2C_PORT")
+	if h2cPort == "" {
+		h2cPort = "3001"
+	}
+
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "50051"
+	}
+
 	context = Context{
 		Namespace: os.Getenv("NAMESPACE"),
 		Ingress:   os.Getenv("INGRESS_NAME"),
 		Service:   os.Getenv("SERVICE_NAME"),
 		Pod:       os.Getenv("POD_NAME"),
+		PodIP:     os.Getenv("POD_IP"),
+		Node:      os.Getenv("NODE_NAME"),
+		Zone:      os.Getenv("ZONE"),
 	}
 
 	httpMux := http.NewServeMux()
 	httpMux.HandleFunc("/health", healthHandler)
 	httpMux.HandleFunc("/status/", statusHandler)
+	httpMux.HandleFunc("/content", contentHandler)
+	httpMux.HandleFunc("/trailers", trailersHandler)
+	httpMux.HandleFunc("/auth", authHandler)
+	httpMux.HandleFunc("/sse", sseHandler)
+	httpMux.HandleFunc("/ws", wsHandler)
 	httpMux.HandleFunc("/", echoHandler)
 	httpHandler := &preserveSlashes{httpMux}
 
@@ -99,22 +147,47 @@ func main() {
 
 	go func() {
 		fmt.Printf("Starting server, listening on port %s (http)\n", httpPort)
-		err := http.ListenAndServe(fmt.Sprintf(":%s", httpPort), httpHandler)
+		srv := &http.Server{
+			Handler:     withProtocol("http", httpHandler),
+			ConnContext: saveConnInContext,
+		}
+
+		err := listenAndServe(srv, fmt.Sprintf(":%s", httpPort))
 		if err != nil {
 			errchan <- err
 		}
 	}()
 
-	// Enable HTTPS if certificate and private key are given.
-	if os.Getenv("TLS_SERVER_CERT") != "" && os.Getenv("TLS_SERVER_PRIVKEY") != "" {
-		go func() {
-			fmt.Printf("Starting server, listening on port %s (https)\n", httpsPort)
-			err := listenAndServeTLS(fmt.Sprintf(":%s", httpsPort), os.Getenv("TLS_SERVER_CERT"), os.Getenv("TLS_SERVER_PRIVKEY"), os.Getenv("TLS_CLIENT_CACERTS"), httpHandler)
-			if err != nil {
-				errchan <- err
-			}
-		}()
-	}
+	go func() {
+		fmt.Printf("Starting server, listening on port %s (h2c)\n", h2cPort)
+		err := listenAndServe(newH2CServer(withProtocol("h2c", httpHandler)), fmt.Sprintf(":%s", h2cPort))
+		if err != nil {
+			errchan <- err
+		}
+	}()
+
+	go func() {
+		fmt.Printf("Starting server, listening on port %s (grpc)\n", grpcPort)
+		err := listenAndServe(newH2CServer(withProtocol("grpc", http.HandlerFunc(grpcHandler))), fmt.Sprintf(":%s", grpcPort))
+		if err != nil {
+			errchan <- err
+		}
+	}()
+
+	// Serve HTTPS with the certificate and private key given, or with a self-signed certificate.
+	go func() {
+		certificate, err := loadServerCertificate(os.Getenv("TLS_SERVER_CERT"), os.Getenv("TLS_SERVER_PRIVKEY"))
+		if err != nil {
+			errchan <- err
+			return
+		}
+
+		fmt.Printf("Starting server, listening on port %s (https)\n", httpsPort)
+		err = listenAndServeTLS(fmt.Sprintf(":%s", httpsPort), certificate, os.Getenv("TLS_CLIENT_CACERTS"), withProtocol("https", httpHandler))
+		if err != nil {
+			errchan <- err
+		}
+	}()
 
 	select {
 	case err := <-errchan:
@@ -141,28 +214,97 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 
 func echoHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("Echoing back request made to %s to client (%s)\n", r.RequestURI, r.RemoteAddr)
-	requestAssertions := RequestAssertions{
-		r.RequestURI,
-		r.Host,
-		r.Method,
-		r.Proto,
-		r.Header,
 
-		context,
+	shaping, err := parseResponseShaping(r)
+	if err != nil {
+		processError(w, err, http.StatusBadRequest)
+		return
+	}
 
-		tlsStateToAssertions(r.TLS),
+	// the body is read before the response is sent, so the ingress controller can send all of it
+	bodySize, err := io.Copy(ioutil.Discard, r.Body)
+	if err != nil {
+		processError(w, err, http.StatusBadRequest)
+		return
 	}
 
+	requestAssertions := newRequestAssertions(r)
+	requestAssertions.BodySize = bodySize
+
 	js, err := json.MarshalIndent(requestAssertions, "", " ")
 	if err != nil {
 		processError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	writeEchoResponseHeaders(w, r.Header)
+	if !shaping.wait(r) {
+		return
+	}
+
+	body, err := shaping.encode(w, shaping.pad(js))
+	if err != nil {
+		processError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeEchoResponseHeaders(w, shaping.headers)
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.Write(js)
+	w.WriteHeader(shaping.status)
+	w.Write(body)
+}
+
+// rawPath returns the path of a request-target without decoding it, also in absolute-form (http://host/path)
+func rawPath(requestURI string) string {
+	path, _, _ := strings.Cut(requestURI, "?")
+
+	if _, rest, ok := strings.Cut(path, "://"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 {
+			return rest[i:]
+		}
+
+		return "/"
+	}
+
+	return path
+}
+
+// newRequestAssertions returns the information about the request echoed back to the client
+func newRequestAssertions(r *http.Request) RequestAssertions {
+	return RequestAssertions{
+		r.RequestURI,
+		r.Host,
+		r.Method,
+		r.Proto,
+		r.Header,
+
+		requestProtocol(r),
+
+		fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto),
+		rawPath(r.RequestURI),
+		r.URL.RawQuery,
+
+		newNonce(),
+
+		context,
+
+		tlsStateToAssertions(r.TLS),
+
+		proxyProtocolToAssertions(r),
+
+		connectionToAssertions(r),
+
+		nil,
+		0,
+	}
+}
+
+// newNonce returns a random value for the Nonce of an echo response
+func newNonce() string {
+	nonce := make([]byte, 8)
+	_, _ = rand.Read(nonce)
+
+	return hex.EncodeToString(nonce)
 }
 
 func writeEchoResponseHeaders(w http.ResponseWriter, headers http.Header) {
@@ -199,8 +341,12 @@ func processError(w http.ResponseWriter, err error, code int) {
 	w.Write(body)
 }
 
-func listenAndServeTLS(addr string, serverCert string, serverPrivKey string, clientCA string, handler http.Handler) error {
-	var config tls.Config
+func listenAndServeTLS(addr string, certificate tls.Certificate, clientCA string, handler http.Handler) error {
+	config := tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		// Request the client certificate to report its identity, even when it cannot be verified.
+		ClientAuth: tls.RequestClientCert,
+	}
 
 	// Optionally enable client certificate validation when client CA certificates are given.
 	if clientCA != "" {
@@ -220,12 +366,27 @@ func listenAndServeTLS(addr string, serverCert string, serverPrivKey string, cli
 	}
 
 	srv := &http.Server{
-		Addr:      addr,
-		Handler:   handler,
-		TLSConfig: &config,
+		Handler:     handler,
+		TLSConfig:   &config,
+		ConnContext: saveConnInContext,
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return srv.ServeTLS(&proxyProtocolListener{listener}, "", "")
+}
+
+// listenAndServe serves plain HTTP, accepting connections starting with a PROXY protocol header
+func listenAndServe(srv *http.Server, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
 	}
 
-	return srv.ListenAndServeTLS(serverCert, serverPrivKey)
+	return srv.Serve(&proxyProtocolListener{listener})
 }
 
 func tlsStateToAssertions(connectionState *tls.ConnectionState) *TLSAssertions {
@@ -246,6 +407,7 @@ func tlsStateToAssertions(connectionState *tls.ConnectionState) *TLSAssertions {
 		state.NegotiatedProtocol = connectionState.NegotiatedProtocol
 		state.ServerName = connectionState.ServerName
 		state.CipherSuite = tls.CipherSuiteName(connectionState.CipherSuite)
+		state.ClientCertificate = clientCertificateToAssertions(connectionState)
 
 		// Convert peer certificates to PEM blocks.
 		for _, c := range connectionState.PeerCertificates {
@@ -1,3 +1,3 @@
 module sigs.k8s.io/ingress-controller-conformance/images/echoserver
 
-go 1.18
+go 1.24
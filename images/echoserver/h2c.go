@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	gocontext "context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GRPCEchoMethod path of the Echo method of the ingressconformance.echo.v1.Echo service (see echo.proto)
+const GRPCEchoMethod = "/ingressconformance.echo.v1.Echo/Echo"
+
+// gRPC status codes
+// https://github.com/grpc/grpc/blob/master/doc/statuscodes.md
+const (
+	grpcOK            = 0
+	grpcInvalidArg    = 3
+	grpcUnimplemented = 12
+	grpcInternal      = 13
+)
+
+type protocolContextKey struct{}
+
+// withProtocol makes the protocol of the listener available to the handlers of its requests
+func withProtocol(protocol string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(gocontext.WithValue(r.Context(), protocolContextKey{}, protocol)))
+	})
+}
+
+// requestProtocol returns the protocol of the listener that received the request
+func requestProtocol(r *http.Request) string {
+	protocol, _ := r.Context().Value(protocolContextKey{}).(string)
+	return protocol
+}
+
+// newH2CServer returns a server of HTTP/2 requests without TLS (h2c), with prior knowledge
+func newH2CServer(handler http.Handler) *http.Server {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	return &http.Server{
+		Handler:     handler,
+		ConnContext: saveConnInContext,
+		Protocols:   &protocols,
+	}
+}
+
+// grpcHandler serves the unary Echo method. The EchoResponse contains the information
+// about the request, in the same JSON document returned by the other listeners.
+func grpcHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("Echoing back gRPC request made to %s to client (%s)\n", r.URL.Path, r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/grpc")
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		writeGRPCStatus(w, grpcInvalidArg, fmt.Sprintf("unexpected content type %q", r.Header.Get("Content-Type")))
+		return
+	}
+
+	if r.URL.Path != GRPCEchoMethod {
+		writeGRPCStatus(w, grpcUnimplemented, fmt.Sprintf("unknown method %v", r.URL.Path))
+		return
+	}
+
+	// the EchoRequest is empty, any field sent is ignored
+	if _, err := io.Copy(io.Discard, r.Body); err != nil {
+		writeGRPCStatus(w, grpcInternal, err.Error())
+		return
+	}
+
+	js, err := json.Marshal(newRequestAssertions(r))
+	if err != nil {
+		writeGRPCStatus(w, grpcInternal, err.Error())
+		return
+	}
+
+	// EchoResponse with the JSON document in the field 1 (length-delimited)
+	message := binary.AppendUvarint([]byte{1<<3 | 2}, uint64(len(js)))
+	message = append(message, js...)
+
+	// length-prefixed message, not compressed
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	frame = append(frame, message...)
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(frame)
+
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(grpcOK))
+}
+
+// writeGRPCStatus sends a response without messages, with the status in its headers (Trailers-Only)
+func writeGRPCStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", message)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ProxyProtocolAssertions contains information about the PROXY protocol header received in the connection.
+type ProxyProtocolAssertions struct {
+	Version            int    `json:"version"`
+	SourceAddress      string `json:"sourceAddress"`
+	DestinationAddress string `json:"destinationAddress"`
+}
+
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+type connContextKey struct{}
+
+// proxyProtocolListener accepts connections that may start with a PROXY protocol header
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyProtocolConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolConn detects and strips a PROXY protocol header (version 1 or 2)
+// before the first read, keeping the information it contains.
+type proxyProtocolConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+	once   sync.Once
+	header *ProxyProtocolAssertions
+	err    error
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.once.Do(func() {
+		c.header, c.err = readProxyProtocolHeader(c.reader)
+	})
+
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// readProxyProtocolHeader reads the PROXY protocol header, if present
+func readProxyProtocolHeader(reader *bufio.Reader) (*ProxyProtocolAssertions, error) {
+	prefix, _ := reader.Peek(len(proxyProtocolV2Signature))
+
+	switch {
+	case bytes.HasPrefix(prefix, []byte("PROXY ")):
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol v1 header: %w", err)
+		}
+
+		// PROXY TCP4|TCP6|UNKNOWN source destination source-port destination-port
+		fields := strings.Fields(line)
+		header := &ProxyProtocolAssertions{Version: 1}
+		if len(fields) == 6 {
+			header.SourceAddress = net.JoinHostPort(fields[2], fields[4])
+			header.DestinationAddress = net.JoinHostPort(fields[3], fields[5])
+		}
+
+		return header, nil
+	case bytes.Equal(prefix, proxyProtocolV2Signature):
+		fixed := make([]byte, len(proxyProtocolV2Signature)+4)
+		if _, err := io.ReadFull(reader, fixed); err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol v2 header: %w", err)
+		}
+
+		family := fixed[len(proxyProtocolV2Signature)+1]
+		length := binary.BigEndian.Uint16(fixed[len(proxyProtocolV2Signature)+2:])
+
+		addresses := make([]byte, length)
+		if _, err := io.ReadFull(reader, addresses); err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol v2 addresses: %w", err)
+		}
+
+		header := &ProxyProtocolAssertions{Version: 2}
+
+		ipLength := 0
+		switch family >> 4 {
+		case 0x1:
+			ipLength = net.IPv4len
+		case 0x2:
+			ipLength = net.IPv6len
+		}
+
+		if ipLength != 0 && len(addresses) >= 2*ipLength+4 {
+			sourcePort := binary.BigEndian.Uint16(addresses[2*ipLength:])
+			destinationPort := binary.BigEndian.Uint16(addresses[2*ipLength+2:])
+
+			header.SourceAddress = net.JoinHostPort(net.IP(addresses[:ipLength]).String(), fmt.Sprint(sourcePort))
+			header.DestinationAddress = net.JoinHostPort(net.IP(addresses[ipLength:2*ipLength]).String(), fmt.Sprint(destinationPort))
+		}
+
+		return header, nil
+	}
+
+	return nil, nil
+}
+
+// saveConnInContext makes the connection, and its counters, available to the handlers of its requests
+func saveConnInContext(ctx gocontext.Context, conn net.Conn) gocontext.Context {
+	return countConnection(gocontext.WithValue(ctx, connContextKey{}, conn))
+}
+
+// proxyProtocolToAssertions returns the PROXY protocol header received in the connection of the request
+func proxyProtocolToAssertions(r *http.Request) *ProxyProtocolAssertions {
+	conn, ok := r.Context().Value(connContextKey{}).(*proxyProtocolConn)
+	if !ok {
+		return nil
+	}
+
+	return conn.header
+}
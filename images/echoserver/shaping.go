@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// responseShaping contains the changes to the echo response requested by the client, using the
+// status, delay, size, encoding and set-header query parameters or the X-Echo-Status, X-Echo-Delay,
+// X-Echo-Size, X-Echo-Encoding and X-Echo-Set-Header headers
+type responseShaping struct {
+	// status code of the response, 200 when not set
+	status int
+	// delay before the response is sent
+	delay time.Duration
+	// size minimum size of the response body in bytes
+	size int
+	// encoding content encoding of the response body, when accepted by the client
+	encoding string
+	// headers values of X-Echo-Set-Header to add to the response
+	headers http.Header
+}
+
+// maxShapingSize limits the size of the response bodies requested by the clients
+const maxShapingSize = 100 << 20
+
+func parseResponseShaping(r *http.Request) (*responseShaping, error) {
+	shaping := &responseShaping{
+		status: http.StatusOK,
+		headers: http.Header{
+			"X-Echo-Set-Header": append(r.Header.Values("X-Echo-Set-Header"), r.URL.Query()["set-header"]...),
+		},
+	}
+
+	if value := shapingParameter(r, "status"); value != "" {
+		status, err := strconv.Atoi(value)
+		if err != nil || status < 200 || status > 599 {
+			return nil, fmt.Errorf("invalid status %q (valid values are 200 to 599)", value)
+		}
+
+		shaping.status = status
+	}
+
+	if value := shapingParameter(r, "delay"); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("invalid delay %q (e.g. 500ms or 5s)", value)
+		}
+
+		shaping.delay = delay
+	}
+
+	if value := shapingParameter(r, "size"); value != "" {
+		size, err := parseSize(value)
+		if err != nil {
+			return nil, err
+		}
+
+		shaping.size = size
+	}
+
+	if value := shapingParameter(r, "encoding"); value != "" {
+		if value != "gzip" {
+			return nil, fmt.Errorf("invalid encoding %q (valid values are gzip)", value)
+		}
+
+		// the client may not accept it, the response is sent uncompressed then
+		if acceptsEncoding(r, value) {
+			shaping.encoding = value
+		}
+	}
+
+	return shaping, nil
+}
+
+// acceptsEncoding returns true if the Accept-Encoding header of the request contains the encoding,
+// without a zero quality value
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, v := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(v), ";")
+			if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+				continue
+			}
+
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if quality, err := strconv.ParseFloat(q, 64); err == nil && quality == 0 {
+					return false
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// encode compresses the body with the content encoding and sets its headers, if the client accepts it
+func (s *responseShaping) encode(w http.ResponseWriter, body []byte) ([]byte, error) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if s.encoding == "" {
+		return body, nil
+	}
+
+	var out bytes.Buffer
+	gz := gzip.NewWriter(&out)
+	if _, err := gz.Write(body); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Encoding", s.encoding)
+	return out.Bytes(), nil
+}
+
+// shapingParameter returns the value of a query parameter, or of the X-Echo- header with the same name
+func shapingParameter(r *http.Request, name string) string {
+	if value := r.URL.Query().Get(name); value != "" {
+		return value
+	}
+
+	return r.Header.Get("X-Echo-" + name)
+}
+
+// parseSize parses a size in bytes with an optional B, KB or MB suffix (e.g. 512KB or 1MB)
+func parseSize(value string) (int, error) {
+	number, multiplier := strings.ToUpper(value), 1
+
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"B", 1}} {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, multiplier = strings.TrimSuffix(number, unit.suffix), unit.multiplier
+			break
+		}
+	}
+
+	size, err := strconv.Atoi(number)
+	if err != nil || size < 0 || size*multiplier > maxShapingSize {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512KB or 1MB, up to 100MB)", value)
+	}
+
+	return size * multiplier, nil
+}
+
+// wait waits for the delay, or until the client goes away. It returns false in the latter case.
+func (s *responseShaping) wait(r *http.Request) bool {
+	if s.delay == 0 {
+		return true
+	}
+
+	timer := time.NewTimer(s.delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// pad pads a JSON body with trailing spaces up to the requested size, so it can still be decoded
+func (s *responseShaping) pad(body []byte) []byte {
+	if len(body) >= s.size {
+		return body
+	}
+
+	return append(body, bytes.Repeat([]byte(" "), s.size-len(body))...)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxSSEEvents limits the number of events requested by the clients
+const maxSSEEvents = 1000
+
+// sseHandler sends a Server-Sent Events stream. The first event, named request, contains the
+// information about the request. Then it sends the number of events in the events query parameter
+// (default 5), named tick, separated by the interval in the interval query parameter (default 1s).
+func sseHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("Streaming events for request made to %s to client (%s)\n", r.RequestURI, r.RemoteAddr)
+
+	events := 5
+	if value := r.URL.Query().Get("events"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxSSEEvents {
+			processError(w, fmt.Errorf("invalid events %q (valid values are 0 to %v)", value, maxSSEEvents), http.StatusBadRequest)
+			return
+		}
+
+		events = n
+	}
+
+	interval := time.Second
+	if value := r.URL.Query().Get("interval"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			processError(w, fmt.Errorf("invalid interval %q (e.g. 100ms or 1s)", value), http.StatusBadRequest)
+			return
+		}
+
+		interval = d
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		processError(w, fmt.Errorf("streaming is not supported by the connection"), http.StatusInternalServerError)
+		return
+	}
+
+	js, err := json.Marshal(newRequestAssertions(r))
+	if err != nil {
+		processError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "event: request\ndata: %s\n\n", js)
+	flusher.Flush()
+
+	ticker := time.NewTicker(max(interval, time.Millisecond))
+	defer ticker.Stop()
+
+	for i := 1; i <= events; i++ {
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+
+		fmt.Fprintf(w, "id: %d\nevent: tick\ndata: %d\n\n", i, i)
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ClientCertificateAssertions contains the identity of the client certificate presented in the TLS handshake
+type ClientCertificateAssertions struct {
+	Subject    string   `json:"subject"`
+	CommonName string   `json:"commonName"`
+	DNSNames   []string `json:"dnsNames,omitempty"`
+	Issuer     string   `json:"issuer"`
+	// Verified is true when the certificate was verified with the client CA certificates (TLS_CLIENT_CACERTS)
+	Verified bool `json:"verified"`
+}
+
+// loadServerCertificate returns the certificate in the certificate and private key files,
+// or a self-signed certificate for the service of the echoserver when they are not given
+func loadServerCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	if certFile != "" && keyFile != "" {
+		return tls.LoadX509KeyPair(certFile, keyFile)
+	}
+
+	hosts := []string{"localhost"}
+	if context.Service != "" {
+		hosts = append(hosts, context.Service)
+		if context.Namespace != "" {
+			hosts = append(hosts,
+				fmt.Sprintf("%s.%s", context.Service, context.Namespace),
+				fmt.Sprintf("%s.%s.svc", context.Service, context.Namespace))
+		}
+	}
+
+	return selfSignedCertificate(hosts)
+}
+
+// selfSignedCertificate generates a certificate valid for the hosts, signed by its own key
+func selfSignedCertificate(hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: hosts[len(hosts)-1], Organization: []string{"ingress-conformance-echo"}},
+		DNSNames:              hosts,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// clientCertificateToAssertions returns the identity of the client certificate of the connection, if any
+func clientCertificateToAssertions(connectionState *tls.ConnectionState) *ClientCertificateAssertions {
+	if len(connectionState.PeerCertificates) == 0 {
+		return nil
+	}
+
+	certificate := connectionState.PeerCertificates[0]
+
+	return &ClientCertificateAssertions{
+		Subject:    certificate.Subject.String(),
+		CommonName: certificate.Subject.CommonName,
+		DNSNames:   certificate.DNSNames,
+		Issuer:     certificate.Issuer.String(),
+		Verified:   len(connectionState.VerifiedChains) != 0,
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// maxTrailersRequestBody limits the size of the request bodies read by trailersHandler
+const maxTrailersRequestBody = 1 << 20
+
+// trailersHandler reads the request body, to receive the trailers of chunked requests, and echoes back
+// the request with its trailers. The response is sent with the trailers in the trailer query parameter
+// or the X-Echo-Trailer header, a comma separated list of name:value pairs (e.g. X-Checksum:1234).
+func trailersHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("Echoing back request with trailers made to %s to client (%s)\n", r.RequestURI, r.RemoteAddr)
+
+	trailers, err := parseTrailers(shapingParameter(r, "trailer"))
+	if err != nil {
+		processError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	// the trailers of the request are received after its body
+	_, err = io.Copy(ioutil.Discard, io.LimitReader(r.Body, maxTrailersRequestBody))
+	if err != nil {
+		processError(w, err, http.StatusBadRequest)
+		return
+	}
+
+	requestAssertions := newRequestAssertions(r)
+	requestAssertions.Trailers = r.Trailer
+
+	js, err := json.MarshalIndent(requestAssertions, "", " ")
+	if err != nil {
+		processError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	// declare the trailers, so they are sent in a chunked response
+	names := make([]string, 0, len(trailers))
+	for name := range trailers {
+		names = append(names, name)
+	}
+
+	if len(names) != 0 {
+		w.Header().Set("Trailer", strings.Join(names, ", "))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	w.Write(js)
+
+	for name, values := range trailers {
+		w.Header()[name] = values
+	}
+}
+
+// parseTrailers parses a comma separated list of name:value pairs
+func parseTrailers(value string) (http.Header, error) {
+	trailers := http.Header{}
+	if value == "" {
+		return trailers, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid trailer %q (e.g. X-Checksum:1234)", pair)
+		}
+
+		trailers.Add(name, strings.TrimSpace(value))
+	}
+
+	return trailers, nil
+}
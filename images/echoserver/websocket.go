@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WebSocket opcodes
+// https://www.rfc-editor.org/rfc/rfc6455#section-5.2
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// wsGUID is concatenated to the key of the client to compute the accept header of the handshake
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxWSPayload limits the size of the frames received from the clients
+const maxWSPayload = 1 << 20
+
+// wsHandler accepts WebSocket connections. The first message sent to the client is a text message with the
+// information about the request, then the text and binary messages received are echoed back until the
+// client closes the connection.
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Printf("Accepting WebSocket request made to %s from client (%s)\n", r.RequestURI, r.RemoteAddr)
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") || key == "" {
+		processError(w, fmt.Errorf("expected a WebSocket handshake"), http.StatusBadRequest)
+		return
+	}
+
+	js, err := json.Marshal(newRequestAssertions(r))
+	if err != nil {
+		processError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		processError(w, fmt.Errorf("WebSocket is not supported by the connection"), http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		processError(w, err, http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	accept := sha1.Sum([]byte(key + wsGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+		base64.StdEncoding.EncodeToString(accept[:]))
+
+	if err := writeWSFrame(rw.Writer, wsText, js); err != nil {
+		return
+	}
+
+	for {
+		opcode, payload, err := readWSMessage(rw.Reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				fmt.Printf("Closing WebSocket connection from client (%s): %v\n", r.RemoteAddr, err)
+			}
+
+			return
+		}
+
+		switch opcode {
+		case wsText, wsBinary:
+			err = writeWSFrame(rw.Writer, opcode, payload)
+		case wsPing:
+			err = writeWSFrame(rw.Writer, wsPong, payload)
+		case wsClose:
+			// echo the status code of the client, if any
+			_ = writeWSFrame(rw.Writer, wsClose, payload)
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// headerContainsToken returns true if one of the comma separated values of the header is the token
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// readWSMessage reads the frames of a message, the control frames are returned as they are received
+func readWSMessage(r *bufio.Reader) (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+
+	for {
+		fin, frameOpcode, payload, err := readWSFrame(r)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if frameOpcode >= wsClose {
+			return frameOpcode, payload, nil
+		}
+
+		if frameOpcode != wsContinuation {
+			opcode = frameOpcode
+		}
+
+		message = append(message, payload...)
+		if len(message) > maxWSPayload {
+			return 0, nil, fmt.Errorf("message larger than %v bytes", maxWSPayload)
+		}
+
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+// readWSFrame reads a frame sent by a client, which must be masked
+func readWSFrame(r *bufio.Reader) (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, fmt.Errorf("unmasked frame received from the client")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(r, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(r, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxWSPayload {
+		return false, 0, nil, fmt.Errorf("frame larger than %v bytes", maxWSPayload)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeWSFrame writes an unmasked frame with the whole payload
+func writeWSFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+
+	switch {
+	case len(payload) < 126:
+		header = append(header, byte(len(payload)))
+	case len(payload) <= 0xffff:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
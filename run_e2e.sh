@@ -25,10 +25,16 @@ trap saveResults TERM
 
 set -x
 /ingress-controller-conformance \
-    --format=cucumber \
+    --format="${FORMAT}" \
+    --profile="${PROFILE}" \
+    --controller-selector="${CONTROLLER_SELECTOR}" \
     --ingress-class="${INGRESS_CLASS}" \
     --output-directory="${RESULTS_DIR}" \
+    --output="${OUTPUT}" \
     --wait-time-for-ingress-status="${WAIT_FOR_STATUS_TIMEOUT}" \
+    --convergence-successes="${CONVERGENCE_SUCCESSES}" \
+    --max-wait="${CONVERGENCE_MAX_WAIT}" \
+    --retry-delay="${CONVERGENCE_RETRY_DELAY}" \
     --test.timeout="${TEST_TIMEOUT}"
 ret=$?
 #set -x
@@ -0,0 +1,247 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/diff"
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes/templates"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+func init() {
+	register(&Command{
+		Name:        "apply",
+		Description: "Apply the fixtures of the features to the cluster, or dry-run them, printing the differences with the live objects",
+		Run:         runApply,
+	})
+}
+
+// FixturesName is the name label of the namespaces of the applied fixtures
+const FixturesName = "ingress-conformance-fixtures"
+
+// serverFields are the fields set by the API server, ignored by the differences with the live objects
+var serverFields = [][]string{
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "selfLink"},
+	{"status"},
+}
+
+func runApply(args []string) error {
+	var directory, filter, namespacePrefix string
+	var dryRun bool
+
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.StringVar(&directory, "features-directory", "features", "Directory that contains the feature files")
+	flags.StringVar(&filter, "feature", "", "Comma separated list of features to apply, as paths or names of the feature files (e.g. host_rules). All the features when empty")
+	flags.StringVar(&namespacePrefix, "namespace-prefix", FixturesName+"-", "Prefix of the namespaces of the features, followed by the name of the feature file")
+	flags.BoolVar(&dryRun, "dry-run", false, "Only print the differences with the live objects, validated by a server-side dry-run, without persisting the objects")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	paths, err := featurePaths(directory, filter)
+	if err != nil {
+		return err
+	}
+
+	if err := templates.Load(); err != nil {
+		return fmt.Errorf("error loading templates: %v", err)
+	}
+
+	kubernetes.DynamicClient, err = kubernetes.LoadDynamicClient()
+	if err != nil {
+		return fmt.Errorf("error loading dynamic client: %v", err)
+	}
+
+	ctx := context.Background()
+
+	var changed int
+	for _, path := range paths {
+		namespace := namespacePrefix + strings.ReplaceAll(strings.TrimSuffix(filepath.Base(path), ".feature"), "_", "-")
+
+		ingresses, err := featureIngresses(path, namespace)
+		if err != nil {
+			return err
+		}
+
+		if len(ingresses) == 0 {
+			continue
+		}
+
+		objects := []*unstructured.Unstructured{namespaceObject(namespace)}
+		for _, fixture := range ingresses {
+			fixtureObjects, err := kubernetes.FixtureObjects(namespace, fixture.ingress)
+			if err != nil {
+				var unsupportedErr report.UnsupportedError
+				if errors.As(err, &unsupportedErr) && unsupportedErr.Unsupported() {
+					fmt.Fprintf(os.Stderr, "%v: skipping the Ingress %v: %v\n", path, fixture.ingress.Name, err)
+					continue
+				}
+
+				return fmt.Errorf("%v: %w", path, err)
+			}
+
+			objects = append(objects, fixtureObjects...)
+		}
+
+		// the fixtures are deleted by the cleanup command, like the objects of the runs
+		for _, object := range objects {
+			objectLabels := object.GetLabels()
+			if objectLabels == nil {
+				objectLabels = map[string]string{}
+			}
+
+			objectLabels[kubernetes.ManagedByLabel] = kubernetes.ManagedBy
+			object.SetLabels(objectLabels)
+		}
+
+		// the namespace does not exist during a dry-run of a new feature, so its objects cannot be dry-run
+		namespaceExists := true
+		for _, object := range objects {
+			applied, live, err := applyObject(ctx, object, dryRun, namespaceExists)
+			if err != nil {
+				return fmt.Errorf("%v: %w", path, err)
+			}
+
+			if object.GetKind() == "Namespace" {
+				namespaceExists = live != ""
+			}
+
+			difference := diff.Lines(live, applied)
+			if difference == "" {
+				fmt.Printf("%v %v unchanged\n", object.GetKind(), objectName(object))
+				continue
+			}
+
+			changed++
+
+			action := "configured"
+			if live == "" {
+				action = "created"
+			}
+
+			if dryRun {
+				action += " (dry run)"
+			}
+
+			fmt.Printf("%v %v %v\n%v", object.GetKind(), objectName(object), action, difference)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%v objects changed\n", changed)
+	return nil
+}
+
+// applyObject applies an object, or dry-runs it, and returns the manifests of the object after and before
+// the apply, without the fields set by the API server. The manifest of an object that does not exist is empty.
+// TLS secrets are only created, to keep the certificates of the live secrets.
+func applyObject(ctx context.Context, object *unstructured.Unstructured, dryRun, namespaceExists bool) (string, string, error) {
+	live, err := kubernetes.LiveObject(ctx, object)
+	if err != nil {
+		return "", "", err
+	}
+
+	liveManifest, err := objectManifest(live)
+	if err != nil {
+		return "", "", err
+	}
+
+	if live != nil && object.GetKind() == "Secret" {
+		return liveManifest, liveManifest, nil
+	}
+
+	applied := object
+	if namespaceExists || !dryRun {
+		applied, err = kubernetes.ApplyObject(ctx, object, dryRun)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	appliedManifest, err := objectManifest(applied)
+	if err != nil {
+		return "", "", err
+	}
+
+	return appliedManifest, liveManifest, nil
+}
+
+// objectManifest returns the yaml manifest of an object without the fields set by the API server, and the
+// data of secrets redacted. The manifest of a nil object is empty.
+func objectManifest(object *unstructured.Unstructured) (string, error) {
+	if object == nil {
+		return "", nil
+	}
+
+	object = object.DeepCopy()
+	for _, field := range serverFields {
+		unstructured.RemoveNestedField(object.Object, field...)
+	}
+
+	if data, ok := object.Object["data"].(map[string]interface{}); ok && object.GetKind() == "Secret" {
+		for key := range data {
+			data[key] = "<redacted>"
+		}
+	}
+
+	manifest, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return "", err
+	}
+
+	return string(manifest), nil
+}
+
+// namespaceObject returns a namespace of the applied fixtures. Unlike the namespaces of the scenarios, it is not
+// removed when the suite starts
+func namespaceObject(name string) *unstructured.Unstructured {
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(name)
+	namespace.SetLabels(map[string]string{
+		"app.kubernetes.io/name": FixturesName,
+	})
+
+	return namespace
+}
+
+// objectName returns the name of an object, prefixed by its namespace
+func objectName(object *unstructured.Unstructured) string {
+	if object.GetNamespace() == "" {
+		return object.GetName()
+	}
+
+	return object.GetNamespace() + "/" + object.GetName()
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+)
+
+func init() {
+	register(&Command{
+		Name:        "cleanup",
+		Description: "Delete the objects created by the suite in all the namespaces, like the ones left behind by aborted runs",
+		Run:         runCleanup,
+	})
+}
+
+func runCleanup(args []string) error {
+	var runID string
+	var force bool
+
+	flags := flag.NewFlagSet("cleanup", flag.ContinueOnError)
+	flags.StringVar(&runID, "run-id", "", "Identifier of the run whose objects are deleted, printed when the suite starts. The objects of all the runs are deleted when empty")
+	flags.BoolVar(&force, "force", false, "Remove the finalizers of the objects, and finalize the namespaces stuck in the Terminating phase")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+
+	kubernetes.KubeClient, err = kubernetes.LoadClientset()
+	if err != nil {
+		return fmt.Errorf("error loading client: %v", err)
+	}
+
+	kubernetes.DynamicClient, err = kubernetes.LoadDynamicClient()
+	if err != nil {
+		return fmt.Errorf("error loading dynamic client: %v", err)
+	}
+
+	deleted, err := kubernetes.DeleteSuiteObjects(context.Background(), runID, force)
+	for _, name := range deleted {
+		fmt.Printf("%v deleted\n", name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%v objects deleted (%v)\n", len(deleted), kubernetes.SuiteSelector(runID))
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Command is run instead of the conformance tests when its name is the first argument of the suite
+type Command struct {
+	Name        string
+	Description string
+
+	// Run runs the command with the arguments after its name
+	Run func(args []string) error
+}
+
+var commands = map[string]*Command{}
+
+// register adds a command to the suite
+func register(command *Command) {
+	commands[command.Name] = command
+}
+
+// Commands returns the commands of the suite sorted by name
+func Commands() []*Command {
+	var list []*Command
+	for _, command := range commands {
+		list = append(list, command)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// Run runs the command with the arguments after its name
+func Run(name string, args []string) error {
+	command, ok := commands[name]
+	if !ok {
+		var names []string
+		for _, command := range Commands() {
+			names = append(names, command.Name)
+		}
+
+		return fmt.Errorf("unknown command %v (valid commands are %v)", name, strings.Join(names, ", "))
+	}
+
+	return command.Run(args)
+}
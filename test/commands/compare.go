@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+func init() {
+	register(&Command{
+		Name:        "compare",
+		Description: "Compare the JSON reports of several ingress controllers side by side",
+		Run:         runCompare,
+	})
+}
+
+func runCompare(args []string) error {
+	var baseline, output string
+
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	flags.StringVar(&baseline, "baseline", "", "Path of the JSON report of a previous run. The command fails when scenarios passing in it fail in the other reports")
+	flags.StringVar(&output, "output", "", "Path of the HTML page of the comparison. When empty, only the text comparison is printed")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	paths := flags.Args()
+	if baseline != "" {
+		paths = append([]string{baseline}, paths...)
+	}
+
+	if len(paths) < 2 {
+		return fmt.Errorf("the paths of at least two JSON reports are required")
+	}
+
+	var reports []*report.ConformanceReport
+	for _, path := range paths {
+		result, err := report.LoadJSON(path)
+		if err != nil {
+			return err
+		}
+
+		reports = append(reports, result)
+	}
+
+	comparison := report.Compare(reports)
+	if err := comparison.RenderText(os.Stdout); err != nil {
+		return err
+	}
+
+	if output != "" {
+		if err := comparison.WriteHTML(output); err != nil {
+			return err
+		}
+	}
+
+	if baseline == "" {
+		return nil
+	}
+
+	regressions := comparison.Regressions()
+	if len(regressions) == 0 {
+		fmt.Printf("\nNo regressions from the baseline %v\n", baseline)
+		return nil
+	}
+
+	fmt.Printf("\nRegressions from the baseline %v:\n", baseline)
+	for _, regression := range regressions {
+		fmt.Printf("  %v: %v is %v with %v\n", regression.Feature, regression.Name, regression.Status, regression.Controller.Label())
+	}
+
+	return fmt.Errorf("%v scenarios passing in the baseline do not pass anymore", len(regressions))
+}
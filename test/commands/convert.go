@@ -0,0 +1,278 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cucumber/gherkin-go/v11"
+	"github.com/cucumber/messages-go/v10"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/gateway"
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes/templates"
+)
+
+func init() {
+	register(&Command{
+		Name:        "convert",
+		Description: "Convert the Ingresses of the feature files, or of manifest files, to Gateway API manifests",
+		Run:         runConvert,
+	})
+}
+
+var (
+	// ingressStep matches the steps whose doc string defines an Ingress
+	ingressStep = regexp.MustCompile(`Ingress resource`)
+	// ingressSpecStep matches the steps whose doc string only defines the spec of an Ingress
+	ingressSpecStep = regexp.MustCompile(`Ingress resource named "([^"]+)" with this spec`)
+)
+
+// fixtureIngress is an Ingress of a feature or a manifest file, and where it was defined
+type fixtureIngress struct {
+	source  string
+	ingress *networking.Ingress
+}
+
+func runConvert(args []string) error {
+	var directory, namespace, gatewayClassName string
+
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.StringVar(&directory, "features-directory", "features", "Directory that contains the feature files. Used when no manifest file is given")
+	flags.StringVar(&namespace, "namespace", "conformance", "Namespace of the Ingresses of the feature files, available as {{ .Namespace }} in their manifests")
+	flags.StringVar(&gatewayClassName, "gateway-class", "conformance", "Sets the value of spec.gatewayClassName in the Gateways")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of convert: [flags] [manifest files]\n")
+		fmt.Fprintf(flags.Output(), "The Ingresses of the manifest files, or of the feature files when none is given, are converted. Use - to read the standard input.\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var ingresses []*fixtureIngress
+	var err error
+
+	if flags.NArg() == 0 {
+		if err := templates.Load(); err != nil {
+			return fmt.Errorf("error loading templates: %v", err)
+		}
+
+		var paths []string
+		paths, err = featurePaths(directory, "")
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			var featureFixtures []*fixtureIngress
+			featureFixtures, err = featureIngresses(path, namespace)
+			if err != nil {
+				return err
+			}
+
+			ingresses = append(ingresses, featureFixtures...)
+		}
+	} else {
+		ingresses, err = manifestIngresses(flags.Args())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(ingresses) == 0 {
+		return fmt.Errorf("there are no Ingresses to convert")
+	}
+
+	var unconvertible int
+	for _, converted := range ingresses {
+		resources, err := gateway.FromIngress(converted.ingress, gatewayClassName)
+		if err != nil {
+			var unconvertibleErr *gateway.UnconvertibleError
+			if !errors.As(err, &unconvertibleErr) {
+				return fmt.Errorf("%v: %w", converted.source, err)
+			}
+
+			unconvertible++
+			fmt.Fprintf(os.Stderr, "%v: the Ingress %v is partially converted, these constructs have no equivalent in the Gateway API:\n",
+				converted.source, converted.ingress.Name)
+			for _, construct := range unconvertibleErr.Constructs {
+				fmt.Fprintf(os.Stderr, "  - %v\n", construct)
+			}
+		}
+
+		for _, object := range resources.Objects() {
+			manifest, err := yaml.Marshal(object.Object)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("---\n# %v: Ingress %v\n%s", converted.source, converted.ingress.Name, manifest)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%v Ingresses converted, %v with constructs that cannot be converted\n", len(ingresses), unconvertible)
+	return nil
+}
+
+// featurePaths returns the paths of the feature files of the directory, sorted. With a filter, only the
+// features of its comma separated list of paths or names of feature files (e.g. host_rules) are returned.
+func featurePaths(directory, filter string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(directory, "*.feature"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("there are no feature files in %v", directory)
+	}
+
+	if filter == "" {
+		return paths, nil
+	}
+
+	var selected []string
+	for _, name := range strings.Split(filter, ",") {
+		name = strings.TrimSpace(name)
+
+		var found bool
+		for _, path := range paths {
+			base := filepath.Base(path)
+			if name == path || name == base || name == strings.TrimSuffix(base, ".feature") {
+				selected = append(selected, path)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("the feature '%v' does not exist", name)
+		}
+	}
+
+	return selected, nil
+}
+
+// featureIngresses returns the Ingresses defined in the doc strings of the steps of a feature file.
+// Ingresses defined more than once, like in the examples of a scenario outline, are returned once.
+func featureIngresses(path, namespace string) ([]*fixtureIngress, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := gherkin.ParseGherkinDocument(bytes.NewReader(data), (&messages.Incrementing{}).NewId)
+	if err != nil {
+		return nil, fmt.Errorf("parsing feature %v: %w", path, err)
+	}
+
+	if document.Feature == nil {
+		return nil, nil
+	}
+
+	var ingresses []*fixtureIngress
+
+	seen := map[string]bool{}
+	for _, pickle := range gherkin.Pickles(*document, path, (&messages.Incrementing{}).NewId) {
+		for _, step := range pickle.Steps {
+			docString := step.GetArgument().GetDocString()
+			if docString == nil || !ingressStep.MatchString(step.Text) || seen[docString.Content] {
+				continue
+			}
+
+			seen[docString.Content] = true
+
+			var ingress *networking.Ingress
+			if match := ingressSpecStep.FindStringSubmatch(step.Text); match != nil {
+				ingress, err = kubernetes.IngressFromSpec(match[1], namespace, docString.Content)
+			} else if strings.Contains(step.Text, "without class") {
+				ingress, err = kubernetes.IngressWithoutClassFromManifest(namespace, docString.Content)
+			} else {
+				ingress, err = kubernetes.IngressFromManifest(namespace, docString.Content)
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", path, err)
+			}
+
+			if ingress.Kind != "" && ingress.Kind != "Ingress" {
+				continue
+			}
+
+			ingresses = append(ingresses, &fixtureIngress{source: path, ingress: ingress})
+		}
+	}
+
+	return ingresses, nil
+}
+
+// manifestIngresses returns the Ingresses of YAML or JSON manifest files, skipping the other kinds of objects
+func manifestIngresses(paths []string) ([]*fixtureIngress, error) {
+	var ingresses []*fixtureIngress
+	for _, path := range paths {
+		var reader io.Reader = os.Stdin
+		if path != "-" {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+
+			reader = file
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(reader, 4096)
+		for {
+			object := &unstructured.Unstructured{}
+			if err := decoder.Decode(&object.Object); err != nil {
+				if err == io.EOF {
+					break
+				}
+
+				return nil, fmt.Errorf("decoding %v: %w", path, err)
+			}
+
+			if object.GetKind() != "Ingress" || object.GroupVersionKind().Group != networking.GroupName {
+				continue
+			}
+
+			ingress := &networking.Ingress{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, ingress); err != nil {
+				return nil, fmt.Errorf("decoding Ingress %v of %v: %w", object.GetName(), path, err)
+			}
+
+			ingresses = append(ingresses, &fixtureIngress{source: path, ingress: ingress})
+		}
+	}
+
+	return ingresses, nil
+}
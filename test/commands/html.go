@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+func init() {
+	register(&Command{
+		Name:        "html",
+		Description: "Render a JSON report as a self-contained HTML page",
+		Run:         runHTML,
+	})
+}
+
+func runHTML(args []string) error {
+	var input, output string
+
+	flags := flag.NewFlagSet("html", flag.ContinueOnError)
+	flags.StringVar(&input, "input", "", "Path of the JSON report")
+	flags.StringVar(&output, "output", "report.html", "Path of the HTML page")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if input == "" {
+		return fmt.Errorf("the path of the JSON report is required")
+	}
+
+	return report.WriteHTMLFromJSON(input, output)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"flag"
+	"fmt"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes/templates"
+)
+
+func init() {
+	register(&Command{
+		Name:        "job",
+		Description: "Print the manifests to run the conformance suite as a Job inside the cluster",
+		Run:         runJob,
+	})
+}
+
+// jobValues contains the values of the job template
+type jobValues struct {
+	Namespace string
+	Image     string
+
+	// Format of the results. The pretty format prints the results in the log of the pod
+	Format string
+	// Profile tested by the conformance suite
+	Profile string
+	// ControllerSelector label selector of the ingress controller pods, to collect their logs on failures
+	ControllerSelector string
+
+	IngressClass         string
+	WaitForStatusTimeout string
+	TestTimeout          string
+}
+
+func runJob(args []string) error {
+	values := &jobValues{}
+
+	flags := flag.NewFlagSet("job", flag.ContinueOnError)
+	flags.StringVar(&values.Namespace, "namespace", "ingress-conformance", "Namespace of the Job")
+	flags.StringVar(&values.Image, "image", "", "Image of the conformance suite")
+	flags.StringVar(&values.Format, "format", "pretty", "Set godog format to use. Valid values are pretty and cucumber")
+	flags.StringVar(&values.Profile, "profile", "experimental", "Conformance profile to test, including the profiles below it. Valid values are core, extended and experimental")
+	flags.StringVar(&values.ControllerSelector, "controller-selector", "", "Label selector of the ingress controller pods. Their logs are collected when a scenario fails")
+	flags.StringVar(&values.IngressClass, "ingress-class", "conformance", "Sets the value of spec.ingressClassName in Ingress definitions without class")
+	flags.StringVar(&values.WaitForStatusTimeout, "wait-time-for-ingress-status", "5m", "Maximum wait time for valid ingress status value")
+	flags.StringVar(&values.TestTimeout, "test-timeout", "20m", "Maximum duration of the conformance suite")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if values.Image == "" {
+		return fmt.Errorf("the image of the conformance suite is required")
+	}
+
+	if err := templates.Load(); err != nil {
+		return fmt.Errorf("error loading templates: %v", err)
+	}
+
+	manifests, err := templates.Render("job", values)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(manifests)
+	return nil
+}
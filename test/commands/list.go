@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+func init() {
+	register(&Command{
+		Name:        "list",
+		Description: "List the features and scenarios of the suite, with their tags, profile and fixtures",
+		Run:         runList,
+	})
+}
+
+func runList(args []string) error {
+	var directory, format string
+
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.StringVar(&directory, "features-directory", "features", "Directory that contains the feature files")
+	flags.StringVar(&format, "format", "text", "Output format. Valid values are text and json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if format != "text" && format != "json" {
+		return fmt.Errorf("the format '%v' is not supported (valid values are text and json)", format)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(directory, "*.feature"))
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("there are no feature files in %v", directory)
+	}
+
+	scenarios, err := report.ParseFeatures(paths)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(scenarios)
+	}
+
+	var feature string
+	for _, scenario := range scenarios {
+		if scenario.Feature != feature {
+			feature = scenario.Feature
+			fmt.Printf("%v: %v\n", scenario.Feature, scenario.FeatureName)
+		}
+
+		fmt.Printf("  %v (line %v)\n", scenario.Name, scenario.Line)
+		fmt.Printf("    profile:  %v\n", scenario.Profile)
+		fmt.Printf("    tags:     %v\n", strings.Join(scenario.Tags, " "))
+		fmt.Printf("    fixtures: %v\n", strings.Join(scenario.Fixtures, ", "))
+	}
+
+	return nil
+}
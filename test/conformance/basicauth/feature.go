@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package basicauth
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/messages-go/v10"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	tstate "sigs.k8s.io/ingress-controller-conformance/test/state"
+)
+
+var (
+	state *tstate.Scenario
+)
+
+// IMPORTANT: Steps definitions are generated and should not be modified
+// by hand but rather through make codegen. DO NOT EDIT.
+
+// InitializeScenario configures the Feature to test
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Step(`^a new random namespace$`, aNewRandomNamespace)
+	ctx.Step(`^a basic authentication secret named "([^"]*)" with the user "([^"]*)" and the password "([^"]*)"$`, aBasicAuthenticationSecretNamedWithTheUserAndThePassword)
+	ctx.Step(`^an Ingress resource$`, anIngressResource)
+	ctx.Step(`^The Ingress status shows the IP address or FQDN where it is exposed$`, theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed)
+	ctx.Step(`^the requests send the credentials of the user "([^"]*)" with the password "([^"]*)"$`, theRequestsSendTheCredentialsOfTheUserWithThePassword)
+	ctx.Step(`^I send a "([^"]*)" request to "([^"]*)"$`, iSendARequestTo)
+	ctx.Step(`^the response status-code must be (\d+)$`, theResponseStatuscodeMustBe)
+	ctx.Step(`^the response must ask for basic credentials of the realm "([^"]*)"$`, theResponseMustAskForBasicCredentialsOfTheRealm)
+	ctx.Step(`^the response must be served by the "([^"]*)" service$`, theResponseMustBeServedByTheService)
+
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
+	})
+
+	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
+		// delete namespace an all the content
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
+	})
+}
+
+func aNewRandomNamespace() error {
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	state.Namespace = ns
+	return nil
+}
+
+func aBasicAuthenticationSecretNamedWithTheUserAndThePassword(secretName, username, password string) error {
+	return kubernetes.NewBasicAuthSecret(state.Context(), kubernetes.KubeClient, state.Namespace, secretName, username, password)
+}
+
+func anIngressResource(spec *messages.PickleStepArgument_PickleDocString) error {
+	ingress, err := kubernetes.IngressFromManifest(state.Namespace, spec.GetContent())
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
+	if err != nil {
+		return err
+	}
+
+	state.IngressName = ingress.GetName()
+
+	return nil
+}
+
+func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
+	if err != nil {
+		return err
+	}
+
+	state.SetDefaultAddress(ingress)
+	return err
+}
+
+func theRequestsSendTheCredentialsOfTheUserWithThePassword(username, password string) error {
+	state.SetBasicAuth(username, password)
+	return nil
+}
+
+func iSendARequestTo(method string, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	return state.CaptureRoundTrip(method, u.Scheme, u.Host, u.RequestURI())
+}
+
+func theResponseStatuscodeMustBe(statusCode int) error {
+	return state.AssertStatusCode(statusCode)
+}
+
+func theResponseMustAskForBasicCredentialsOfTheRealm(realm string) error {
+	return state.AssertBasicAuthChallenge(realm)
+}
+
+func theResponseMustBeServedByTheService(service string) error {
+	return state.AssertServedBy(service)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package defaultbackend
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/cucumber/godog"
@@ -48,18 +49,29 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the request proto must be "([^"]*)"$`, theRequestProtoMustBe)
 	ctx.Step(`^the request headers must contain <key> with matching <value>$`, theRequestHeadersMustContainKeyWithMatchingValue)
 
-	ctx.BeforeScenario(func(*godog.Scenario) {
-		state = tstate.New()
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
 	})
 
 	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
 		// delete namespace an all the content
-		_ = kubernetes.DeleteNamespace(kubernetes.KubeClient, state.Namespace)
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
 	})
 }
 
 func aNewRandomNamespace() error {
-	ns, err := kubernetes.NewNamespace(kubernetes.KubeClient)
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
 	if err != nil {
 		return err
 	}
@@ -74,12 +86,12 @@ func anIngressResourceNamedWithThisSpec(name string, spec *messages.PickleStepAr
 		return err
 	}
 
-	err = kubernetes.DeploymentsFromIngress(kubernetes.KubeClient, ingress)
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
 	if err != nil {
 		return err
 	}
 
-	err = kubernetes.NewIngress(kubernetes.KubeClient, state.Namespace, ingress)
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
 	if err != nil {
 		return err
 	}
@@ -90,12 +102,12 @@ func anIngressResourceNamedWithThisSpec(name string, spec *messages.PickleStepAr
 }
 
 func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
-	ingress, err := kubernetes.WaitForIngressAddress(kubernetes.KubeClient, state.Namespace, state.IngressName)
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
 	if err != nil {
 		return err
 	}
 
-	state.IPOrFQDN = ingress
+	state.SetDefaultAddress(ingress)
 	return err
 }
 
@@ -135,11 +147,13 @@ func theRequestHeadersMustContainKeyWithMatchingValue(headers *messages.PickleSt
 	return assertHeaderTable(headers, state.AssertRequestHeader)
 }
 
+// assertHeaderTable checks all the rows of the table, reporting all the headers that do not match
 func assertHeaderTable(headerTable *messages.PickleStepArgument_PickleTable, assertF func(key string, value string) error) error {
 	if len(headerTable.Rows) < 1 {
 		return fmt.Errorf("expected a table with at least one row")
 	}
 
+	var asserts []tstate.Assert
 	for i, row := range headerTable.Rows {
 		if len(row.Cells) != 2 {
 			return fmt.Errorf("expected a table with 2 cells, it contained %v", len(row.Cells))
@@ -156,10 +170,10 @@ func assertHeaderTable(headerTable *messages.PickleStepArgument_PickleTable, ass
 			continue
 		}
 
-		if err := assertF(headerKey, headerValue); err != nil {
-			return err
-		}
+		asserts = append(asserts, tstate.AssertFunc(func(*tstate.Scenario) error {
+			return assertF(headerKey, headerValue)
+		}))
 	}
 
-	return nil
+	return state.AssertAll(asserts...)
 }
@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultingressclass
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/messages-go/v10"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	tstate "sigs.k8s.io/ingress-controller-conformance/test/state"
+)
+
+var (
+	state *tstate.Scenario
+)
+
+// IMPORTANT: Steps definitions are generated and should not be modified
+// by hand but rather through make codegen. DO NOT EDIT.
+
+// InitializeScenario configures the Feature to test
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Step(`^an Ingress resource without class in a new random namespace$`, anIngressResourceWithoutClassInANewRandomNamespace)
+	ctx.Step(`^The Ingress must be assigned the default IngressClass of the cluster$`, theIngressMustBeAssignedTheDefaultIngressClassOfTheCluster)
+	ctx.Step(`^The Ingress status must only contain the IP address or FQDN if the tested ingress class is the default$`, theIngressStatusMustOnlyContainTheIPAddressOrFQDNIfTheTestedIngressClassIsTheDefault)
+
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
+	})
+
+	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
+		// delete namespace an all the content
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
+	})
+}
+
+func anIngressResourceWithoutClassInANewRandomNamespace(spec *messages.PickleStepArgument_PickleDocString) error {
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	state.Namespace = ns
+
+	ingress, err := kubernetes.IngressWithoutClassFromManifest(state.Namespace, spec.GetContent())
+	if err != nil {
+		return err
+	}
+
+	if ingress.Spec.IngressClassName != nil {
+		return fmt.Errorf("the Ingress definition should not contain a class")
+	}
+
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
+	if err != nil {
+		return err
+	}
+
+	state.IngressName = ingress.GetName()
+
+	return nil
+}
+
+func theIngressMustBeAssignedTheDefaultIngressClassOfTheCluster() error {
+	defaultClass, err := kubernetes.DefaultIngressClass(state.Context(), kubernetes.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	ingressClass, err := kubernetes.IngressClassName(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
+	if err != nil {
+		return err
+	}
+
+	if ingressClass != defaultClass {
+		return fmt.Errorf("expected the Ingress to be assigned the default IngressClass %q but it was assigned %q", defaultClass, ingressClass)
+	}
+
+	return nil
+}
+
+func theIngressStatusMustOnlyContainTheIPAddressOrFQDNIfTheTestedIngressClassIsTheDefault() error {
+	defaultClass, err := kubernetes.DefaultIngressClass(state.Context(), kubernetes.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
+
+	if defaultClass == kubernetes.IngressClassValue && err != nil {
+		return fmt.Errorf("expected the Ingress to be implemented by the controller of the default IngressClass %v: %w", defaultClass, err)
+	}
+
+	if defaultClass != kubernetes.IngressClassValue && err == nil {
+		return fmt.Errorf("waiting for Ingress status should not return an IP address or FQDN (default IngressClass %q)", defaultClass)
+	}
+
+	return nil
+}
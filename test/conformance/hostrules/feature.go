@@ -17,6 +17,7 @@ limitations under the License.
 package hostrules
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/cucumber/godog"
@@ -45,18 +46,29 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the response must be served by the "([^"]*)" service$`, theResponseMustBeServedByTheService)
 	ctx.Step(`^the request host must be "([^"]*)"$`, theRequestHostMustBe)
 
-	ctx.BeforeScenario(func(*godog.Scenario) {
-		state = tstate.New()
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
 	})
 
 	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
 		// delete namespace an all the content
-		_ = kubernetes.DeleteNamespace(kubernetes.KubeClient, state.Namespace)
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
 	})
 }
 
 func aNewRandomNamespace() error {
-	ns, err := kubernetes.NewNamespace(kubernetes.KubeClient)
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
 	if err != nil {
 		return err
 	}
@@ -71,12 +83,12 @@ func anIngressResource(spec *messages.PickleStepArgument_PickleDocString) error
 		return err
 	}
 
-	err = kubernetes.DeploymentsFromIngress(kubernetes.KubeClient, ingress)
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
 	if err != nil {
 		return err
 	}
 
-	err = kubernetes.NewIngress(kubernetes.KubeClient, state.Namespace, ingress)
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
 	if err != nil {
 		return err
 	}
@@ -87,7 +99,7 @@ func anIngressResource(spec *messages.PickleStepArgument_PickleDocString) error
 }
 
 func aSelfsignedTLSSecretNamedForTheHostname(secretName string, host string) error {
-	err := kubernetes.NewSelfSignedSecret(kubernetes.KubeClient, state.Namespace, secretName, []string{host})
+	err := kubernetes.NewSelfSignedSecret(state.Context(), kubernetes.KubeClient, state.Namespace, secretName, []string{host})
 	if err != nil {
 		return err
 	}
@@ -99,12 +111,12 @@ func aSelfsignedTLSSecretNamedForTheHostname(secretName string, host string) err
 }
 
 func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
-	ingress, err := kubernetes.WaitForIngressAddress(kubernetes.KubeClient, state.Namespace, state.IngressName)
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
 	if err != nil {
 		return err
 	}
 
-	state.IPOrFQDN = ingress
+	state.SetDefaultAddress(ingress)
 
 	return err
 }
@@ -17,22 +17,18 @@ limitations under the License.
 package loadbalancing
 
 import (
-	"fmt"
+	"context"
 	"net/url"
 
 	"github.com/cucumber/godog"
 	"github.com/cucumber/messages-go/v10"
-	"k8s.io/apimachinery/pkg/util/sets"
 
-	"sigs.k8s.io/ingress-controller-conformance/test/http"
 	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
 	tstate "sigs.k8s.io/ingress-controller-conformance/test/state"
 )
 
 var (
 	state *tstate.Scenario
-
-	resultStatus map[int]sets.String
 )
 
 // IMPORTANT: Steps definitions are generated and should not be modified
@@ -47,19 +43,29 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^I send (\d+) requests to "([^"]*)"$`, iSendRequestsTo)
 	ctx.Step(`^all the responses status-code must be (\d+) and the response body should contain the IP address of (\d+) different Kubernetes pods$`, allTheResponsesStatuscodeMustBeAndTheResponseBodyShouldContainTheIPAddressOfDifferentKubernetesPods)
 
-	ctx.BeforeScenario(func(*godog.Scenario) {
-		state = tstate.New()
-		resultStatus = make(map[int]sets.String, 0)
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
 	})
 
 	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
 		// delete namespace an all the content
-		_ = kubernetes.DeleteNamespace(kubernetes.KubeClient, state.Namespace)
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
 	})
 }
 
 func aNewRandomNamespace() error {
-	ns, err := kubernetes.NewNamespace(kubernetes.KubeClient)
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
 	if err != nil {
 		return err
 	}
@@ -74,12 +80,12 @@ func anIngressResourceNamedWithThisSpec(name string, spec *messages.PickleStepAr
 		return err
 	}
 
-	err = kubernetes.DeploymentsFromIngress(kubernetes.KubeClient, ingress)
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
 	if err != nil {
 		return err
 	}
 
-	err = kubernetes.NewIngress(kubernetes.KubeClient, state.Namespace, ingress)
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
 	if err != nil {
 		return err
 	}
@@ -90,12 +96,12 @@ func anIngressResourceNamedWithThisSpec(name string, spec *messages.PickleStepAr
 }
 
 func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
-	ingress, err := kubernetes.WaitForIngressAddress(kubernetes.KubeClient, state.Namespace, state.IngressName)
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
 	if err != nil {
 		return err
 	}
 
-	state.IPOrFQDN = ingress
+	state.SetDefaultAddress(ingress)
 	return err
 }
 
@@ -105,35 +111,18 @@ func iSendRequestsTo(totalRequest int, rawURL string) error {
 		return err
 	}
 
-	for iteration := 1; iteration <= totalRequest; iteration++ {
-		capturedRequest, capturedResponse, err := http.CaptureRoundTrip("GET", u.Scheme, u.Host, u.Path, state.IPOrFQDN)
-		if err != nil {
-			return err
-		}
-
-		if resultStatus[capturedResponse.StatusCode] == nil {
-			resultStatus[capturedResponse.StatusCode] = sets.NewString()
-		}
-
-		resultStatus[capturedResponse.StatusCode].Insert(capturedRequest.Pod)
-	}
-
-	return nil
+	return state.CaptureMultipleRoundTrips("GET", u.Scheme, u.Host, u.Path, totalRequest, 1)
 }
 
 func allTheResponsesStatuscodeMustBeAndTheResponseBodyShouldContainTheIPAddressOfDifferentKubernetesPods(statusCode int, pods int) error {
-	results, ok := resultStatus[statusCode]
-	if !ok {
-		return fmt.Errorf("no reponses for status code %v returned", statusCode)
-	}
-
-	if results.Len() != pods {
-		return fmt.Errorf("expected %v different POD IP addresses/FQDN for status code %v but %v was returned", pods, statusCode, results.Len())
+	err := state.AssertAllStatusCodes(statusCode)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return state.AssertServedByAtLeastNPods(pods)
 }
 
 func theBackendDeploymentForTheIngressResourceIsScaledTo(deployment string, replicas int) error {
-	return kubernetes.ScaleIngressBackendDeployment(kubernetes.KubeClient, state.Namespace, state.IngressName, deployment, replicas)
+	return kubernetes.ScaleIngressBackendDeployment(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName, deployment, replicas)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package pathrules
 
 import (
+	"context"
 	"net/url"
 
 	"github.com/cucumber/godog"
@@ -42,18 +43,29 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Step(`^the response must be served by the "([^"]*)" service$`, theResponseMustBeServedByTheService)
 	ctx.Step(`^the request path must be "([^"]*)"$`, theRequestPathMustBe)
 
-	ctx.BeforeScenario(func(*godog.Scenario) {
-		state = tstate.New()
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
 	})
 
 	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
 		// delete namespace an all the content
-		_ = kubernetes.DeleteNamespace(kubernetes.KubeClient, state.Namespace)
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
 	})
 }
 
 func anIngressResourceInANewRandomNamespace(spec *messages.PickleStepArgument_PickleDocString) error {
-	ns, err := kubernetes.NewNamespace(kubernetes.KubeClient)
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
 	if err != nil {
 		return err
 	}
@@ -65,12 +77,12 @@ func anIngressResourceInANewRandomNamespace(spec *messages.PickleStepArgument_Pi
 		return err
 	}
 
-	err = kubernetes.DeploymentsFromIngress(kubernetes.KubeClient, ingress)
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
 	if err != nil {
 		return err
 	}
 
-	err = kubernetes.NewIngress(kubernetes.KubeClient, state.Namespace, ingress)
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
 	if err != nil {
 		return err
 	}
@@ -81,12 +93,12 @@ func anIngressResourceInANewRandomNamespace(spec *messages.PickleStepArgument_Pi
 }
 
 func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
-	ingress, err := kubernetes.WaitForIngressAddress(kubernetes.KubeClient, state.Namespace, state.IngressName)
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
 	if err != nil {
 		return err
 	}
 
-	state.IPOrFQDN = ingress
+	state.SetDefaultAddress(ingress)
 
 	return err
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tlssecretrotation
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cucumber/godog"
+	"github.com/cucumber/messages-go/v10"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	tstate "sigs.k8s.io/ingress-controller-conformance/test/state"
+)
+
+var (
+	state *tstate.Scenario
+)
+
+// IMPORTANT: Steps definitions are generated and should not be modified
+// by hand but rather through make codegen. DO NOT EDIT.
+
+// InitializeScenario configures the Feature to test
+func InitializeScenario(ctx *godog.ScenarioContext) {
+	ctx.Step(`^a new random namespace$`, aNewRandomNamespace)
+	ctx.Step(`^a self-signed TLS secret named "([^"]*)" for the "([^"]*)" hostname$`, aSelfsignedTLSSecretNamedForTheHostname)
+	ctx.Step(`^an Ingress resource$`, anIngressResource)
+	ctx.Step(`^The Ingress status shows the IP address or FQDN where it is exposed$`, theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed)
+	ctx.Step(`^I send a "([^"]*)" request to "([^"]*)"$`, iSendARequestTo)
+	ctx.Step(`^the response status-code must be (\d+)$`, theResponseStatuscodeMustBe)
+	ctx.Step(`^the response certificate must be the one of the "([^"]*)" secret$`, theResponseCertificateMustBeTheOneOfTheSecret)
+	ctx.Step(`^I keep sending "([^"]*)" requests to "([^"]*)" in the background$`, iKeepSendingRequestsToInTheBackground)
+	ctx.Step(`^the TLS secret "([^"]*)" is renewed for the "([^"]*)" hostname$`, theTLSSecretIsRenewedForTheHostname)
+	ctx.Step(`^the requests to "([^"]*)" must eventually present the certificate of the "([^"]*)" secret$`, theRequestsToMustEventuallyPresentTheCertificateOfTheSecret)
+	ctx.Step(`^the requests sent in the background must fail at most at the maximum error rate$`, theRequestsSentInTheBackgroundMustFailAtMostAtTheMaximumErrorRate)
+
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		state = tstate.New(scenario)
+
+		// the certificate is only presented in the TLS handshake of new connections
+		state.UseFreshConnections()
+	})
+
+	ctx.AfterStep(func(_ *godog.Step, err error) {
+		// show the round trips captured and the state of the cluster before the failure
+		if err != nil {
+			state.DumpHistory()
+			state.DumpDiagnostics()
+		}
+	})
+
+	ctx.AfterScenario(func(*messages.Pickle, error) {
+		// cancel the requests in flight
+		state.Close()
+
+		// delete namespace an all the content
+		_ = kubernetes.DeleteNamespace(context.Background(), kubernetes.KubeClient, state.Namespace)
+	})
+}
+
+func aNewRandomNamespace() error {
+	ns, err := kubernetes.NewNamespace(state.Context(), kubernetes.KubeClient)
+	if err != nil {
+		return err
+	}
+
+	state.Namespace = ns
+	return nil
+}
+
+func anIngressResource(spec *messages.PickleStepArgument_PickleDocString) error {
+	ingress, err := kubernetes.IngressFromManifest(state.Namespace, spec.GetContent())
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.DeploymentsFromIngress(state.Context(), kubernetes.KubeClient, ingress)
+	if err != nil {
+		return err
+	}
+
+	err = kubernetes.NewIngress(state.Context(), kubernetes.KubeClient, state.Namespace, ingress)
+	if err != nil {
+		return err
+	}
+
+	state.IngressName = ingress.GetName()
+
+	return nil
+}
+
+func aSelfsignedTLSSecretNamedForTheHostname(secretName string, host string) error {
+	err := kubernetes.NewSelfSignedSecret(state.Context(), kubernetes.KubeClient, state.Namespace, secretName, []string{host})
+	if err != nil {
+		return err
+	}
+
+	state.SecretName = secretName
+
+	return nil
+
+}
+
+func theIngressStatusShowsTheIPAddressOrFQDNWhereItIsExposed() error {
+	ingress, err := kubernetes.WaitForIngressAddress(state.Context(), kubernetes.KubeClient, state.Namespace, state.IngressName)
+	if err != nil {
+		return err
+	}
+
+	state.SetDefaultAddress(ingress)
+
+	return err
+}
+
+func iSendARequestTo(method string, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return state.CaptureRoundTrip(method, u.Scheme, u.Host, u.Path)
+}
+
+func theResponseStatuscodeMustBe(statusCode int) error {
+	return state.AssertStatusCode(statusCode)
+}
+
+func theResponseCertificateMustBeTheOneOfTheSecret(secretName string) error {
+	certificate, err := kubernetes.SecretCertificate(state.Context(), kubernetes.KubeClient, state.Namespace, secretName)
+	if err != nil {
+		return err
+	}
+
+	return state.AssertResponseCertificateIs(certificate)
+}
+
+func iKeepSendingRequestsToInTheBackground(method string, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	return state.StartLoad(method, u.Scheme, u.Host, u.Path)
+}
+
+func theTLSSecretIsRenewedForTheHostname(secretName string, host string) error {
+	return kubernetes.RenewSelfSignedSecret(state.Context(), kubernetes.KubeClient, state.Namespace, secretName, []string{host})
+}
+
+func theRequestsToMustEventuallyPresentTheCertificateOfTheSecret(rawURL string, secretName string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	certificate, err := kubernetes.SecretCertificate(state.Context(), kubernetes.KubeClient, state.Namespace, secretName)
+	if err != nil {
+		return err
+	}
+
+	return state.CaptureRoundTripUntil("GET", u.Scheme, u.Host, u.Path, func() error {
+		return state.AssertResponseCertificateIs(certificate)
+	})
+}
+
+func theRequestsSentInTheBackgroundMustFailAtMostAtTheMaximumErrorRate() error {
+	err := state.StopLoad()
+	if err != nil {
+		return err
+	}
+
+	return state.AssertErrorRateUnder(tstate.MaxErrorRate)
+}
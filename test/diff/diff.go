@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diff compares texts line by line
+package diff
+
+import (
+	"strings"
+)
+
+// contextLines is the number of unchanged lines printed around the changed lines
+const contextLines = 3
+
+// Lines returns the differences between two texts, as lines prefixed with - when removed, + when added
+// and a space when unchanged around the changes. It is empty when the texts are equal.
+func Lines(from, to string) string {
+	a := strings.Split(strings.TrimSuffix(from, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(to, "\n"), "\n")
+	if from == "" {
+		a = nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	var changed []bool
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			changed = append(changed, false)
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			changed = append(changed, true)
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			changed = append(changed, true)
+			j++
+		}
+	}
+
+	var output strings.Builder
+	last := -1
+	for n := range lines {
+		near := false
+		for m := n - contextLines; m <= n+contextLines; m++ {
+			if m >= 0 && m < len(changed) && changed[m] {
+				near = true
+				break
+			}
+		}
+
+		if !near {
+			continue
+		}
+
+		if last != -1 && n != last+1 {
+			output.WriteString("  ...\n")
+		}
+
+		output.WriteString(lines[n] + "\n")
+		last = n
+	}
+
+	return output.String()
+}
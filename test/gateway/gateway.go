@@ -0,0 +1,292 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gateway converts Ingresses to the equivalent Gateway API resources, a Gateway
+// with the listeners of the Ingress and an HTTPRoute for each rule of the Ingress
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+const (
+	// Group API group of the Gateway API
+	Group = "gateway.networking.k8s.io"
+	// Version version of the Gateway API resources created from the Ingresses
+	Version = "v1"
+
+	// IngressLabel label of the Gateway API resources with the name of the Ingress they were converted from
+	IngressLabel = "conformance.ingress.k8s.io/ingress"
+)
+
+var (
+	// GatewayResource resource of the Gateways
+	GatewayResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "gateways"}
+	// HTTPRouteResource resource of the HTTPRoutes
+	HTTPRouteResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "httproutes"}
+)
+
+// ignoredAnnotations are replaced by the Gateway API resources, or do not change the routing
+var ignoredAnnotations = map[string]bool{
+	"kubernetes.io/ingress.class":                      true,
+	"kubectl.kubernetes.io/last-applied-configuration": true,
+}
+
+// regexpCharacters are the characters of the ImplementationSpecific paths converted to regular expressions
+const regexpCharacters = `^$()[]{}*+?|\`
+
+// Resources contains the Gateway API resources equivalent to an Ingress
+type Resources struct {
+	Gateway    *unstructured.Unstructured
+	HTTPRoutes []*unstructured.Unstructured
+}
+
+// Objects returns the Gateway followed by the HTTPRoutes
+func (r *Resources) Objects() []*unstructured.Unstructured {
+	return append([]*unstructured.Unstructured{r.Gateway}, r.HTTPRoutes...)
+}
+
+// UnconvertibleError is returned when an Ingress uses constructs without equivalent in the Gateway API,
+// like the annotations of the ingress controllers. The scenarios returning it are reported as unsupported
+// instead of failed.
+type UnconvertibleError struct {
+	report.UnsupportedFeature
+
+	Ingress    string
+	Constructs []string
+}
+
+func (e *UnconvertibleError) Error() string {
+	return fmt.Sprintf("the Ingress %v cannot be converted to the Gateway API: %v", e.Ingress, strings.Join(e.Constructs, "; "))
+}
+
+// FromIngress converts an Ingress to a Gateway of the class, named like the Ingress, and an HTTPRoute
+// for each rule of the Ingress, and another one for its default backend. The constructs that cannot be
+// converted are skipped and reported in an UnconvertibleError, returned with the resources converted.
+func FromIngress(ingress *networking.Ingress, gatewayClassName string) (*Resources, error) {
+	c := &converter{ingress: ingress}
+
+	resources := &Resources{
+		Gateway: c.gateway(gatewayClassName),
+	}
+
+	if backend := ingress.Spec.DefaultBackend; backend != nil {
+		rule := map[string]interface{}{
+			"matches":     []interface{}{pathMatch("PathPrefix", "/")},
+			"backendRefs": c.backendRefs(backend, "the default backend"),
+		}
+
+		resources.HTTPRoutes = append(resources.HTTPRoutes, c.httpRoute(ingress.Name+"-default-backend", "", []interface{}{rule}))
+	}
+
+	for i, ingressRule := range ingress.Spec.Rules {
+		if ingressRule.HTTP == nil {
+			continue
+		}
+
+		var rules []interface{}
+		for _, path := range ingressRule.HTTP.Paths {
+			description := fmt.Sprintf("the path %v of the host %q", path.Path, ingressRule.Host)
+
+			rules = append(rules, map[string]interface{}{
+				"matches":     []interface{}{c.pathMatch(path, description)},
+				"backendRefs": c.backendRefs(&path.Backend, description),
+			})
+		}
+
+		resources.HTTPRoutes = append(resources.HTTPRoutes, c.httpRoute(fmt.Sprintf("%v-%v", ingress.Name, i), ingressRule.Host, rules))
+	}
+
+	var annotations []string
+	for key := range ingress.Annotations {
+		if !ignoredAnnotations[key] {
+			annotations = append(annotations, key)
+		}
+	}
+
+	sort.Strings(annotations)
+	for _, key := range annotations {
+		c.unconvertible("the annotation %v", key)
+	}
+
+	if len(c.constructs) != 0 {
+		return resources, &UnconvertibleError{Ingress: ingress.Name, Constructs: c.constructs}
+	}
+
+	return resources, nil
+}
+
+// converter keeps the constructs of an Ingress that cannot be converted
+type converter struct {
+	ingress    *networking.Ingress
+	constructs []string
+}
+
+func (c *converter) unconvertible(format string, args ...interface{}) {
+	c.constructs = append(c.constructs, fmt.Sprintf(format, args...))
+}
+
+// gateway returns a Gateway with an HTTP listener, and an HTTPS listener for each host of the TLS
+// section of the Ingress, terminating TLS with the certificate of its secret
+func (c *converter) gateway(gatewayClassName string) *unstructured.Unstructured {
+	listeners := []interface{}{
+		map[string]interface{}{
+			"name":     "http",
+			"protocol": "HTTP",
+			"port":     int64(80),
+		},
+	}
+
+	for i, tls := range c.ingress.Spec.TLS {
+		hosts := tls.Hosts
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+
+		for j, host := range hosts {
+			listener := map[string]interface{}{
+				"name":     fmt.Sprintf("https-%v-%v", i, j),
+				"protocol": "HTTPS",
+				"port":     int64(443),
+				"tls": map[string]interface{}{
+					"mode": "Terminate",
+					"certificateRefs": []interface{}{
+						map[string]interface{}{"name": tls.SecretName},
+					},
+				},
+			}
+
+			if host != "" {
+				listener["hostname"] = host
+			}
+
+			listeners = append(listeners, listener)
+		}
+	}
+
+	return c.object("Gateway", c.ingress.Name, map[string]interface{}{
+		"gatewayClassName": gatewayClassName,
+		"listeners":        listeners,
+	})
+}
+
+// httpRoute returns an HTTPRoute attached to the Gateway of the Ingress, for the host when it is not empty
+func (c *converter) httpRoute(name, host string, rules []interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": c.ingress.Name},
+		},
+		"rules": rules,
+	}
+
+	if host != "" {
+		spec["hostnames"] = []interface{}{host}
+	}
+
+	return c.object("HTTPRoute", name, spec)
+}
+
+// pathMatch converts the path of an Ingress rule. Prefix paths match the same path elements than
+// PathPrefix matches, and ImplementationSpecific paths are regular expressions when they contain any
+// of their special characters, as with ingress-nginx, or prefixes otherwise.
+func (c *converter) pathMatch(path networking.HTTPIngressPath, description string) map[string]interface{} {
+	value := path.Path
+	if value == "" {
+		value = "/"
+	}
+
+	pathType := networking.PathTypeImplementationSpecific
+	if path.PathType != nil {
+		pathType = *path.PathType
+	}
+
+	switch pathType {
+	case networking.PathTypeExact:
+		return pathMatch("Exact", value)
+	case networking.PathTypePrefix:
+		return pathMatch("PathPrefix", value)
+	case networking.PathTypeImplementationSpecific:
+		if strings.ContainsAny(value, regexpCharacters) {
+			return pathMatch("RegularExpression", value)
+		}
+
+		return pathMatch("PathPrefix", value)
+	default:
+		c.unconvertible("the path type %v of %v", pathType, description)
+		return pathMatch("PathPrefix", value)
+	}
+}
+
+// backendRefs converts the backend of an Ingress rule. The Gateway API only references the ports of
+// the services by number, and other resources only with extensions of the implementations.
+func (c *converter) backendRefs(backend *networking.IngressBackend, description string) []interface{} {
+	if backend.Service == nil {
+		c.unconvertible("the resource backend of %v", description)
+		return []interface{}{}
+	}
+
+	if backend.Service.Port.Name != "" {
+		c.unconvertible("the port name %v of the service %v of %v", backend.Service.Port.Name, backend.Service.Name, description)
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name": backend.Service.Name,
+			"port": int64(backend.Service.Port.Number),
+		},
+	}
+}
+
+// object returns a Gateway API resource in the namespace of the Ingress, labeled with its name
+func (c *converter) object(kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	metadata := map[string]interface{}{
+		"name": name,
+		"labels": map[string]interface{}{
+			IngressLabel: c.ingress.Name,
+		},
+	}
+
+	if c.ingress.Namespace != "" {
+		metadata["namespace"] = c.ingress.Namespace
+	}
+
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": Group + "/" + Version,
+			"kind":       kind,
+			"metadata":   metadata,
+			"spec":       spec,
+		},
+	}
+}
+
+func pathMatch(matchType, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":  matchType,
+			"value": value,
+		},
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// decodeBody decodes a response body with its Content-Encoding. Only gzip and deflate are
+// supported, the requests must not accept other encodings, like brotli (br).
+func decodeBody(contentEncoding string, body []byte) ([]byte, error) {
+	// responses without body, like HEAD responses, may have a Content-Encoding
+	if len(body) == 0 {
+		return body, nil
+	}
+
+	var reader io.Reader
+
+	switch encoding := strings.ToLower(strings.TrimSpace(contentEncoding)); encoding {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("decoding %v response body: %w", encoding, err)
+		}
+		defer gz.Close()
+
+		reader = gz
+	case "deflate":
+		// deflate is zlib (RFC 1950), but some servers send raw deflate (RFC 1951) data
+		zr, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			reader = flate.NewReader(bytes.NewReader(body))
+		} else {
+			defer zr.Close()
+			reader = zr
+		}
+	default:
+		return nil, fmt.Errorf("decoding %v response body is not supported", encoding)
+	}
+
+	decoded, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %v response body: %w", contentEncoding, err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ForwardedElement contains the parameters of a single element of a Forwarded header (RFC 7239)
+type ForwardedElement struct {
+	For   string
+	By    string
+	Host  string
+	Proto string
+}
+
+// ForwardedHeaders contains the forwarding information received by the echoserver,
+// from both the standard Forwarded header and the de-facto X-Forwarded-* headers.
+type ForwardedHeaders struct {
+	// Forwarded contains the elements of all the Forwarded headers, in order
+	Forwarded []ForwardedElement
+
+	// XForwardedFor contains the addresses of all the X-Forwarded-For headers, in order
+	XForwardedFor   []string
+	XForwardedProto string
+	XForwardedHost  string
+	XForwardedPort  string
+}
+
+// ForwardedHeaders parses the forwarding headers of the captured request
+func (r *CapturedRequest) ForwardedHeaders() *ForwardedHeaders {
+	headers := http.Header(r.Headers)
+
+	forwarded := &ForwardedHeaders{
+		XForwardedProto: lastListValue(headers.Values("X-Forwarded-Proto")),
+		XForwardedHost:  lastListValue(headers.Values("X-Forwarded-Host")),
+		XForwardedPort:  lastListValue(headers.Values("X-Forwarded-Port")),
+	}
+
+	for _, value := range headers.Values("X-Forwarded-For") {
+		for _, address := range splitQuoted(value, ',') {
+			if address = strings.TrimSpace(address); address != "" {
+				forwarded.XForwardedFor = append(forwarded.XForwardedFor, address)
+			}
+		}
+	}
+
+	for _, value := range headers.Values("Forwarded") {
+		for _, element := range splitQuoted(value, ',') {
+			forwarded.Forwarded = append(forwarded.Forwarded, parseForwardedElement(element))
+		}
+	}
+
+	return forwarded
+}
+
+// parseForwardedElement parses a list of semicolon separated forwarded-pairs
+func parseForwardedElement(element string) ForwardedElement {
+	var fe ForwardedElement
+
+	for _, pair := range splitQuoted(element, ';') {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		value := strings.Trim(kv[1], `"`)
+
+		switch strings.ToLower(kv[0]) {
+		case "for":
+			fe.For = value
+		case "by":
+			fe.By = value
+		case "host":
+			fe.Host = value
+		case "proto":
+			fe.Proto = value
+		}
+	}
+
+	return fe
+}
+
+// splitQuoted splits s on sep, ignoring separators inside quoted strings
+func splitQuoted(s string, sep rune) []string {
+	var parts []string
+	var quoted bool
+
+	start := 0
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// lastListValue returns the last element of a list of comma separated header values
+func lastListValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	elements := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(elements[len(elements)-1])
+}
@@ -17,25 +17,68 @@ limitations under the License.
 package http
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/http/httputil"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
 )
 
+// trailersRequestBody is the body of the requests sent with trailers
+const trailersRequestBody = "conformance"
+
 var (
 	// HTTPClientTimeout specifies a time limit for requests made by a client
 	HTTPClientTimeout = 10 * time.Second
-	// EnableDebug enable dump of requests and responses of HTTP requests (useful for debug)
+	// EnableDebug enable dump of requests and responses of HTTP requests (useful for debug).
+	// The dumps are also enabled with a log verbosity of DebugLogLevel or higher.
 	EnableDebug = false
+
+	// HTTPPort port used to send plain HTTP requests when a port is not specified
+	HTTPPort = 80
+	// HTTPSPort port used to send HTTPS requests when a port is not specified
+	HTTPSPort = 443
+
+	// SourceAddress local IP address or network interface name used to send requests.
+	// When empty, the operating system chooses the source address.
+	SourceAddress = ""
+
+	// ProxyProtocolVersion PROXY protocol header version (1 or 2) sent at the beginning of
+	// each connection. Zero disables the PROXY protocol.
+	ProxyProtocolVersion = 0
+
+	// IPFamily address family used to connect to the ingress controller
+	IPFamily = IPFamilyDual
+)
+
+const (
+	// IPFamilyIPv4 only connects using IPv4 addresses
+	IPFamilyIPv4 = "ipv4"
+	// IPFamilyIPv6 only connects using IPv6 addresses
+	IPFamilyIPv6 = "ipv6"
+	// IPFamilyDual connects using any address family
+	IPFamilyDual = "dual"
 )
 
+// IPFamilies contains the valid values of IPFamily
+var IPFamilies = []string{IPFamilyIPv4, IPFamilyIPv6, IPFamilyDual}
+
 // CapturedRequest contains the original HTTP request metadata as received
 // by the echoserver handling the test request.
 type CapturedRequest struct {
@@ -45,10 +88,87 @@ type CapturedRequest struct {
 	Proto   string              `json:"proto"`
 	Headers map[string][]string `json:"headers"`
 
+	// Protocol of the echoserver listener that received the request: http, https, h2c or grpc
+	Protocol string `json:"protocol"`
+
+	// RequestLine, RawPath and RawQuery are the request as received by the echoserver, before it is decoded or normalized
+	RequestLine string `json:"requestLine"`
+	RawPath     string `json:"rawPath"`
+	RawQuery    string `json:"rawQuery"`
+
+	// Nonce is a random value unique to each echo response, repeated only by responses served from a cache
+	Nonce string `json:"nonce"`
+
 	Namespace string `json:"namespace"`
 	Ingress   string `json:"ingress"`
 	Service   string `json:"service"`
 	Pod       string `json:"pod"`
+	PodIP     string `json:"podIP,omitempty"`
+	Node      string `json:"node,omitempty"`
+	// Zone is the topology.kubernetes.io/zone label of the pod, copied from its node by the cluster, if any
+	Zone string `json:"zone,omitempty"`
+
+	// TLS contains the TLS connection received by the echoserver, when the request is re-encrypted to its HTTPS listener
+	TLS *BackendTLS `json:"tls,omitempty"`
+
+	ProxyProtocol *ProxyProtocolHeader `json:"proxyProtocol,omitempty"`
+
+	// Connection contains the counters of the connection between the ingress controller and the echoserver
+	Connection *BackendConnection `json:"connection,omitempty"`
+
+	// Trailers received by the echoserver after the request body, only reported by its /trailers path
+	Trailers map[string][]string `json:"trailers,omitempty"`
+
+	// BodySize is the size of the request body received by the echoserver
+	BodySize int64 `json:"bodySize"`
+}
+
+// BackendConnection contains the counters of a connection received by the echoserver
+type BackendConnection struct {
+	// ID sequence number of the connection in the echoserver pod
+	ID uint64 `json:"id"`
+	// Requests number of requests received in the connection, including this one
+	Requests uint64 `json:"requests"`
+	// Accepted number of connections accepted by the echoserver pod
+	Accepted uint64 `json:"accepted"`
+}
+
+// BackendTLS contains the TLS connection received by the echoserver
+type BackendTLS struct {
+	Version            string `json:"version"`
+	ServerName         string `json:"serverName"`
+	NegotiatedProtocol string `json:"negotiatedProtocol,omitempty"`
+	CipherSuite        string `json:"cipherSuite"`
+
+	// ClientCertificate contains the identity of the certificate presented by the ingress controller, if any
+	ClientCertificate *ClientCertificate `json:"clientCertificate,omitempty"`
+}
+
+// ClientCertificate contains the identity of a client certificate received by the echoserver
+type ClientCertificate struct {
+	Subject    string   `json:"subject"`
+	CommonName string   `json:"commonName"`
+	DNSNames   []string `json:"dnsNames,omitempty"`
+	Issuer     string   `json:"issuer"`
+	// Verified is true when the echoserver verified the certificate with its client CA certificates
+	Verified bool `json:"verified"`
+}
+
+// PodIdentity returns the name of the pod that served the request, with its IP address when it is known,
+// so a pod replaced by another pod with the same name is not mistaken for it
+func (r *CapturedRequest) PodIdentity() string {
+	if r.Pod == "" || r.PodIP == "" {
+		return r.Pod
+	}
+
+	return fmt.Sprintf("%v (%v)", r.Pod, r.PodIP)
+}
+
+// ProxyProtocolHeader contains the PROXY protocol header received by the echoserver
+type ProxyProtocolHeader struct {
+	Version            int    `json:"version"`
+	SourceAddress      string `json:"sourceAddress"`
+	DestinationAddress string `json:"destinationAddress"`
 }
 
 // CapturedResponse contains the HTTP response metadata from the echoserver.
@@ -59,66 +179,272 @@ type CapturedResponse struct {
 	Headers       map[string][]string
 	TLSHostname   string
 
+	// RemoteAddress is the address of the ingress controller used by the connection
+	RemoteAddress string
+	// ConnectionReused is true when the round trip was sent over a connection of a previous round trip
+	ConnectionReused bool
+
+	// ContentEncoding is the encoding of the response body, which is decoded before it is read
+	ContentEncoding string
+	// BodySize is the size of the response body received, before it is decoded
+	BodySize int64
+	// DecodedBodySize is the size of the response body after it is decoded
+	DecodedBodySize int64
+	// Body is the response body, after it is decoded
+	Body []byte
+
 	Certificate *x509.Certificate
+	// CertificateChain contains the certificates presented by the server, leaf certificate first
+	CertificateChain []*x509.Certificate
+
+	// Trailers contains the trailers received after the response body
+	Trailers map[string][]string
+
+	Timings Timings
 }
 
-// CaptureRoundTrip will perform an HTTP request and return the CapturedRequest and CapturedResponse tuple
-func CaptureRoundTrip(method, scheme, hostname, path, location string) (*CapturedRequest, *CapturedResponse, error) {
-	var capturedTLSHostname string
-	var certificate *x509.Certificate
+// CapturedRoundTrip contains the CapturedRequest and CapturedResponse of a single HTTP round trip
+type CapturedRoundTrip struct {
+	Request  *CapturedRequest
+	Response *CapturedResponse
+}
 
-	tr := &http.Transport{
-		DisableCompression: true,
-		TLSClientConfig: &tls.Config{
-			// Skip all usual TLS verifications, since we are using self-signed certificates.
-			InsecureSkipVerify: true,
-			VerifyPeerCertificate: func(certificates [][]byte, _ [][]*x509.Certificate) error {
-				certs := make([]*x509.Certificate, len(certificates))
-				for i, asn1Data := range certificates {
-					cert, err := x509.ParseCertificate(asn1Data)
-					if err != nil {
-						return fmt.Errorf("tls: failed to parse certificate from server: " + err.Error())
-					}
-					certs[i] = cert
-				}
-
-				capturedTLSHostname = certs[0].DNSNames[0]
-				certificate = certs[0]
-				return nil
-			},
-		},
+// Timings contains the duration of each phase of an HTTP round trip.
+// Phases that did not happen, like DNS lookups for IP addresses or
+// TLS handshakes for plain HTTP, have a zero duration.
+type Timings struct {
+	DNSLookup    time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// TimeToFirstByte is the time between sending the request and receiving the first response byte
+	TimeToFirstByte time.Duration
+	Total           time.Duration
+}
+
+func (t Timings) String() string {
+	return fmt.Sprintf("dns=%v connect=%v tls=%v ttfb=%v total=%v",
+		t.DNSLookup, t.Connect, t.TLSHandshake, t.TimeToFirstByte, t.Total)
+}
+
+// RoundTripOption configures optional behavior of an HTTP round trip
+type RoundTripOption func(*roundTripOptions)
+
+type roundTripOptions struct {
+	cookieJar     http.CookieJar
+	headers       http.Header
+	port          int
+	sourceAddress string
+	proxyProtocol int
+	ipFamily      string
+	addresses     map[string]string
+	serverName    string
+	noRedirects   bool
+	trailers      http.Header
+	body          []byte
+
+	connectionPool  *ConnectionPool
+	freshConnection bool
+	certificates    string
+}
+
+// WithCookieJar sends the cookies stored in the jar for the request hostname
+// and stores the cookies set by the response in it.
+func WithCookieJar(jar http.CookieJar) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.cookieJar = jar
 	}
+}
 
-	if scheme == "https" && hostname != "" {
-		tr.TLSClientConfig.ServerName = hostname
+// WithHeaders adds the headers to the request. A Host header replaces the request hostname.
+func WithHeaders(headers http.Header) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.headers = headers
+	}
+}
+
+// WithPort sends the request to a port different than HTTPPort or HTTPSPort
+func WithPort(port int) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.port = port
+	}
+}
+
+// WithSourceAddress sends the request from a local IP address or network interface name instead of SourceAddress
+func WithSourceAddress(address string) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.sourceAddress = address
+	}
+}
+
+// WithProxyProtocol sends a PROXY protocol header of the given version instead of using ProxyProtocolVersion
+func WithProxyProtocol(version int) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.proxyProtocol = version
+	}
+}
+
+// WithIPFamily connects using the given address family instead of IPFamily
+func WithIPFamily(family string) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.ipFamily = family
+	}
+}
+
+// WithAddresses connects to the address (IP or FQDN) of a hostname instead of resolving it, like an
+// entry in /etc/hosts. The address of the empty hostname is used for any hostname without an address
+// and for requests without hostname.
+func WithAddresses(addresses map[string]string) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.addresses = addresses
+	}
+}
+
+// WithServerName sends the name in the TLS handshake (SNI) instead of the request hostname
+func WithServerName(name string) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.serverName = name
+	}
+}
+
+// WithoutRedirects returns the redirect responses instead of following them
+func WithoutRedirects() RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.noRedirects = true
+	}
+}
+
+// WithTrailers sends the request with a chunked body followed by the trailers
+func WithTrailers(trailers http.Header) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.trailers = trailers
+	}
+}
+
+// WithBody sends the body in the request, with its Content-Length unless trailers are sent
+func WithBody(body []byte) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.body = body
+	}
+}
+
+// newRoundTripOptions applies the options to the default round trip options
+func newRoundTripOptions(opts []RoundTripOption) *roundTripOptions {
+	options := &roundTripOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// address returns the address used to connect to a hostname
+func (o *roundTripOptions) address(hostname string) string {
+	if address, ok := o.addresses[hostname]; ok {
+		return address
+	}
+
+	if address, ok := o.addresses[""]; ok {
+		return address
+	}
+
+	return hostname
+}
+
+// CaptureRoundTrip will perform an HTTP request and return the CapturedRequest and CapturedResponse tuple.
+// The request is cancelled when the context is done.
+func CaptureRoundTrip(ctx context.Context, method, scheme, hostname, path string, opts ...RoundTripOption) (*CapturedRequest, *CapturedResponse, error) {
+	ctx, span := tracing.StartClient(ctx, fmt.Sprintf("HTTP %v", method),
+		"http.method", method, "http.scheme", scheme, "http.host", hostname, "http.target", path)
+
+	capturedRequest, capturedResponse, err := captureRoundTrip(ctx, method, scheme, hostname, path, opts...)
+	if capturedResponse != nil {
+		span.SetAttributes("http.status_code", capturedResponse.StatusCode, "net.peer.address", capturedResponse.RemoteAddress)
+	}
+
+	span.End(err)
+
+	return capturedRequest, capturedResponse, err
+}
+
+// captureRoundTrip performs the HTTP request of CaptureRoundTrip
+func captureRoundTrip(ctx context.Context, method, scheme, hostname, path string, opts ...RoundTripOption) (*CapturedRequest, *CapturedResponse, error) {
+	options := newRoundTripOptions(opts)
+
+	tlsState := &tlsState{}
+
+	pool := options.pool()
+
+	var transport *http.Transport
+	var err error
+	if pool != nil {
+		transport, err = pool.transport(scheme, hostname, options)
+	} else {
+		transport, err = newTransport(scheme, hostname, tlsState, options)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
 	client := &http.Client{
-		Transport: tr,
+		Transport: transport,
 		Timeout:   HTTPClientTimeout,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 
-	url := fmt.Sprintf("%s://%s/%s", scheme, location, strings.TrimPrefix(path, "/"))
+	var requestBody io.Reader
+	if options.body != nil {
+		requestBody = bytes.NewReader(options.body)
+	}
 
-	req, err := http.NewRequest(method, url, nil)
+	req, err := http.NewRequestWithContext(ctx, method, requestURL(scheme, hostname, path, options), requestBody)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if hostname != "" {
-		req.Host = hostname
+	for key, values := range options.headers {
+		if http.CanonicalHeaderKey(key) == "Host" {
+			req.Host = values[0]
+			continue
+		}
+
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	// trailers are only sent after a chunked body, of unknown length
+	if len(options.trailers) != 0 {
+		if req.Body == nil {
+			req.Body = ioutil.NopCloser(strings.NewReader(trailersRequestBody))
+		}
+
+		req.ContentLength = -1
+		req.Trailer = options.trailers.Clone()
+	}
+
+	var cookieURL *url.URL
+	if options.cookieJar != nil {
+		cookieURL = req.URL
+
+		for _, cookie := range options.cookieJar.Cookies(cookieURL) {
+			req.AddCookie(cookie)
+		}
 	}
 
-	if EnableDebug {
+	var timings Timings
+	var connection connectionInfo
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), newClientTrace(start, &timings, &connection)))
+
+	if debugEnabled() {
 		dump, err := httputil.DumpRequestOut(req, true)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		fmt.Printf("Sending request:\n%s\n\n", formatDump(dump, "> "))
+		klog.Infof("Sending request:\n%s\n", formatDump(dump, "> "))
 	}
 
 	resp, err := client.Do(req)
@@ -127,29 +453,54 @@ func CaptureRoundTrip(method, scheme, hostname, path, location string) (*Capture
 	}
 	defer resp.Body.Close()
 
-	if EnableDebug {
+	if options.cookieJar != nil {
+		options.cookieJar.SetCookies(cookieURL, resp.Cookies())
+	}
+
+	if pool != nil {
+		tlsState.connectionCertificates(resp.TLS)
+	}
+
+	if debugEnabled() {
 		dump, err := httputil.DumpResponse(resp, true)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		fmt.Printf("Received response:\n%s\n\n", formatDump(dump, "< "))
+		klog.Infof("Received response:\n%s\n", formatDump(dump, "< "))
 	}
 
 	// check if the result is a redirect and return a new request
 	// this avoids the issue of URLs without valid DNS names and
 	// also sends the traffic to the ingress controller IP address or FQDN
-	if isRedirect(resp.StatusCode) {
+	if isRedirect(resp.StatusCode) && !options.noRedirects {
 		redirectURL, err := resp.Location()
 		if err != nil {
 			return nil, nil, err
 		}
 
-		return CaptureRoundTrip(method, redirectURL.Scheme, redirectURL.Hostname(), redirectURL.Path, location)
+		// the port of the original request is only kept for the same scheme
+		if redirectURL.Scheme != scheme || redirectURL.Port() != "" {
+			redirectPort, _ := strconv.Atoi(redirectURL.Port())
+			opts = append(opts[:len(opts):len(opts)], WithPort(redirectPort))
+		}
+
+		return CaptureRoundTrip(ctx, method, redirectURL.Scheme, redirectURL.Hostname(), redirectURL.RequestURI(), opts...)
 	}
 
 	capReq := CapturedRequest{}
-	body, _ := ioutil.ReadAll(resp.Body)
+	rawBody, _ := ioutil.ReadAll(resp.Body)
+	timings.Total = time.Since(start)
+
+	if debugEnabled() {
+		klog.InfoS("Round trip timings", "timings", timings)
+	}
+
+	// the transport does not decode the body, since its compression is disabled
+	body, err := decodeBody(resp.Header.Get("Content-Encoding"), rawBody)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// we cannot assume the response is JSON
 	if isJSON(body) {
@@ -160,17 +511,303 @@ func CaptureRoundTrip(method, scheme, hostname, path, location string) (*Capture
 	}
 
 	capRes := &CapturedResponse{
-		resp.StatusCode,
-		resp.ContentLength,
-		resp.Proto,
-		resp.Header,
-		capturedTLSHostname,
-		certificate,
+		StatusCode:       resp.StatusCode,
+		ContentLength:    resp.ContentLength,
+		Proto:            resp.Proto,
+		Headers:          resp.Header,
+		TLSHostname:      tlsState.hostname,
+		RemoteAddress:    connection.remoteAddress,
+		ConnectionReused: connection.reused,
+		ContentEncoding:  resp.Header.Get("Content-Encoding"),
+		BodySize:         int64(len(rawBody)),
+		DecodedBodySize:  int64(len(body)),
+		Body:             body,
+		Certificate:      tlsState.certificate,
+		CertificateChain: tlsState.chain,
+		Trailers:         resp.Trailer,
+		Timings:          timings,
 	}
 
 	return &capReq, capRes, nil
 }
 
+// requestURL returns the URL of a request to the hostname. Requests without
+// hostname are sent to the address configured for the empty hostname.
+func requestURL(scheme, hostname, path string, options *roundTripOptions) string {
+	if hostname == "" {
+		hostname = options.address("")
+	}
+
+	port := targetPort(scheme, options.port)
+	return fmt.Sprintf("%s://%s/%s", scheme, hostPort(scheme, hostname, port), strings.TrimPrefix(path, "/"))
+}
+
+// targetPort returns the port to use for a request with the given
+// scheme, using HTTPPort or HTTPSPort if port is zero
+func targetPort(scheme string, port int) int {
+	if port != 0 {
+		return port
+	}
+
+	if scheme == "https" {
+		return HTTPSPort
+	}
+
+	return HTTPPort
+}
+
+// hostPort joins host and port, omitting the port if it is the well-known port of the scheme.
+// Hosts that already contain a port are returned unchanged.
+func hostPort(scheme, host string, port int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		// IPv6 literals must be enclosed in square brackets
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// tlsState holds information about the certificates presented by the server
+type tlsState struct {
+	hostname    string
+	certificate *x509.Certificate
+	chain       []*x509.Certificate
+}
+
+// verifyPeerCertificate stores the certificates presented by the server without verifying them
+func (state *tlsState) verifyPeerCertificate(certificates [][]byte, _ [][]*x509.Certificate) error {
+	certs := make([]*x509.Certificate, len(certificates))
+	for i, asn1Data := range certificates {
+		cert, err := x509.ParseCertificate(asn1Data)
+		if err != nil {
+			return fmt.Errorf("tls: failed to parse certificate from server: " + err.Error())
+		}
+		certs[i] = cert
+	}
+
+	// certificates without subject alternative names have no hostname
+	if len(certs[0].DNSNames) > 0 {
+		state.hostname = certs[0].DNSNames[0]
+	}
+
+	state.certificate = certs[0]
+	state.chain = certs
+	return nil
+}
+
+// newTransport returns an HTTP transport that skips the usual TLS verifications, storing the certificate
+// presented by the server in the provided tlsState, if any. Transports with a tlsState are used for a
+// single round trip, so their connections are not kept open.
+func newTransport(scheme, hostname string, state *tlsState, options *roundTripOptions) (*http.Transport, error) {
+	dialContext, err := newDialContext(options)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := &http.Transport{
+		DialContext:         dialContext,
+		DisableCompression:  true,
+		DisableKeepAlives:   state != nil,
+		MaxIdleConnsPerHost: MaxIdleConnsPerHost,
+		IdleConnTimeout:     IdleConnTimeout,
+		TLSHandshakeTimeout: TLSHandshakeTimeout,
+		TLSClientConfig: &tls.Config{
+			// Skip all usual TLS verifications, since we are using self-signed certificates.
+			InsecureSkipVerify: true,
+		},
+	}
+
+	if state != nil {
+		tr.TLSClientConfig.VerifyPeerCertificate = state.verifyPeerCertificate
+	}
+
+	if scheme == "https" && hostname != "" {
+		tr.TLSClientConfig.ServerName = hostname
+	}
+
+	if scheme == "https" && options.serverName != "" {
+		tr.TLSClientConfig.ServerName = options.serverName
+	}
+
+	return tr, nil
+}
+
+// newDialContext returns a dial function bound to the configured source address
+// and address family that sends the configured PROXY protocol header
+func newDialContext(options *roundTripOptions) (dialContextFunc, error) {
+	family := IPFamily
+	if options.ipFamily != "" {
+		family = options.ipFamily
+	}
+
+	dialer, err := newDialer(options, family)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := withAddresses(withIPFamily(dialer.DialContext, family), options)
+
+	version := ProxyProtocolVersion
+	if options.proxyProtocol != 0 {
+		version = options.proxyProtocol
+	}
+
+	if version == 0 {
+		return dial, nil
+	}
+
+	return withProxyProtocol(dial, version), nil
+}
+
+// withAddresses returns a dial function that connects to the configured address of each hostname
+func withAddresses(dial dialContextFunc, options *roundTripOptions) dialContextFunc {
+	if len(options.addresses) == 0 {
+		return dial
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		return dial(ctx, network, net.JoinHostPort(options.address(host), port))
+	}
+}
+
+// withIPFamily returns a dial function that only connects to addresses of the given family.
+// Hostnames are resolved using only the records of the family (A or AAAA).
+func withIPFamily(dial dialContextFunc, family string) dialContextFunc {
+	var suffix string
+	switch family {
+	case IPFamilyIPv4:
+		suffix = "4"
+	case IPFamilyIPv6:
+		suffix = "6"
+	default:
+		return dial
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dial(ctx, strings.TrimRight(network, "46")+suffix, address)
+	}
+}
+
+// newDialer returns a dialer bound to the configured source address
+func newDialer(options *roundTripOptions, family string) (*net.Dialer, error) {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	address := SourceAddress
+	if options.sourceAddress != "" {
+		address = options.sourceAddress
+	}
+
+	if address == "" {
+		return dialer, nil
+	}
+
+	ip, err := resolveSourceAddress(address, family)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	return dialer, nil
+}
+
+// resolveSourceAddress returns the IP address of a source address, which can be
+// an IP address or the name of a network interface. For interfaces, the first
+// address of the family that is not link-local is used.
+func resolveSourceAddress(address, family string) (net.IP, error) {
+	if ip := net.ParseIP(address); ip != nil {
+		return ip, nil
+	}
+
+	iface, err := net.InterfaceByName(address)
+	if err != nil {
+		return nil, fmt.Errorf("source address %v is not an IP address or network interface: %w", address, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("reading addresses of network interface %v: %w", address, err)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() || !IsIPFamily(ipNet.IP, family) {
+			continue
+		}
+
+		return ipNet.IP, nil
+	}
+
+	return nil, fmt.Errorf("network interface %v does not have a usable IP address", address)
+}
+
+// IsIPFamily returns true if the IP address belongs to the address family
+func IsIPFamily(ip net.IP, family string) bool {
+	switch family {
+	case IPFamilyIPv4:
+		return ip.To4() != nil
+	case IPFamilyIPv6:
+		return ip.To4() == nil && ip.To16() != nil
+	}
+
+	return true
+}
+
+// connectionInfo contains the connection used by a round trip
+type connectionInfo struct {
+	remoteAddress string
+	reused        bool
+}
+
+// newClientTrace returns a ClientTrace that records the duration of each phase of the round
+// trip in timings and the address of the server used by the connection, and if it was reused.
+func newClientTrace(start time.Time, timings *Timings, connection *connectionInfo) *httptrace.ClientTrace {
+	var dnsStart, connectStart, tlsStart time.Time
+
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			connection.remoteAddress = info.Conn.RemoteAddr().String()
+			connection.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			timings.DNSLookup = time.Since(dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			timings.Connect = time.Since(connectStart)
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			timings.TLSHandshake = time.Since(tlsStart)
+		},
+		GotFirstResponseByte: func() {
+			timings.TimeToFirstByte = time.Since(start)
+		},
+	}
+}
+
 func isJSON(content []byte) bool {
 	var js map[string]interface{}
 	return json.Unmarshal(content, &js) == nil
@@ -189,6 +826,14 @@ func isRedirect(statusCode int) bool {
 	return false
 }
 
+// DebugLogLevel log verbosity level that enables the dumps of requests and responses
+const DebugLogLevel klog.Level = 4
+
+// debugEnabled returns true when requests and responses must be dumped
+func debugEnabled() bool {
+	return EnableDebug || klog.V(DebugLogLevel).Enabled()
+}
+
 var startLineRegex = regexp.MustCompile(`(?m)^`)
 
 func formatDump(data []byte, prefix string) string {
@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadInterval time between the requests sent by a LoadGenerator
+var LoadInterval = 100 * time.Millisecond
+
+// LoadResult contains the outcome of the requests sent by a LoadGenerator
+type LoadResult struct {
+	// Requests number of requests sent
+	Requests int
+	// Errors number of requests that failed or returned a 5xx status code
+	Errors int
+	// StatusCodes number of responses by status code
+	StatusCodes map[int]int
+	// LastError error of the last request that failed
+	LastError error
+}
+
+// ErrorRate returns the fraction of the requests that failed or returned a 5xx status code
+func (r *LoadResult) ErrorRate() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Errors) / float64(r.Requests)
+}
+
+// String returns a summary of the requests and their status codes
+func (r *LoadResult) String() string {
+	codes := make([]int, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	counts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		counts = append(counts, fmt.Sprintf("%v: %v", code, r.StatusCodes[code]))
+	}
+
+	summary := fmt.Sprintf("%v requests, %v errors (%.2f%%), status codes {%v}",
+		r.Requests, r.Errors, 100*r.ErrorRate(), strings.Join(counts, ", "))
+	if r.LastError != nil {
+		summary += fmt.Sprintf(", last error: %v", r.LastError)
+	}
+
+	return summary
+}
+
+// LoadGenerator sends requests in the background, one every LoadInterval, until it is stopped
+type LoadGenerator struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	result LoadResult
+}
+
+// StartLoad starts sending requests in the background. The requests stop when
+// the context is done or the LoadGenerator is stopped.
+func StartLoad(ctx context.Context, method, scheme, hostname, path string, opts ...RoundTripOption) *LoadGenerator {
+	ctx, cancel := context.WithCancel(ctx)
+
+	g := &LoadGenerator{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		result: LoadResult{StatusCodes: map[int]int{}},
+	}
+
+	go func() {
+		defer close(g.done)
+
+		ticker := time.NewTicker(LoadInterval)
+		defer ticker.Stop()
+
+		for {
+			// requests are not traced, a span per request would flood the trace of the scenario
+			_, capturedResponse, err := captureRoundTrip(ctx, method, scheme, hostname, path, opts...)
+			if ctx.Err() != nil {
+				// the request was cancelled by Stop
+				return
+			}
+
+			g.record(capturedResponse, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return g
+}
+
+func (g *LoadGenerator) record(capturedResponse *CapturedResponse, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.result.Requests++
+
+	switch {
+	case err != nil:
+		g.result.Errors++
+		g.result.LastError = err
+	case capturedResponse.StatusCode >= 500:
+		g.result.Errors++
+		g.result.StatusCodes[capturedResponse.StatusCode]++
+	default:
+		g.result.StatusCodes[capturedResponse.StatusCode]++
+	}
+}
+
+// Stop stops sending requests, waits for the request in flight and returns the result of the requests sent
+func (g *LoadGenerator) Stop() *LoadResult {
+	g.cancel()
+	<-g.done
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := g.result
+	return &result
+}
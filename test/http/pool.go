@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ReuseConnections sends the round trips without WithConnectionPool or WithFreshConnection
+	// using the connections of SharedPool. When false, each round trip opens a new connection.
+	ReuseConnections = true
+	// SharedPool keeps the connections of the round trips of the suite open, shared by the scenarios.
+	// The TLS connections are only reused by the round trips expecting the same certificates.
+	SharedPool = NewConnectionPool()
+
+	// MaxIdleConnsPerHost maximum number of idle connections kept open per host by a ConnectionPool
+	MaxIdleConnsPerHost = 4
+	// IdleConnTimeout maximum time an idle connection is kept open by a ConnectionPool
+	IdleConnTimeout = 30 * time.Second
+	// TLSHandshakeTimeout maximum duration of a TLS handshake
+	TLSHandshakeTimeout = 10 * time.Second
+)
+
+// ConnectionPool keeps the connections of the round trips sent with WithConnectionPool open,
+// so the following round trips to the same hostname can reuse them (HTTP keep-alive).
+type ConnectionPool struct {
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+	// certificates contains the keys of the transports of the round trips sent WithCertificates
+	certificates map[string][]string
+}
+
+// NewConnectionPool returns an empty ConnectionPool
+func NewConnectionPool() *ConnectionPool {
+	return &ConnectionPool{
+		transports:   map[string]*http.Transport{},
+		certificates: map[string][]string{},
+	}
+}
+
+// WithConnectionPool reuses the idle connections of the pool instead of opening a new connection
+func WithConnectionPool(pool *ConnectionPool) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.connectionPool = pool
+	}
+}
+
+// WithFreshConnection opens a new connection for the round trip, closed after it, instead of reusing
+// the connections of SharedPool, to test the behavior of the first request of a connection
+func WithFreshConnection() RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.freshConnection = true
+	}
+}
+
+// WithCertificates identifies the certificates expected in the TLS handshakes of the round trip, like the
+// namespace and name of the TLS secret of the scenario. The TLS connections opened by the round trips expecting
+// other certificates, and the certificates of their handshakes, are not reused.
+func WithCertificates(certificates string) RoundTripOption {
+	return func(o *roundTripOptions) {
+		o.certificates = certificates
+	}
+}
+
+// pool returns the ConnectionPool of a round trip, nil when it uses a fresh connection
+func (o *roundTripOptions) pool() *ConnectionPool {
+	switch {
+	case o.connectionPool != nil:
+		return o.connectionPool
+	case o.freshConnection || !ReuseConnections:
+		return nil
+	}
+
+	return SharedPool
+}
+
+// transport returns the transport of the scheme, hostname and options that change how the connections
+// are opened. Its certificates are read from the state of each connection.
+func (p *ConnectionPool) transport(scheme, hostname string, options *roundTripOptions) (*http.Transport, error) {
+	key := transportKey(scheme, hostname, options)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if transport, ok := p.transports[key]; ok {
+		return transport, nil
+	}
+
+	transport, err := newTransport(scheme, hostname, nil, options)
+	if err != nil {
+		return nil, err
+	}
+
+	p.transports[key] = transport
+	if scheme == "https" && options.certificates != "" {
+		p.certificates[options.certificates] = append(p.certificates[options.certificates], key)
+	}
+
+	return transport, nil
+}
+
+// transportKey identifies the transports of the round trips opening the same connections
+func transportKey(scheme, hostname string, options *roundTripOptions) string {
+	var addresses []string
+	for host, address := range options.addresses {
+		addresses = append(addresses, host+"="+address)
+	}
+
+	sort.Strings(addresses)
+
+	key := fmt.Sprintf("%v://%v/%v/%v/%v/%v/%v", scheme, hostname, options.serverName, options.sourceAddress,
+		options.proxyProtocol, options.ipFamily, strings.Join(addresses, ","))
+	if scheme == "https" {
+		key += "/" + options.certificates
+	}
+
+	return key
+}
+
+// Close closes the idle connections of the pool
+func (p *ConnectionPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, transport := range p.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// Release closes the idle connections of the round trips sent WithCertificates and forgets their transports,
+// once no round trip expects the certificates anymore. The other connections of the pool are kept open.
+func (p *ConnectionPool) Release(certificates string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range p.certificates[certificates] {
+		p.transports[key].CloseIdleConnections()
+		delete(p.transports, key)
+	}
+
+	delete(p.certificates, certificates)
+}
+
+// connectionCertificates stores the certificates of a TLS connection in the state
+func (state *tlsState) connectionCertificates(connectionState *tls.ConnectionState) {
+	if connectionState == nil || len(connectionState.PeerCertificates) == 0 {
+		return
+	}
+
+	certificates := make([][]byte, len(connectionState.PeerCertificates))
+	for i, certificate := range connectionState.PeerCertificates {
+		certificates[i] = certificate.Raw
+	}
+
+	_ = state.verifyPeerCertificate(certificates, nil)
+}
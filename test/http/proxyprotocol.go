@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// proxyProtocolV2Signature is the fixed prefix of a PROXY protocol version 2 header
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// dialContextFunc is the signature of net.Dialer.DialContext
+type dialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// withProxyProtocol returns a dial function that sends a PROXY protocol header of
+// the given version (1 or 2) as soon as the connection is established, describing
+// the connection local and remote addresses.
+func withProxyProtocol(dial dialContextFunc, version int) dialContextFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := proxyProtocolHeader(version, conn.LocalAddr(), conn.RemoteAddr())
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if _, err := conn.Write(header); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("writing PROXY protocol header: %w", err)
+		}
+
+		return conn, nil
+	}
+}
+
+// proxyProtocolHeader returns the PROXY protocol header for a TCP connection from source to destination
+func proxyProtocolHeader(version int, source, destination net.Addr) ([]byte, error) {
+	src, ok := source.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("PROXY protocol requires a TCP connection (%v)", source)
+	}
+
+	dst, ok := destination.(*net.TCPAddr)
+	if !ok {
+		return nil, fmt.Errorf("PROXY protocol requires a TCP connection (%v)", destination)
+	}
+
+	srcIP, dstIP := src.IP.To4(), dst.IP.To4()
+	family := "TCP4"
+	if srcIP == nil || dstIP == nil {
+		srcIP, dstIP = src.IP.To16(), dst.IP.To16()
+		family = "TCP6"
+	}
+
+	switch version {
+	case 1:
+		return []byte(fmt.Sprintf("PROXY %v %v %v %v %v\r\n", family, srcIP, dstIP, src.Port, dst.Port)), nil
+	case 2:
+		var buf bytes.Buffer
+		buf.Write(proxyProtocolV2Signature)
+		// version 2, PROXY command
+		buf.WriteByte(0x21)
+
+		if family == "TCP4" {
+			// AF_INET, STREAM
+			buf.WriteByte(0x11)
+		} else {
+			// AF_INET6, STREAM
+			buf.WriteByte(0x21)
+		}
+
+		binary.Write(&buf, binary.BigEndian, uint16(2*len(srcIP)+4))
+		buf.Write(srcIP)
+		buf.Write(dstIP)
+		binary.Write(&buf, binary.BigEndian, uint16(src.Port))
+		binary.Write(&buf, binary.BigEndian, uint16(dst.Port))
+
+		return buf.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported PROXY protocol version %v (valid values are 1 and 2)", version)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// RawTimeout specifies a time limit for raw TCP and TLS exchanges
+var RawTimeout = 10 * time.Second
+
+// CapturedConnection contains the result of a raw exchange over a TCP or TLS connection.
+type CapturedConnection struct {
+	RemoteAddress string
+
+	// Lines contains the lines received from the server, without line terminators
+	Lines []string
+
+	// TLS information, only available for TLS connections
+	TLSHostname        string
+	Certificate        *x509.Certificate
+	NegotiatedProtocol string
+}
+
+// StatusCode returns the status code of the HTTP response received in the exchange,
+// or 0 when the first line received is not an HTTP status line
+func (c *CapturedConnection) StatusCode() int {
+	if len(c.Lines) == 0 {
+		return 0
+	}
+
+	fields := strings.Fields(c.Lines[0])
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return 0
+	}
+
+	statusCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0
+	}
+
+	return statusCode
+}
+
+// CaptureRawRoundTrip opens a connection to the port of the hostname, writes the payload and reads
+// the response lines until the server closes the connection, maxLines lines are received, RawTimeout
+// expires or the context is done. Network must be "tcp" or "tls"; TLS connections use the hostname for
+// SNI and do not verify the certificate presented by the server, which is available in the CapturedConnection.
+func CaptureRawRoundTrip(ctx context.Context, network, hostname string, port int, payload []byte, maxLines int, opts ...RoundTripOption) (*CapturedConnection, error) {
+	if network != "tcp" && network != "tls" {
+		return nil, fmt.Errorf("unsupported network %v (valid values are tcp and tls)", network)
+	}
+
+	options := newRoundTripOptions(opts)
+
+	dialContext, err := newDialContext(options)
+	if err != nil {
+		return nil, err
+	}
+
+	host := hostname
+	if host == "" {
+		host = options.address("")
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	deadline := time.Now().Add(RawTimeout)
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	conn, err := dialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// interrupt the exchange when the context is cancelled
+	done := make(chan struct{})
+	defer close(done)
+
+	go func(conn net.Conn) {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}(conn)
+
+	captured := &CapturedConnection{}
+
+	if network == "tls" {
+		serverName := hostname
+		if options.serverName != "" {
+			serverName = options.serverName
+		}
+
+		tlsState := &tlsState{}
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:            serverName,
+			InsecureSkipVerify:    true,
+			VerifyPeerCertificate: tlsState.verifyPeerCertificate,
+		})
+
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, err
+		}
+
+		captured.TLSHostname = tlsState.hostname
+		captured.Certificate = tlsState.certificate
+		captured.NegotiatedProtocol = tlsConn.ConnectionState().NegotiatedProtocol
+
+		conn = tlsConn
+	}
+
+	captured.RemoteAddress = conn.RemoteAddr().String()
+
+	if debugEnabled() {
+		klog.Infof("Sending raw payload to %v (%v):\n%s\n", address, network, formatDump(payload, "> "))
+	}
+
+	// servers may answer and close the connection before reading a whole payload they reject,
+	// so the response is read even when the payload could not be written
+	_, writeErr := conn.Write(payload)
+
+	reader := bufio.NewReader(conn)
+	for maxLines <= 0 || len(captured.Lines) < maxLines {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			captured.Lines = append(captured.Lines, strings.TrimRight(line, "\r\n"))
+		}
+
+		if err == nil {
+			continue
+		}
+
+		var netErr net.Error
+		if errors.Is(err, io.EOF) || (errors.As(err, &netErr) && netErr.Timeout() && len(captured.Lines) != 0) {
+			break
+		}
+
+		if writeErr != nil && len(captured.Lines) == 0 {
+			return nil, fmt.Errorf("writing payload: %w", writeErr)
+		}
+
+		return captured, fmt.Errorf("reading response after %v lines: %w", len(captured.Lines), err)
+	}
+
+	if debugEnabled() {
+		klog.Infof("Received raw response:\n%s\n", formatDump([]byte(strings.Join(captured.Lines, "\n")), "< "))
+	}
+
+	return captured, nil
+}
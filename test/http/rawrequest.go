@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RawRequest is an HTTP/1.1 request written as is over a raw connection. Unlike the requests of
+// the HTTP client, its header fields are not validated nor normalized, so it can frame its body
+// ambiguously, like the requests used in request smuggling attacks.
+type RawRequest struct {
+	Method string
+	Target string
+	Host   string
+
+	// Headers are written in order after the Host header, keeping the repeated keys
+	Headers [][2]string
+
+	Body string
+}
+
+// Bytes returns the request as written on the connection
+func (r *RawRequest) Bytes() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%v %v HTTP/1.1\r\n", r.Method, r.Target)
+	fmt.Fprintf(&buf, "Host: %v\r\n", r.Host)
+	for _, header := range r.Headers {
+		fmt.Fprintf(&buf, "%v: %v\r\n", header[0], header[1])
+	}
+
+	buf.WriteString("\r\n")
+	buf.WriteString(r.Body)
+
+	return buf.Bytes()
+}
+
+// LargeHeadersRequest returns a request with count headers of size bytes each
+func LargeHeadersRequest(method, host, target string, count, size int) *RawRequest {
+	request := &RawRequest{Method: method, Target: target, Host: host}
+	for i := 0; i < count; i++ {
+		request.Headers = append(request.Headers, [2]string{fmt.Sprintf("X-Conformance-%v", i), strings.Repeat("x", size)})
+	}
+
+	request.Headers = append(request.Headers, [2]string{"Connection", "close"})
+	return request
+}
+
+// DuplicateContentLengthRequest returns a request with two Content-Length headers of different values,
+// so the end of its body, and the start of the next request in the connection, is ambiguous
+func DuplicateContentLengthRequest(method, host, target string) *RawRequest {
+	body := "conformance"
+
+	return &RawRequest{
+		Method: method,
+		Target: target,
+		Host:   host,
+		Headers: [][2]string{
+			{"Content-Length", strconv.Itoa(len(body))},
+			{"Content-Length", strconv.Itoa(len(body) - 1)},
+			{"Connection", "close"},
+		},
+		Body: body,
+	}
+}
+
+// TransferEncodingWithContentLengthRequest returns a request with a chunked body and a Content-Length
+// header that does not match it. A proxy and a backend using different headers to find the end of the
+// body disagree on where the next request in the connection starts.
+func TransferEncodingWithContentLengthRequest(method, host, target string) *RawRequest {
+	body := "0\r\n\r\nX"
+
+	return &RawRequest{
+		Method: method,
+		Target: target,
+		Host:   host,
+		Headers: [][2]string{
+			{"Content-Length", strconv.Itoa(len(body))},
+			{"Transfer-Encoding", "chunked"},
+			{"Connection", "close"},
+		},
+		Body: body,
+	}
+}
+
+// SendRawRequest writes the request over a TCP or TLS connection to the port of the hostname and reads
+// the status line of the response, available with the StatusCode method of the CapturedConnection
+func SendRawRequest(ctx context.Context, network, hostname string, port int, request *RawRequest, opts ...RoundTripOption) (*CapturedConnection, error) {
+	return CaptureRawRoundTrip(ctx, network, hostname, port, request.Bytes(), 1, opts...)
+}
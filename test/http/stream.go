@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StreamTimeout specifies a time limit for reading all the events of a streaming response
+var StreamTimeout = 30 * time.Second
+
+// CapturedEvent contains a Server-Sent Event and the time it was received by the client.
+type CapturedEvent struct {
+	ID    string
+	Event string
+	Data  string
+
+	ReceivedAt time.Time
+}
+
+// CapturedStream contains the HTTP response metadata and the events
+// read from a Server-Sent Events (text/event-stream) response.
+type CapturedStream struct {
+	Response *CapturedResponse
+	Events   []CapturedEvent
+
+	// Request contains the information about the request sent by the echoserver in
+	// its first event, named request, which is not included in the Events
+	Request *CapturedRequest
+
+	// StartedAt is the time the request was sent
+	StartedAt time.Time
+}
+
+// CaptureStream will perform an HTTP request to a Server-Sent Events endpoint and read
+// up to the number of events requested, recording the time each event was received.
+// Reading stops when the server closes the stream or the context is done, even if fewer events were received.
+func CaptureStream(ctx context.Context, method, scheme, hostname, path string, events int, opts ...RoundTripOption) (*CapturedStream, error) {
+	options := newRoundTripOptions(opts)
+	tlsState := &tlsState{}
+
+	transport, err := newTransport(scheme, hostname, tlsState, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client timeout includes reading the body, which is
+	// controlled by the StreamTimeout context instead.
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, StreamTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL(scheme, hostname, path, options), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	stream := &CapturedStream{
+		StartedAt: time.Now(),
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	stream.Response = &CapturedResponse{
+		StatusCode:    resp.StatusCode,
+		ContentLength: resp.ContentLength,
+		Proto:         resp.Proto,
+		Headers:       resp.Header,
+		TLSHostname:   tlsState.hostname,
+		Certificate:   tlsState.certificate,
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return stream, nil
+	}
+
+	var event CapturedEvent
+	var data []string
+
+	scanner := bufio.NewScanner(resp.Body)
+	for len(stream.Events) < events && scanner.Scan() {
+		line := scanner.Text()
+
+		// an empty line dispatches the event
+		if line == "" {
+			if len(data) == 0 {
+				continue
+			}
+
+			event.Data = strings.Join(data, "\n")
+			event.ReceivedAt = time.Now()
+
+			if event.Event == "request" && stream.Request == nil && isJSON([]byte(event.Data)) {
+				stream.Request = &CapturedRequest{}
+				if err := json.Unmarshal([]byte(event.Data), stream.Request); err != nil {
+					return stream, fmt.Errorf("unexpected error reading request event: %w", err)
+				}
+			} else {
+				stream.Events = append(stream.Events, event)
+			}
+
+			event = CapturedEvent{}
+			data = nil
+			continue
+		}
+
+		// lines starting with a colon are comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field := strings.SplitN(line, ":", 2)
+		value := ""
+		if len(field) == 2 {
+			value = strings.TrimPrefix(field[1], " ")
+		}
+
+		switch field[0] {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil && len(stream.Events) < events {
+		return stream, fmt.Errorf("reading event stream after %v events: %w", len(stream.Events), err)
+	}
+
+	return stream, nil
+}
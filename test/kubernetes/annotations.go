@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	networking "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+// AnnotationPrefix prefix of the abstract annotations used by the manifests of the Extended features
+// (e.g. conformance.ingress.k8s.io/rewrite-target), translated to annotations of the ingress controller
+// before the Ingresses are created or updated
+const AnnotationPrefix = "conformance.ingress.k8s.io/"
+
+// AnnotationMapping translates an abstract annotation to an annotation of the ingress controller
+type AnnotationMapping struct {
+	// Key of the annotation of the ingress controller. The abstract annotation is removed when empty,
+	// for controllers that do not need it (e.g. when the feature is enabled by other annotations)
+	Key string `json:"key"`
+	// Values translates the values of the abstract annotation. Values not listed are kept.
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// AnnotationMappings contains the translations of the abstract annotations, by name without AnnotationPrefix
+type AnnotationMappings struct {
+	Annotations map[string]AnnotationMapping `json:"annotations"`
+}
+
+// Annotations translates the abstract annotations of the Ingresses. The defaults are the annotations of
+// ingress-nginx. Other ingress controllers replace them with LoadAnnotationMappings or SetAnnotationKeys.
+var Annotations = map[string]AnnotationMapping{
+	"rewrite-target": {Key: "nginx.ingress.kubernetes.io/rewrite-target"},
+	"use-regex":      {Key: "nginx.ingress.kubernetes.io/use-regex"},
+
+	"cors-enable":            {Key: "nginx.ingress.kubernetes.io/enable-cors"},
+	"cors-allow-origin":      {Key: "nginx.ingress.kubernetes.io/cors-allow-origin"},
+	"cors-allow-methods":     {Key: "nginx.ingress.kubernetes.io/cors-allow-methods"},
+	"cors-allow-headers":     {Key: "nginx.ingress.kubernetes.io/cors-allow-headers"},
+	"cors-allow-credentials": {Key: "nginx.ingress.kubernetes.io/cors-allow-credentials"},
+	"cors-max-age":           {Key: "nginx.ingress.kubernetes.io/cors-max-age"},
+
+	"rate-limit-rps": {Key: "nginx.ingress.kubernetes.io/limit-rps"},
+
+	"allow-source-range": {Key: "nginx.ingress.kubernetes.io/whitelist-source-range"},
+	"deny-source-range":  {Key: "nginx.ingress.kubernetes.io/denylist-source-range"},
+
+	"auth-type":   {Key: "nginx.ingress.kubernetes.io/auth-type"},
+	"auth-secret": {Key: "nginx.ingress.kubernetes.io/auth-secret"},
+	"auth-realm":  {Key: "nginx.ingress.kubernetes.io/auth-realm"},
+
+	"auth-url":              {Key: "nginx.ingress.kubernetes.io/auth-url"},
+	"auth-response-headers": {Key: "nginx.ingress.kubernetes.io/auth-response-headers"},
+
+	"canary":                 {Key: "nginx.ingress.kubernetes.io/canary"},
+	"canary-weight":          {Key: "nginx.ingress.kubernetes.io/canary-weight"},
+	"canary-by-header":       {Key: "nginx.ingress.kubernetes.io/canary-by-header"},
+	"canary-by-header-value": {Key: "nginx.ingress.kubernetes.io/canary-by-header-value"},
+	"canary-by-cookie":       {Key: "nginx.ingress.kubernetes.io/canary-by-cookie"},
+
+	"proxy-connect-timeout": {Key: "nginx.ingress.kubernetes.io/proxy-connect-timeout"},
+	"proxy-read-timeout":    {Key: "nginx.ingress.kubernetes.io/proxy-read-timeout"},
+	"proxy-send-timeout":    {Key: "nginx.ingress.kubernetes.io/proxy-send-timeout"},
+
+	"proxy-body-size": {Key: "nginx.ingress.kubernetes.io/proxy-body-size"},
+
+	"custom-http-errors": {Key: "nginx.ingress.kubernetes.io/custom-http-errors"},
+	"default-backend":    {Key: "nginx.ingress.kubernetes.io/default-backend"},
+}
+
+// UnsupportedAnnotationsError is returned when an Ingress uses abstract annotations without translation,
+// because the ingress controller does not support their feature. The scenarios returning it are reported
+// as unsupported instead of failed.
+type UnsupportedAnnotationsError struct {
+	report.UnsupportedFeature
+
+	Annotations []string
+}
+
+func (e *UnsupportedAnnotationsError) Error() string {
+	return fmt.Sprintf("the annotations %v are not mapped to annotations of the ingress controller (see the -annotation-mappings flag)", e.Annotations)
+}
+
+// LoadAnnotationMappings replaces the translations of the abstract annotations with the ones of a YAML file.
+// Abstract annotations missing in the file are not supported by the ingress controller.
+func LoadAnnotationMappings(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	mappings := &AnnotationMappings{}
+	if err := yaml.UnmarshalStrict(data, mappings); err != nil {
+		return fmt.Errorf("reading annotation mappings %v: %w", path, err)
+	}
+
+	Annotations = mappings.Annotations
+	return nil
+}
+
+// SetAnnotationKeys replaces the keys of the annotations with the given name=key pairs
+func SetAnnotationKeys(pairs []string) error {
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("invalid annotation key %q (e.g. cors-enable=example.com/cors)", pair)
+		}
+
+		if Annotations == nil {
+			Annotations = map[string]AnnotationMapping{}
+		}
+
+		name := strings.TrimPrefix(kv[0], AnnotationPrefix)
+
+		mapping := Annotations[name]
+		mapping.Key = kv[1]
+		Annotations[name] = mapping
+	}
+
+	return nil
+}
+
+// translateAnnotations returns a copy of an Ingress with its abstract annotations
+// replaced by the annotations of the ingress controller
+func translateAnnotations(ingress *networking.Ingress) (*networking.Ingress, error) {
+	translated := ingress.DeepCopy()
+	if len(ingress.Annotations) == 0 {
+		return translated, nil
+	}
+
+	translated.Annotations = map[string]string{}
+
+	var unsupported []string
+	for key, value := range ingress.Annotations {
+		if !strings.HasPrefix(key, AnnotationPrefix) {
+			translated.Annotations[key] = value
+			continue
+		}
+
+		mapping, ok := Annotations[strings.TrimPrefix(key, AnnotationPrefix)]
+		if !ok {
+			unsupported = append(unsupported, key)
+			continue
+		}
+
+		if mapping.Key == "" {
+			continue
+		}
+
+		if translatedValue, ok := mapping.Values[value]; ok {
+			value = translatedValue
+		}
+
+		translated.Annotations[mapping.Key] = value
+	}
+
+	if len(unsupported) != 0 {
+		sort.Strings(unsupported)
+		return nil, &UnsupportedAnnotationsError{Annotations: unsupported}
+	}
+
+	return translated, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// BasicAuthSecretKey key of the htpasswd file in the secrets created by NewBasicAuthSecret
+const BasicAuthSecretKey = "auth"
+
+// NewBasicAuthSecret creates a secret containing an htpasswd file with the password of
+// a user, hashed with the Apache MD5 algorithm ($apr1$) supported by most proxies
+func NewBasicAuthSecret(ctx context.Context, c clientset.Interface, namespace, secretName, username, password string) error {
+	salt, err := randomSalt()
+	if err != nil {
+		return err
+	}
+
+	newSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretName,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			BasicAuthSecretKey: []byte(fmt.Sprintf("%v:%v\n", username, apr1(password, salt))),
+		},
+	}
+
+	_, err = applyFixture(ctx, namespace, newSecret, corev1.SchemeGroupVersion.WithKind("Secret"))
+	return err
+}
+
+// apr1Alphabet is the alphabet of the encoding of the salts and hashes of apr1
+const apr1Alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// randomSalt returns a random salt of 8 characters for apr1
+func randomSalt() (string, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	for i := range salt {
+		salt[i] = apr1Alphabet[int(salt[i])%len(apr1Alphabet)]
+	}
+
+	return string(salt), nil
+}
+
+// apr1 returns the hash of a password with the Apache variant of the MD5 crypt algorithm
+// https://httpd.apache.org/docs/2.4/misc/password_encryptions.html
+func apr1(password, salt string) string {
+	const magic = "$apr1$"
+
+	pw := []byte(password)
+
+	alternate := md5.Sum([]byte(password + salt + password))
+
+	digest := md5.New()
+	digest.Write([]byte(password + magic + salt))
+	for i := len(pw); i > 0; i -= 16 {
+		if i > 16 {
+			digest.Write(alternate[:])
+		} else {
+			digest.Write(alternate[:i])
+		}
+	}
+
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			digest.Write([]byte{0})
+		} else {
+			digest.Write(pw[:1])
+		}
+	}
+
+	final := digest.Sum(nil)
+
+	// the rounds make brute force attacks slower
+	for i := 0; i < 1000; i++ {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(pw)
+		} else {
+			round.Write(final)
+		}
+
+		if i%3 != 0 {
+			round.Write([]byte(salt))
+		}
+
+		if i%7 != 0 {
+			round.Write(pw)
+		}
+
+		if i&1 != 0 {
+			round.Write(final)
+		} else {
+			round.Write(pw)
+		}
+
+		final = round.Sum(nil)
+	}
+
+	hash := make([]byte, 0, 22)
+	encode := func(value uint, n int) {
+		for ; n > 0; n-- {
+			hash = append(hash, apr1Alphabet[value&0x3f])
+			value >>= 6
+		}
+	}
+
+	for _, group := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		encode(uint(final[group[0]])<<16|uint(final[group[1]])<<8|uint(final[group[2]]), 4)
+	}
+	encode(uint(final[11]), 2)
+
+	return magic + salt + "$" + string(hash)
+}
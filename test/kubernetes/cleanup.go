@@ -0,0 +1,258 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ManagedByLabel label of the objects created by the suite
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedBy value of ManagedByLabel in the objects created by the suite
+	ManagedBy = "ingress-conformance"
+	// RunIDLabel label of the objects created by the suite with the identifier of the run that created them
+	RunIDLabel = "conformance.ingress.k8s.io/run-id"
+	// AnchorLabel label of the anchor namespaces of the runs
+	AnchorLabel = "conformance.ingress.k8s.io/anchor"
+
+	// anchorPrefix prefix of the names of the anchor namespaces, followed by the identifier of the run
+	anchorPrefix = "ingress-conformance-run-"
+	// anchorConfigMap name of the ConfigMap describing the run in its anchor namespace
+	anchorConfigMap = "run"
+)
+
+// RunID identifier of the run of the suite, set in the RunIDLabel label of the objects it creates
+var RunID string
+
+// anchor reference to the anchor namespace of the run, owner of the objects it creates
+var anchor *metav1.OwnerReference
+
+// cleanupKinds are the kinds of the objects created by the suite, in deletion order. The namespaces are the last ones.
+var cleanupKinds = []string{"Ingress", "HTTPRoute", "Gateway", "Service", "Deployment", "Secret", "Namespace"}
+
+// NewRunID returns a new identifier of a run of the suite, starting with the time of the run
+func NewRunID() string {
+	return fmt.Sprintf("%v-%v", time.Now().UTC().Format("20060102-150405"), utilrand.String(5))
+}
+
+// ValidateRunID returns an error when an identifier of a run cannot be used in labels and in the name of its anchor namespace
+func ValidateRunID(runID string) error {
+	if errs := validation.IsDNS1123Label(anchorPrefix + runID); len(errs) != 0 {
+		return fmt.Errorf("the run identifier '%v' is not valid: %v", runID, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// setSuiteMetadata labels an object as created by the suite in the current run, and makes it owned by the anchor of the run
+func setSuiteMetadata(object metav1.Object) {
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+
+	objectLabels[ManagedByLabel] = ManagedBy
+	objectLabels[RunIDLabel] = RunID
+
+	object.SetLabels(objectLabels)
+
+	if anchor == nil || object.GetUID() == anchor.UID {
+		return
+	}
+
+	for _, owner := range object.GetOwnerReferences() {
+		if owner.UID == anchor.UID {
+			return
+		}
+	}
+
+	object.SetOwnerReferences(append(object.GetOwnerReferences(), *anchor))
+}
+
+// NewRunAnchor creates the anchor of the run, a namespace owning all the objects created by the run so
+// deleting it deletes them in cascade, even when the run was aborted. A ConfigMap inside describes the run.
+// The anchor is a namespace because namespaced objects, like ConfigMaps, cannot own the namespaces of the scenarios.
+func NewRunAnchor(ctx context.Context, c kubernetes.Interface, description map[string]string) error {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: anchorPrefix + RunID,
+			Labels: map[string]string{
+				AnchorLabel: "true",
+			},
+		},
+	}
+
+	setSuiteMetadata(namespace)
+
+	namespace, err := c.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating the anchor namespace of the run: %w", err)
+	}
+
+	anchor = &metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       namespace.Name,
+		UID:        namespace.UID,
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: anchorConfigMap,
+		},
+		Data: description,
+	}
+
+	setSuiteMetadata(configMap)
+
+	_, err = c.CoreV1().ConfigMaps(namespace.Name).Create(ctx, configMap, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating the ConfigMap of the anchor namespace of the run: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRunAnchor deletes the anchor of the run and, in cascade, all the objects created by the run
+func DeleteRunAnchor(ctx context.Context, c kubernetes.Interface) error {
+	if anchor == nil {
+		return nil
+	}
+
+	propagation := metav1.DeletePropagationBackground
+
+	err := c.CoreV1().Namespaces().Delete(ctx, anchor.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("deleting the anchor namespace of the run: %w", err)
+	}
+
+	return nil
+}
+
+// SuiteSelector returns the label selector of the objects created by the suite, or only by one of its runs
+// when runID is not empty
+func SuiteSelector(runID string) string {
+	selector := labels.Set{ManagedByLabel: ManagedBy}
+	if runID != "" {
+		selector[RunIDLabel] = runID
+	}
+
+	return selector.AsSelector().String()
+}
+
+// DeleteSuiteObjects deletes the objects created by the suite, or only by one of its runs when runID is not empty,
+// in all the namespaces, and returns their names. With force, the finalizers of the objects are removed so they
+// do not block the deletion, and the namespaces stuck in the Terminating phase are finalized.
+func DeleteSuiteObjects(ctx context.Context, runID string, force bool) ([]string, error) {
+	selector := SuiteSelector(runID)
+	propagation := metav1.DeletePropagationBackground
+
+	var deleted []string
+	for _, kind := range cleanupKinds {
+		resource := DynamicClient.Resource(fixtureResources[kind])
+
+		objects, err := resource.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if apierrors.IsNotFound(err) {
+			// the Gateway API resources are not installed
+			continue
+		}
+
+		if err != nil {
+			return deleted, fmt.Errorf("listing %v objects: %w", kind, err)
+		}
+
+		for _, object := range objects.Items {
+			name := object.GetName()
+			if object.GetNamespace() != "" {
+				name = object.GetNamespace() + "/" + name
+			}
+
+			client := resource.Namespace(object.GetNamespace())
+			if object.GetNamespace() == "" {
+				client = resource
+			}
+
+			if object.GetDeletionTimestamp() == nil {
+				err := client.Delete(ctx, object.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return deleted, fmt.Errorf("deleting %v %v: %w", kind, name, err)
+				}
+			}
+
+			deleted = append(deleted, fmt.Sprintf("%v %v", kind, name))
+
+			if !force {
+				continue
+			}
+
+			if len(object.GetFinalizers()) != 0 {
+				_, err := client.Patch(ctx, object.GetName(), types.MergePatchType, []byte(`{"metadata":{"finalizers":null}}`), metav1.PatchOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return deleted, fmt.Errorf("removing the finalizers of %v %v: %w", kind, name, err)
+				}
+			}
+
+			// namespaces are only finalized when already stuck, to not leave behind the objects inside
+			if kind == "Namespace" && object.GetDeletionTimestamp() != nil {
+				if err := finalizeNamespace(ctx, object.GetName()); err != nil {
+					return deleted, err
+				}
+			}
+		}
+	}
+
+	return deleted, nil
+}
+
+// finalizeNamespace removes the finalizers of the spec of a namespace being deleted, which are only removed
+// once all the objects of the namespace are deleted
+func finalizeNamespace(ctx context.Context, name string) error {
+	namespace, err := KubeClient.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if namespace.DeletionTimestamp == nil || len(namespace.Spec.Finalizers) == 0 {
+		return nil
+	}
+
+	namespace.Spec.Finalizers = nil
+
+	_, err = KubeClient.CoreV1().Namespaces().Finalize(ctx, namespace, metav1.UpdateOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("finalizing namespace %v: %w", name, err)
+	}
+
+	return nil
+}
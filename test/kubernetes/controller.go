@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// Controller describes the deployment of the ingress controller tested
+type Controller struct {
+	// Class value of spec.controller in the IngressClass of the suite, like k8s.io/ingress-nginx
+	Class string
+
+	// Name of the ingress controller, from the app.kubernetes.io/name label of the deployment or its name
+	Name string
+	// Version of the ingress controller, from the app.kubernetes.io/version label of the deployment or the tag of its image
+	Version string
+
+	// Deployment namespace/name of the deployment of the ingress controller
+	Deployment string
+	// Images of the containers of the deployment
+	Images   []string
+	Replicas int32
+}
+
+// DetectController returns the deployment of the ingress controller: the deployment of the pods selected by
+// ControllerSelector or, when empty, the deployment whose containers reference the controller of the IngressClass
+// of the suite in their arguments, or whose name contains its last segment (e.g. ingress-nginx for
+// k8s.io/ingress-nginx). It returns only the controller of the IngressClass when no deployment is found.
+func DetectController(ctx context.Context, c clientset.Interface) (*Controller, error) {
+	controller := &Controller{}
+
+	ingressClass, err := c.NetworkingV1().IngressClasses().Get(ctx, IngressClassValue, metav1.GetOptions{})
+	if err == nil {
+		controller.Class = ingressClass.Spec.Controller
+	}
+
+	deployments, err := c.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return controller, fmt.Errorf("listing deployments: %w", err)
+	}
+
+	// sorted by namespace and name, so the detection does not depend on the order of the list
+	items := deployments.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Namespace+"/"+items[i].Name < items[j].Namespace+"/"+items[j].Name
+	})
+
+	deployment, err := controllerDeployment(items, controller.Class)
+	if err != nil || deployment == nil {
+		return controller, err
+	}
+
+	controller.Deployment = deployment.Namespace + "/" + deployment.Name
+	controller.Name = deployment.Labels["app.kubernetes.io/name"]
+	if controller.Name == "" {
+		controller.Name = deployment.Name
+	}
+
+	controller.Replicas = 1
+	if deployment.Spec.Replicas != nil {
+		controller.Replicas = *deployment.Spec.Replicas
+	}
+
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		controller.Images = append(controller.Images, container.Image)
+	}
+
+	controller.Version = deployment.Labels["app.kubernetes.io/version"]
+	if controller.Version == "" && len(controller.Images) != 0 {
+		controller.Version = imageTag(controller.Images[0])
+	}
+
+	return controller, nil
+}
+
+// controllerDeployment returns the deployment of the ingress controller among the deployments, or nil
+func controllerDeployment(deployments []appsv1.Deployment, class string) (*appsv1.Deployment, error) {
+	if ControllerSelector != "" {
+		selector, err := labels.Parse(ControllerSelector)
+		if err != nil {
+			return nil, fmt.Errorf("parsing the controller selector: %w", err)
+		}
+
+		for i := range deployments {
+			if selector.Matches(labels.Set(deployments[i].Spec.Template.Labels)) {
+				return &deployments[i], nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	if class == "" {
+		return nil, nil
+	}
+
+	for i := range deployments {
+		for _, container := range deployments[i].Spec.Template.Spec.Containers {
+			for _, arg := range append(container.Command, container.Args...) {
+				if strings.Contains(arg, class) {
+					return &deployments[i], nil
+				}
+			}
+		}
+	}
+
+	segment := class[strings.LastIndex(class, "/")+1:]
+	for i := range deployments {
+		if strings.Contains(deployments[i].Name, segment) {
+			return &deployments[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// imageTag returns the tag of an image reference, or an empty string when it does not have one
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+
+	// the colon of a registry port is followed by a slash
+	i := strings.LastIndex(image, ":")
+	if i == -1 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+
+	return image[i+1:]
+}
+
+// CloudProvider returns the cloud provider of the nodes of the cluster, from the scheme of their provider ID
+// (e.g. aws for aws:///us-east-1a/i-0123), or an empty string when they do not have one
+func CloudProvider(ctx context.Context, c clientset.Interface) (string, error) {
+	nodes, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("listing nodes: %w", err)
+	}
+
+	if len(nodes.Items) == 0 {
+		return "", nil
+	}
+
+	providerID := nodes.Items[0].Spec.ProviderID
+	if i := strings.Index(providerID, "://"); i != -1 {
+		return providerID[:i], nil
+	}
+
+	return "", nil
+}
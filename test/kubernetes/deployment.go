@@ -19,6 +19,7 @@ package kubernetes
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -27,11 +28,14 @@ import (
 	networking "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 
 	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes/templates"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
 )
 
 // EchoService name of the deployment for the echo app
@@ -40,11 +44,28 @@ const EchoService = "echo"
 // EchoContainer container image name
 const EchoContainer = "k8s.gcr.io/ingressconformance/echoserver:v0.0.1@sha256:9b34b17f391f87fb2155f01da2f2f90b7a4a5c1110ed84cb5379faa4f570dc52"
 
+// EchoPort port of the echoserver container
+const EchoPort = 3000
+
+// ClusterDomain DNS domain of the cluster, used in the DNS names of the services
+var ClusterDomain = "cluster.local"
+
 // NewEchoDeployment creates a new deployment of the echoserver image in a particular namespace.
-func NewEchoDeployment(kubeClientSet kubernetes.Interface, namespace, name, serviceName, servicePortName string, servicePort int32) error {
+// The service targets the container port by number, or by name when targetPort is a string.
+func NewEchoDeployment(ctx context.Context, kubeClientSet kubernetes.Interface, namespace, name, serviceName, servicePortName string, servicePort int32, targetPort intstr.IntOrString) error {
 	deploymentName := fmt.Sprintf("%v-%v", name, serviceName)
 
-	deployment, err := kubeClientSet.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+	// ExternalName services created by the features do not need a deployment
+	service, err := kubeClientSet.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil && service.Spec.Type == corev1.ServiceTypeExternalName {
+		return nil
+	}
+
+	deployment, err := kubeClientSet.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return err
@@ -59,6 +80,31 @@ func NewEchoDeployment(kubeClientSet kubernetes.Interface, namespace, name, serv
 		return nil
 	}
 
+	deployment, service, err = echoDeploymentObjects(name, serviceName, servicePortName, servicePort, targetPort)
+	if err != nil {
+		return err
+	}
+
+	_, err = applyFixture(ctx, namespace, deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err != nil {
+		return err
+	}
+
+	_, err = applyFixture(ctx, namespace, service, corev1.SchemeGroupVersion.WithKind("Service"))
+	return err
+}
+
+// echoDeploymentObjects returns the deployment of the echoserver image, named after the Ingress and the
+// service, and the service targeting it
+func echoDeploymentObjects(name, serviceName, servicePortName string, servicePort int32, targetPort intstr.IntOrString) (*appsv1.Deployment, *corev1.Service, error) {
+	deploymentName := fmt.Sprintf("%v-%v", name, serviceName)
+
+	// a named target port must match the name of the container port
+	containerPortName := servicePortName
+	if targetPort.Type == intstr.String {
+		containerPortName = targetPort.StrVal
+	}
+
 	deploymentData := struct {
 		Name        string
 		MatchLabels string
@@ -74,47 +120,39 @@ func NewEchoDeployment(kubeClientSet kubernetes.Interface, namespace, name, serv
 		EchoContainer,
 		name,
 		serviceName,
-		servicePortName,
+		containerPortName,
 	}
 
 	manifest, err := templates.Render("deployment", deploymentData)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	deployment, err = deploymentFromManifest(manifest)
+	deployment, err := deploymentFromManifest(manifest)
 	if err != nil {
-		return err
-	}
-
-	err = displayYamlDefinition(deployment)
-	if err != nil {
-		return fmt.Errorf("unable show yaml definition: %v", err)
-	}
-
-	_, err = kubeClientSet.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("creating deployment (%v): %w", deployment.Name, err)
+		return nil, nil, err
 	}
 
 	serviceData := struct {
-		Name     string
-		Selector string
-		Port     int32
+		Name       string
+		Selector   string
+		Port       int32
+		TargetPort string
 	}{
 		serviceName,
 		deploymentName,
 		servicePort,
+		targetPort.String(),
 	}
 
 	manifest, err = templates.Render("service", serviceData)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	service, err := serviceFromManifest(manifest)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	if servicePortName != "" {
@@ -126,31 +164,25 @@ func NewEchoDeployment(kubeClientSet kubernetes.Interface, namespace, name, serv
 		service.Spec.Ports[0].Port = 8080
 	}
 
-	err = displayYamlDefinition(service)
-	if err != nil {
-		return fmt.Errorf("unable show yaml definition: %v", err)
-	}
-
-	service, err = kubeClientSet.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("creating service (%v): %w", service.Name, err)
-	}
-
-	err = waitForEndpoints(kubeClientSet, WaitForEndpointsTimeout, service.Namespace, service.Name, 1)
-	if err != nil {
-		return fmt.Errorf("waiting for service (%v) endpoints available: %w", service.Name, err)
-	}
-
-	return nil
+	return deployment, service, nil
 }
 
 // DeploymentsFromIngress creates the required deployments for the services defined in the ingress object
-func DeploymentsFromIngress(kubeClientSet kubernetes.Interface, ingress *networking.Ingress) error {
+func DeploymentsFromIngress(ctx context.Context, kubeClientSet kubernetes.Interface, ingress *networking.Ingress) error {
+	return DeploymentsFromIngressWithTargetPort(ctx, kubeClientSet, ingress, intstr.FromInt(EchoPort))
+}
+
+// DeploymentsFromIngressWithTargetPort creates the required deployments for the services defined in the
+// ingress object, like DeploymentsFromIngress, with services targeting the container port by name when
+// targetPort is a string
+func DeploymentsFromIngressWithTargetPort(ctx context.Context, kubeClientSet kubernetes.Interface, ingress *networking.Ingress, targetPort intstr.IntOrString) error {
 	if ingress.Spec.DefaultBackend != nil {
 		service := ingress.Spec.DefaultBackend.Service
 		servicePort := service.Port
 
-		err := NewEchoDeployment(kubeClientSet, ingress.Namespace, ingress.Name, service.Name, servicePort.Name, servicePort.Number)
+		deployCtx, span := tracing.Start(ctx, "deploy backend", "k8s.namespace.name", ingress.Namespace, "k8s.service.name", service.Name)
+		err := NewEchoDeployment(deployCtx, kubeClientSet, ingress.Namespace, ingress.Name, service.Name, servicePort.Name, servicePort.Number, targetPort)
+		span.End(err)
 		if err != nil {
 			return err
 		}
@@ -165,7 +197,9 @@ func DeploymentsFromIngress(kubeClientSet kubernetes.Interface, ingress *network
 			service := path.Backend.Service
 			servicePort := service.Port
 
-			err := NewEchoDeployment(kubeClientSet, ingress.Namespace, ingress.Name, service.Name, servicePort.Name, servicePort.Number)
+			deployCtx, span := tracing.Start(ctx, "deploy backend", "k8s.namespace.name", ingress.Namespace, "k8s.service.name", service.Name)
+			err := NewEchoDeployment(deployCtx, kubeClientSet, ingress.Namespace, ingress.Name, service.Name, servicePort.Name, servicePort.Number, targetPort)
+			span.End(err)
 			if err != nil {
 				return err
 			}
@@ -175,8 +209,32 @@ func DeploymentsFromIngress(kubeClientSet kubernetes.Interface, ingress *network
 	return nil
 }
 
+// NewExternalNameService creates a service of type ExternalName, an alias of a DNS name
+func NewExternalNameService(ctx context.Context, kubeClientSet kubernetes.Interface, namespace, serviceName, externalName string, servicePort int32) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: serviceName,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: externalName,
+			Ports: []corev1.ServicePort{
+				{Port: servicePort},
+			},
+		},
+	}
+
+	_, err := applyFixture(ctx, namespace, service, corev1.SchemeGroupVersion.WithKind("Service"))
+	return err
+}
+
+// ServiceDNSName returns the fully qualified DNS name of a service
+func ServiceDNSName(namespace, serviceName string) string {
+	return fmt.Sprintf("%v.%v.svc.%v", serviceName, namespace, ClusterDomain)
+}
+
 // ScaleIngressBackendDeployment changes the replicas count of a deployment defined in an ingress service backend
-func ScaleIngressBackendDeployment(kubeClientSet kubernetes.Interface, namespace, name, serviceName string, replicas int) error {
+func ScaleIngressBackendDeployment(ctx context.Context, kubeClientSet kubernetes.Interface, namespace, name, serviceName string, replicas int) error {
 	deploymentName := fmt.Sprintf("%v-%v", name, serviceName)
 
 	scale := &autoscalingv1.Scale{
@@ -189,21 +247,73 @@ func ScaleIngressBackendDeployment(kubeClientSet kubernetes.Interface, namespace
 		},
 	}
 
-	_, err := kubeClientSet.AppsV1().Deployments(namespace).UpdateScale(context.TODO(), deploymentName, scale, metav1.UpdateOptions{})
+	_, err := kubeClientSet.AppsV1().Deployments(namespace).UpdateScale(ctx, deploymentName, scale, metav1.UpdateOptions{})
 	if err != nil {
 		return err
 	}
 
-	err = waitForEndpoints(kubeClientSet, WaitForEndpointsTimeout, namespace, serviceName, replicas)
+	err = waitForEndpoints(ctx, kubeClientSet, WaitForEndpointsTimeout, namespace, serviceName, replicas)
 	if err != nil {
 		return fmt.Errorf("waiting for service (%v) endpoints available: %w", serviceName, err)
 	}
 
-	time.Sleep(60 * time.Second)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(60 * time.Second):
+	}
+
+	return nil
+}
+
+// RestartIngressBackendDeployment triggers a rolling restart of a deployment defined in an ingress
+// service backend, like kubectl rollout restart, and waits until the rollout completes
+func RestartIngressBackendDeployment(ctx context.Context, kubeClientSet kubernetes.Interface, namespace, name, serviceName string) error {
+	deploymentName := fmt.Sprintf("%v-%v", name, serviceName)
+
+	ctx, span := tracing.Start(ctx, "restart backend", "k8s.namespace.name", namespace, "k8s.deployment.name", deploymentName)
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`, time.Now().Format(time.RFC3339))
+	deployment, err := kubeClientSet.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		span.End(err)
+		return fmt.Errorf("restarting deployment (%v): %w", deploymentName, err)
+	}
+
+	err = waitForRollout(ctx, kubeClientSet, WaitForEndpointsTimeout, namespace, deploymentName, deployment.Generation)
+	span.End(err)
+	if err != nil {
+		return fmt.Errorf("waiting for deployment (%v) rollout: %w", deploymentName, err)
+	}
 
 	return nil
 }
 
+// waitForRollout waits for a given amount of time until a deployment generation is rolled out,
+// with all its replicas updated and available
+func waitForRollout(ctx context.Context, kubeClientSet kubernetes.Interface, timeout time.Duration, ns, name string, generation int64) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return wait.PollUntil(time.Second, func() (bool, error) {
+		deployment, err := kubeClientSet.AppsV1().Deployments(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		status := deployment.Status
+		return status.ObservedGeneration >= generation &&
+			status.UpdatedReplicas == replicas &&
+			status.AvailableReplicas == replicas &&
+			status.Replicas == replicas, nil
+	}, ctx.Done())
+}
+
 // deploymentFromManifest deserializes a Deployment definition from a yaml string
 func deploymentFromManifest(manifest string) (*appsv1.Deployment, error) {
 	deployment := &appsv1.Deployment{}
@@ -225,13 +335,18 @@ func serviceFromManifest(manifest string) (*corev1.Service, error) {
 }
 
 // waitForEndpoints waits for a given amount of time until the number of endpoints = expectedEndpoints.
-func waitForEndpoints(kubeClientSet kubernetes.Interface, timeout time.Duration, ns, name string, expectedEndpoints int) error {
+func waitForEndpoints(ctx context.Context, kubeClientSet kubernetes.Interface, timeout time.Duration, ns, name string, expectedEndpoints int) error {
 	if expectedEndpoints == 0 {
 		return nil
 	}
 
-	return wait.Poll(5*time.Second, timeout, func() (bool, error) {
-		endpoint, err := kubeClientSet.CoreV1().Endpoints(ns).Get(context.TODO(), name, metav1.GetOptions{})
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, span := tracing.Start(ctx, "wait for endpoints", "k8s.namespace.name", ns, "k8s.service.name", name, "endpoints", expectedEndpoints)
+
+	err := wait.PollUntil(5*time.Second, func() (bool, error) {
+		endpoint, err := kubeClientSet.CoreV1().Endpoints(ns).Get(ctx, name, metav1.GetOptions{})
 		if apierrors.IsNotFound(err) {
 			return false, nil
 		}
@@ -241,7 +356,39 @@ func waitForEndpoints(kubeClientSet kubernetes.Interface, timeout time.Duration,
 		}
 
 		return false, nil
-	})
+	}, ctx.Done())
+	span.End(err)
+
+	return err
+}
+
+// ReadyEndpoints returns the names of the pods of the ready endpoints of a service, sorted, or their
+// IP addresses when the endpoints do not reference a pod
+func ReadyEndpoints(ctx context.Context, kubeClientSet kubernetes.Interface, ns, name string) ([]string, error) {
+	endpoints, err := kubeClientSet.CoreV1().Endpoints(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting the endpoints of the service %v: %w", name, err)
+	}
+
+	ready := map[string]bool{}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+				ready[address.TargetRef.Name] = true
+			} else {
+				ready[address.IP] = true
+			}
+		}
+	}
+
+	var names []string
+	for endpoint := range ready {
+		names = append(names, endpoint)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
 }
 
 func countReadyEndpoints(e *corev1.Endpoints) int {
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// ControllerSelector label selector of the ingress controller pods, used to collect their logs when a scenario fails
+	ControllerSelector = ""
+	// ControllerLogLines maximum number of log lines collected from each container of the ingress controller pods
+	ControllerLogLines int64 = 200
+)
+
+// ControllerLogs returns the logs written since a time by the containers of the pods selected by
+// ControllerSelector in any namespace, by namespace/pod/container. It returns no logs when
+// ControllerSelector is empty.
+func ControllerLogs(ctx context.Context, c clientset.Interface, since time.Time) (map[string]string, error) {
+	if ControllerSelector == "" {
+		return nil, nil
+	}
+
+	pods, err := c.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: ControllerSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing ingress controller pods: %w", err)
+	}
+
+	logs := map[string]string{}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			raw, err := c.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+				Container: container.Name,
+				SinceTime: &metav1.Time{Time: since},
+				TailLines: &ControllerLogLines,
+			}).DoRaw(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("reading logs of container %v of pod %v/%v: %w", container.Name, pod.Namespace, pod.Name, err)
+			}
+
+			logs[fmt.Sprintf("%v/%v/%v", pod.Namespace, pod.Name, container.Name)] = string(raw)
+		}
+	}
+
+	return logs, nil
+}
+
+// NamespaceEvents returns the events of the objects of a namespace, oldest first, one per line
+func NamespaceEvents(ctx context.Context, c clientset.Interface, namespace string) (string, error) {
+	events, err := c.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("listing events of namespace %v: %w", namespace, err)
+	}
+
+	items := events.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return eventTime(&items[i]).Before(eventTime(&items[j]))
+	})
+
+	var out strings.Builder
+	for _, event := range items {
+		fmt.Fprintf(&out, "%v %v %v %v/%v: %v (%v, x%v)\n",
+			eventTime(&event).Format("15:04:05"), event.Type, event.Reason,
+			event.InvolvedObject.Kind, event.InvolvedObject.Name,
+			strings.TrimSpace(event.Message), event.Source.Component, event.Count)
+	}
+
+	return out.String(), nil
+}
+
+// eventTime returns the last time an event was observed
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+
+	return event.CreationTimestamp.Time
+}
+
+// IngressStatus returns the status of an Ingress, and the annotations set by the ingress controller, in yaml
+func IngressStatus(ctx context.Context, c clientset.Interface, namespace, name string) (string, error) {
+	if API == APIGateway {
+		return gatewayResourcesStatus(ctx, namespace, name)
+	}
+
+	ingress, err := c.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("reading ingress %v/%v: %w", namespace, name, err)
+	}
+
+	output, err := yaml.Marshal(map[string]interface{}{
+		"generation":  ingress.Generation,
+		"annotations": ingress.Annotations,
+		"status":      ingress.Status,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
@@ -0,0 +1,296 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/gateway"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
+)
+
+// FieldManager is the field manager of the objects applied by the suite
+const FieldManager = "ingress-conformance"
+
+// fixtureResources contains the resources of the kinds of objects of the fixtures
+var fixtureResources = map[string]schema.GroupVersionResource{
+	"Namespace":  corev1.SchemeGroupVersion.WithResource("namespaces"),
+	"Secret":     corev1.SchemeGroupVersion.WithResource("secrets"),
+	"Service":    corev1.SchemeGroupVersion.WithResource("services"),
+	"Deployment": appsv1.SchemeGroupVersion.WithResource("deployments"),
+	"Ingress":    networking.SchemeGroupVersion.WithResource("ingresses"),
+	"Gateway":    gateway.GatewayResource,
+	"HTTPRoute":  gateway.HTTPRouteResource,
+}
+
+// fixtureWaits contains the conditions the objects of each kind must meet once applied
+var fixtureWaits = map[string]func(context.Context, *unstructured.Unstructured) error{
+	"Deployment": func(ctx context.Context, deployment *unstructured.Unstructured) error {
+		err := waitForRollout(ctx, KubeClient, WaitForEndpointsTimeout, deployment.GetNamespace(), deployment.GetName(), deployment.GetGeneration())
+		if err != nil {
+			return fmt.Errorf("waiting for deployment (%v) rollout: %w", deployment.GetName(), err)
+		}
+
+		return nil
+	},
+	"Service": func(ctx context.Context, service *unstructured.Unstructured) error {
+		// only the services selecting the pods of a deployment have endpoints
+		selector, _, _ := unstructured.NestedStringMap(service.Object, "spec", "selector")
+		if len(selector) == 0 {
+			return nil
+		}
+
+		err := waitForEndpoints(ctx, KubeClient, WaitForEndpointsTimeout, service.GetNamespace(), service.GetName(), 1)
+		if err != nil {
+			return fmt.Errorf("waiting for service (%v) endpoints available: %w", service.GetName(), err)
+		}
+
+		return nil
+	},
+}
+
+// FixtureObjects returns the objects the suite creates in a namespace for an Ingress of a feature: the
+// self-signed TLS secrets and the deployments and services of its backends, followed by the Ingress, or
+// by the equivalent Gateway API resources in the Gateway API mode
+func FixtureObjects(namespace string, ingress *networking.Ingress) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName == "" || len(tls.Hosts) == 0 {
+			continue
+		}
+
+		data, err := selfSignedSecretData(tls.Hosts)
+		if err != nil {
+			return nil, err
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: tls.SecretName},
+			Type:       corev1.SecretTypeTLS,
+			Data:       data,
+		}
+
+		objects, err = appendFixtureObject(objects, namespace, secret, corev1.SchemeGroupVersion.WithKind("Secret"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	backends := []*networking.IngressBackend{ingress.Spec.DefaultBackend}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for i := range rule.HTTP.Paths {
+			backends = append(backends, &rule.HTTP.Paths[i].Backend)
+		}
+	}
+
+	services := map[string]bool{}
+	for _, backend := range backends {
+		if backend == nil || backend.Service == nil || services[backend.Service.Name] {
+			continue
+		}
+
+		services[backend.Service.Name] = true
+
+		port := backend.Service.Port
+		deployment, service, err := echoDeploymentObjects(ingress.Name, backend.Service.Name, port.Name, port.Number, intstr.FromInt(EchoPort))
+		if err != nil {
+			return nil, err
+		}
+
+		objects, err = appendFixtureObject(objects, namespace, deployment, appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		if err != nil {
+			return nil, err
+		}
+
+		objects, err = appendFixtureObject(objects, namespace, service, corev1.SchemeGroupVersion.WithKind("Service"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if API == APIGateway {
+		resources, err := gatewayResourcesFromIngress(namespace, ingress)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(objects, resources.Objects()...), nil
+	}
+
+	translated, err := translateAnnotations(ingress)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendFixtureObject(objects, namespace, translated, networking.SchemeGroupVersion.WithKind("Ingress"))
+}
+
+// appendFixtureObject converts an object of a fixture to an unstructured object in the namespace
+func appendFixtureObject(objects []*unstructured.Unstructured, namespace string, object apiruntime.Object, gvk schema.GroupVersionKind) ([]*unstructured.Unstructured, error) {
+	converted, err := fixtureObject(namespace, object, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(objects, converted), nil
+}
+
+// fixtureObject converts an object of a fixture to an unstructured object in the namespace, without the
+// fields set by the API server
+func fixtureObject(namespace string, object apiruntime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	object = object.DeepCopyObject()
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+
+	content, err := apiruntime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, fmt.Errorf("converting %v: %w", gvk.Kind, err)
+	}
+
+	converted := &unstructured.Unstructured{Object: content}
+	converted.SetNamespace(namespace)
+	unstructured.RemoveNestedField(converted.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(converted.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(converted.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(converted.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(converted.Object, "status")
+
+	return converted, nil
+}
+
+// applyFixture applies an object created by the suite with server-side apply, labeled and owned like the other
+// objects of the run, and waits for the condition of its kind, like ready endpoints for services. Applying the
+// object again replaces the fields set by the previous apply. The fields also set by other field managers, like
+// a step updating the object, are taken over after logging the conflict.
+func applyFixture(ctx context.Context, namespace string, object apiruntime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	fixture, err := fixtureObject(namespace, object, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	setSuiteMetadata(fixture)
+
+	err = displayYamlDefinition(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("unable show yaml definition: %v", err)
+	}
+
+	_, span := tracing.StartClient(ctx, "apply "+gvk.Kind, "k8s.namespace.name", namespace, "k8s."+strings.ToLower(gvk.Kind)+".name", fixture.GetName())
+	applied, err := applyObject(ctx, fixture, false, false)
+	if apierrors.IsConflict(err) {
+		klog.V(2).InfoS("Taking over the fields of an object set by other field managers", "kind", gvk.Kind, "namespace", namespace, "name", fixture.GetName(), "conflict", err)
+		applied, err = applyObject(ctx, fixture, true, false)
+	}
+	span.End(err)
+
+	if err != nil {
+		return nil, fmt.Errorf("applying %v (%v): %w", gvk.Kind, fixture.GetName(), err)
+	}
+
+	if wait, ok := fixtureWaits[gvk.Kind]; ok {
+		if err := wait(ctx, applied); err != nil {
+			return nil, err
+		}
+	}
+
+	return applied, nil
+}
+
+// LiveObject returns the object of the cluster with the kind, namespace and name of the object, or nil when it does not exist
+func LiveObject(ctx context.Context, object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	resource, err := fixtureResource(object)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := resource.Get(ctx, object.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	return live, err
+}
+
+// ApplyObject applies an object with server-side apply, owning all its fields, and returns the object
+// resulting from the apply. With dryRun the object is validated and merged by the API server but not persisted.
+func ApplyObject(ctx context.Context, object *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	applied, err := applyObject(ctx, object, true, dryRun)
+	if err != nil {
+		return nil, fmt.Errorf("applying %v (%v): %w", object.GetKind(), object.GetName(), err)
+	}
+
+	return applied, nil
+}
+
+// applyObject sends the server-side apply patch of an object. Without force, the apply fails with a conflict
+// when it sets fields owned by other field managers.
+func applyObject(ctx context.Context, object *unstructured.Unstructured, force, dryRun bool) (*unstructured.Unstructured, error) {
+	resource, err := fixtureResource(object)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(object.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	options := metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+	}
+
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	return resource.Patch(ctx, object.GetName(), types.ApplyPatchType, data, options)
+}
+
+// fixtureResource returns the client of the resource of an object of a fixture
+func fixtureResource(object *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	resource, ok := fixtureResources[object.GetKind()]
+	if !ok {
+		return nil, fmt.Errorf("the kind %v is not a kind of the fixtures", object.GetKind())
+	}
+
+	if object.GetNamespace() == "" {
+		return DynamicClient.Resource(resource), nil
+	}
+
+	return DynamicClient.Resource(resource).Namespace(object.GetNamespace()), nil
+}
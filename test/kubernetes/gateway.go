@@ -0,0 +1,323 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/gateway"
+	"sigs.k8s.io/ingress-controller-conformance/test/http"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
+)
+
+const (
+	// APIIngress exposes the backend services of the features with Ingresses
+	APIIngress = "ingress"
+	// APIGateway exposes the backend services of the features with the Gateways and
+	// HTTPRoutes equivalent to their Ingresses
+	APIGateway = "gateway"
+)
+
+// APIs contains the valid values of API
+var APIs = []string{APIIngress, APIGateway}
+
+var (
+	// API used to expose the backend services of the features
+	API = APIIngress
+	// GatewayClassName class of the Gateways created from the Ingresses with APIGateway
+	GatewayClassName = "conformance"
+)
+
+// DynamicClient Kubernetes API client of the resources without typed client, like the Gateway API resources
+var DynamicClient dynamic.Interface
+
+// gatewayWaitInterval time to wait between the checks of the status of the Gateway API resources
+const gatewayWaitInterval = 2 * time.Second
+
+// UnsupportedOperationError is returned by the operations on Ingresses without equivalent in
+// the Gateway API, like changing their class. The scenarios returning it are reported as
+// unsupported instead of failed.
+type UnsupportedOperationError struct {
+	report.UnsupportedFeature
+
+	Operation string
+}
+
+func (e *UnsupportedOperationError) Error() string {
+	return fmt.Sprintf("%v has no equivalent in the Gateway API", e.Operation)
+}
+
+// LoadDynamicClient returns a dynamic client for connecting to kubernetes clusters
+func LoadDynamicClient() (dynamic.Interface, error) {
+	config, err := clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(config)
+}
+
+// newGatewayResources creates the Gateway and HTTPRoutes equivalent to an Ingress
+func newGatewayResources(ctx context.Context, namespace string, ingress *networking.Ingress) error {
+	resources, err := gatewayResourcesFromIngress(namespace, ingress)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range resources.Objects() {
+		err = applyGatewayObject(ctx, namespace, object)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateGatewayResources replaces the spec of the Gateway and HTTPRoutes equivalent to an
+// Ingress, creating and deleting HTTPRoutes as the rules of the Ingress change
+func updateGatewayResources(ctx context.Context, namespace string, ingress *networking.Ingress) error {
+	resources, err := gatewayResourcesFromIngress(namespace, ingress)
+	if err != nil {
+		return err
+	}
+
+	gateways := DynamicClient.Resource(gateway.GatewayResource).Namespace(namespace)
+	routes := DynamicClient.Resource(gateway.HTTPRouteResource).Namespace(namespace)
+
+	if _, err := gateways.Get(ctx, ingress.Name, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	existing, err := routes.List(ctx, metav1.ListOptions{LabelSelector: gateway.IngressLabel + "=" + ingress.Name})
+	if err != nil {
+		return err
+	}
+
+	currentRoutes := map[string]bool{}
+	for _, route := range existing.Items {
+		currentRoutes[route.GetName()] = true
+	}
+
+	for _, object := range resources.Objects() {
+		delete(currentRoutes, object.GetName())
+
+		err = applyGatewayObject(ctx, namespace, object)
+		if err != nil {
+			return err
+		}
+	}
+
+	for name := range currentRoutes {
+		_, span := tracing.StartClient(ctx, "delete HTTPRoute", "k8s.namespace.name", namespace, "k8s.httproute.name", name)
+		err = routes.Delete(ctx, name, metav1.DeleteOptions{})
+		span.End(err)
+
+		if err != nil {
+			return fmt.Errorf("deleting HTTPRoute (%v): %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteGatewayResources deletes the Gateway and HTTPRoutes equivalent to an Ingress
+func deleteGatewayResources(ctx context.Context, namespace, name string) error {
+	_, span := tracing.StartClient(ctx, "delete Gateway", "k8s.namespace.name", namespace, "k8s.gateway.name", name)
+	err := DynamicClient.Resource(gateway.HTTPRouteResource).Namespace(namespace).DeleteCollection(ctx,
+		metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: gateway.IngressLabel + "=" + name})
+	if err == nil {
+		err = DynamicClient.Resource(gateway.GatewayResource).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	}
+	span.End(err)
+
+	return err
+}
+
+// waitForGatewayAddressFamily waits for the Gateway equivalent to an Ingress to be programmed with an address of the
+// address family, and for its HTTPRoutes to be accepted. Hostnames are valid addresses for any family.
+func waitForGatewayAddressFamily(ctx context.Context, namespace, name, family string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, WaitForIngressAddressTimeout)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "wait for Gateway address", "k8s.namespace.name", namespace, "k8s.gateway.name", name, "ip.family", family)
+
+	address, pending := "", "the Gateway to be programmed"
+	err := wait.PollImmediateUntil(gatewayWaitInterval, func() (bool, error) {
+		gw, err := DynamicClient.Resource(gateway.GatewayResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		conditions, _, _ := unstructured.NestedSlice(gw.Object, "status", "conditions")
+		if !conditionTrue(conditions, "Programmed") {
+			return false, nil
+		}
+
+		pending = "an address of the Gateway"
+		address = gatewayAddress(gw, family)
+		if address == "" {
+			return false, nil
+		}
+
+		routes, err := DynamicClient.Resource(gateway.HTTPRouteResource).Namespace(namespace).List(ctx,
+			metav1.ListOptions{LabelSelector: gateway.IngressLabel + "=" + name})
+		if err != nil {
+			return false, err
+		}
+
+		for _, route := range routes.Items {
+			if !routeAccepted(&route, name) {
+				pending = fmt.Sprintf("the HTTPRoute %v to be accepted", route.GetName())
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}, ctx.Done())
+	span.SetAttributes("address", address)
+	span.End(err)
+
+	if err != nil {
+		return "", fmt.Errorf("waiting for %v (%v/%v): %w", pending, namespace, name, err)
+	}
+
+	return address, nil
+}
+
+// gatewayResourcesStatus returns the status of the Gateway and HTTPRoutes equivalent to an Ingress, in yaml
+func gatewayResourcesStatus(ctx context.Context, namespace, name string) (string, error) {
+	gw, err := DynamicClient.Resource(gateway.GatewayResource).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("reading gateway %v/%v: %w", namespace, name, err)
+	}
+
+	routes, err := DynamicClient.Resource(gateway.HTTPRouteResource).Namespace(namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: gateway.IngressLabel + "=" + name})
+	if err != nil {
+		return "", fmt.Errorf("listing the HTTPRoutes of %v/%v: %w", namespace, name, err)
+	}
+
+	routeStatus := map[string]interface{}{}
+	for _, route := range routes.Items {
+		routeStatus[route.GetName()] = route.Object["status"]
+	}
+
+	output, err := yaml.Marshal(map[string]interface{}{
+		"generation": gw.GetGeneration(),
+		"status":     gw.Object["status"],
+		"httpRoutes": routeStatus,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+// gatewayResourcesFromIngress converts an Ingress to the Gateway API resources in the namespace
+func gatewayResourcesFromIngress(namespace string, ingress *networking.Ingress) (*gateway.Resources, error) {
+	ingress = ingress.DeepCopy()
+	ingress.Namespace = namespace
+
+	return gateway.FromIngress(ingress, GatewayClassName)
+}
+
+// applyGatewayObject applies a Gateway or an HTTPRoute
+func applyGatewayObject(ctx context.Context, namespace string, object *unstructured.Unstructured) error {
+	_, err := applyFixture(ctx, namespace, object, object.GroupVersionKind())
+	return err
+}
+
+// gatewayAddress returns the first address of the status of the Gateway of the address family
+func gatewayAddress(gw *unstructured.Unstructured, family string) string {
+	addresses, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	for _, address := range addresses {
+		entry, ok := address.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		value, _, _ := unstructured.NestedString(entry, "value")
+		if value == "" {
+			continue
+		}
+
+		ip := net.ParseIP(value)
+		if ip == nil || http.IsIPFamily(ip, family) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// routeAccepted returns true when the HTTPRoute is accepted by the Gateway
+func routeAccepted(route *unstructured.Unstructured, gatewayName string) bool {
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, parent := range parents {
+		entry, ok := parent.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _, _ := unstructured.NestedString(entry, "parentRef", "name")
+		conditions, _, _ := unstructured.NestedSlice(entry, "conditions")
+		if name == gatewayName && conditionTrue(conditions, "Accepted") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditionTrue returns true when the status conditions contain the condition type with the True status
+func conditionTrue(conditions []interface{}, conditionType string) bool {
+	for _, condition := range conditions {
+		entry, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if entry["type"] == conditionType && entry["status"] == "True" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsupportedInGatewayAPI returns an UnsupportedOperationError for the operation when
+// the backend services are exposed with the Gateway API
+func unsupportedInGatewayAPI(operation string) error {
+	if API != APIGateway {
+		return nil
+	}
+
+	return &UnsupportedOperationError{Operation: operation}
+}
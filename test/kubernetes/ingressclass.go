@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// defaultIngressClassAnnotation marks the IngressClass assigned to new Ingresses without class
+const defaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// IngressClasses returns the IngressClass resources of the cluster
+func IngressClasses(ctx context.Context, c clientset.Interface) ([]networking.IngressClass, error) {
+	ingressClasses, err := c.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing IngressClasses: %w", err)
+	}
+
+	return ingressClasses.Items, nil
+}
+
+// DefaultIngressClass returns the name of the default IngressClass of the cluster,
+// or an empty string if the cluster does not have a default IngressClass.
+func DefaultIngressClass(ctx context.Context, c clientset.Interface) (string, error) {
+	ingressClasses, err := IngressClasses(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	var defaults []string
+	for _, ingressClass := range ingressClasses {
+		if ingressClass.Annotations[defaultIngressClassAnnotation] == "true" {
+			defaults = append(defaults, ingressClass.Name)
+		}
+	}
+
+	// Kubernetes rejects new Ingresses without class when there is more than one default
+	if len(defaults) > 1 {
+		return "", fmt.Errorf("the cluster contains more than one default IngressClass: %v", defaults)
+	}
+
+	if len(defaults) == 0 {
+		return "", nil
+	}
+
+	return defaults[0], nil
+}
+
+// IngressClassName returns the class of an Ingress, or an empty string if the Ingress does not have a class
+func IngressClassName(ctx context.Context, c clientset.Interface, namespace, name string) (string, error) {
+	if err := unsupportedInGatewayAPI("the class of an Ingress"); err != nil {
+		return "", err
+	}
+
+	ingress, err := c.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	if ingress.Spec.IngressClassName == nil {
+		return "", nil
+	}
+
+	return *ingress.Spec.IngressClassName, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/http"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
+)
+
+// ReadinessCheck returns true when the Ingress exposed in the address is ready to serve traffic
+type ReadinessCheck func(ctx context.Context, c clientset.Interface, ingress *networking.Ingress, address string) (bool, error)
+
+var (
+	// ReadinessChecks names of the checks run after an Ingress acquires an address
+	ReadinessChecks []string
+	// WaitForIngressReadyTimeout maximum wait time for the readiness checks of an Ingress
+	WaitForIngressReadyTimeout = 5 * time.Minute
+
+	// ReadinessAnnotation annotation, as key or key=value, set by the ingress controller on ready Ingresses
+	ReadinessAnnotation = ""
+	// ReadinessConditionType type of the status condition set by the ingress controller on ready Ingresses
+	ReadinessConditionType = "Ready"
+)
+
+// readinessWaitInterval time to wait between readiness checks
+const readinessWaitInterval = 2 * time.Second
+
+var readinessChecks = map[string]ReadinessCheck{
+	"annotation": annotationReadinessCheck,
+	"conditions": conditionsReadinessCheck,
+	"probe":      probeReadinessCheck,
+}
+
+// RegisterReadinessCheck adds a readiness check that can be enabled by name in ReadinessChecks
+func RegisterReadinessCheck(name string, check ReadinessCheck) {
+	readinessChecks[name] = check
+}
+
+// ValidateReadinessChecks returns an error if ReadinessChecks contains unknown checks
+func ValidateReadinessChecks() error {
+	for _, name := range ReadinessChecks {
+		if _, ok := readinessChecks[name]; !ok {
+			valid := sets.StringKeySet(readinessChecks).List()
+			return fmt.Errorf("unknown readiness check %v (valid values are %v)", name, strings.Join(valid, ", "))
+		}
+	}
+
+	if sets.NewString(ReadinessChecks...).Has("annotation") && ReadinessAnnotation == "" {
+		return fmt.Errorf("the annotation readiness check requires a readiness annotation")
+	}
+
+	return nil
+}
+
+// WaitForIngressReady runs the configured readiness checks until all of them pass
+func WaitForIngressReady(ctx context.Context, c clientset.Interface, namespace, name, address string) error {
+	if len(ReadinessChecks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, WaitForIngressReadyTimeout)
+	defer cancel()
+
+	ctx, span := tracing.Start(ctx, "wait for Ingress readiness", "k8s.namespace.name", namespace, "k8s.ingress.name", name)
+
+	pending := ""
+	err := wait.PollImmediateUntil(readinessWaitInterval, func() (bool, error) {
+		ingress, err := c.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		for _, check := range ReadinessChecks {
+			ready, err := readinessChecks[check](ctx, c, ingress, address)
+			if err != nil {
+				return false, err
+			}
+
+			if !ready {
+				pending = check
+				return false, nil
+			}
+		}
+
+		return true, nil
+	}, ctx.Done())
+	span.End(err)
+
+	if err != nil {
+		return fmt.Errorf("waiting for the %v readiness check of ingress %v/%v: %w", pending, namespace, name, err)
+	}
+
+	return nil
+}
+
+// annotationReadinessCheck waits for the ingress controller to set the ReadinessAnnotation
+func annotationReadinessCheck(_ context.Context, _ clientset.Interface, ingress *networking.Ingress, _ string) (bool, error) {
+	kv := strings.SplitN(ReadinessAnnotation, "=", 2)
+
+	value, ok := ingress.Annotations[kv[0]]
+	if !ok {
+		return false, nil
+	}
+
+	return len(kv) == 1 || value == kv[1], nil
+}
+
+// conditionsReadinessCheck waits for a status condition of type ReadinessConditionType with status True,
+// observed for the current generation of the Ingress, like the conditions of the Gateway API resources.
+// The Ingress API does not define status conditions, so the raw object returned by the API server is used.
+func conditionsReadinessCheck(ctx context.Context, c clientset.Interface, ingress *networking.Ingress, _ string) (bool, error) {
+	raw, err := c.NetworkingV1().RESTClient().Get().
+		Namespace(ingress.Namespace).
+		Resource("ingresses").
+		Name(ingress.Name).
+		DoRaw(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var obj struct {
+		Metadata struct {
+			Generation int64 `json:"generation"`
+		} `json:"metadata"`
+		Status struct {
+			ObservedGeneration int64 `json:"observedGeneration"`
+			Conditions         []struct {
+				Type               string `json:"type"`
+				Status             string `json:"status"`
+				ObservedGeneration int64  `json:"observedGeneration"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}
+
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return false, fmt.Errorf("reading ingress status: %w", err)
+	}
+
+	generation := obj.Metadata.Generation
+	if obj.Status.ObservedGeneration != 0 && obj.Status.ObservedGeneration < generation {
+		return false, nil
+	}
+
+	for _, condition := range obj.Status.Conditions {
+		if condition.Type != ReadinessConditionType {
+			continue
+		}
+
+		if condition.ObservedGeneration != 0 && condition.ObservedGeneration < generation {
+			return false, nil
+		}
+
+		return condition.Status == string(metav1.ConditionTrue), nil
+	}
+
+	return false, nil
+}
+
+// probeReadinessCheck waits for a request to the first rule of the Ingress to return a status code other than 404
+func probeReadinessCheck(ctx context.Context, _ clientset.Interface, ingress *networking.Ingress, address string) (bool, error) {
+	hostname, path := "", "/"
+	if len(ingress.Spec.Rules) != 0 {
+		rule := ingress.Spec.Rules[0]
+		hostname = rule.Host
+
+		if rule.HTTP != nil && len(rule.HTTP.Paths) != 0 {
+			path = rule.HTTP.Paths[0].Path
+		}
+	}
+
+	// wildcard hosts are not valid request hostnames
+	hostname = strings.Replace(hostname, "*", "probe", 1)
+
+	_, res, err := http.CaptureRoundTrip(ctx, nethttp.MethodGet, "http", hostname, path, http.WithAddresses(map[string]string{"": address}))
+	if err != nil {
+		return false, nil
+	}
+
+	return res.StatusCode != nethttp.StatusNotFound, nil
+}
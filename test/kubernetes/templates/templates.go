@@ -48,6 +48,18 @@ spec:
           valueFrom:
             fieldRef:
               fieldPath: metadata.name
+        - name: POD_IP
+          valueFrom:
+            fieldRef:
+              fieldPath: status.podIP
+        - name: NODE_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: spec.nodeName
+        - name: ZONE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.labels['topology.kubernetes.io/zone']
         - name: NAMESPACE
           valueFrom:
             fieldRef:
@@ -59,6 +71,12 @@ spec:
         ports:
         - name: {{ .PortName }}
           containerPort: 3000
+        - name: echo-https
+          containerPort: 8443
+        - name: echo-h2c
+          containerPort: 3001
+        - name: echo-grpc
+          containerPort: 50051
         livenessProbe:
           httpGet:
             path: /health
@@ -91,7 +109,87 @@ spec:
     app: {{ .Selector }}
   ports:
     - port: {{ .Port }}
-      targetPort: 3000
+      targetPort: {{ .TargetPort }}
+`,
+	"job": `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .Namespace }}
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ingress-conformance
+  namespace: {{ .Namespace }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: ingress-conformance
+rules:
+  - apiGroups: [""]
+    resources: ["namespaces", "services", "secrets", "configmaps"]
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+  - apiGroups: [""]
+    resources: ["endpoints", "pods", "pods/log", "events", "nodes"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["apps"]
+    resources: ["deployments", "deployments/scale"]
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingresses"]
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingressclasses"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["gateway.networking.k8s.io"]
+    resources: ["gateways", "httproutes"]
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: ingress-conformance
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: ingress-conformance
+subjects:
+  - kind: ServiceAccount
+    name: ingress-conformance
+    namespace: {{ .Namespace }}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ingress-conformance
+  namespace: {{ .Namespace }}
+data:
+  FORMAT: "{{ .Format }}"
+  PROFILE: "{{ .Profile }}"
+  CONTROLLER_SELECTOR: "{{ .ControllerSelector }}"
+  INGRESS_CLASS: "{{ .IngressClass }}"
+  WAIT_FOR_STATUS_TIMEOUT: "{{ .WaitForStatusTimeout }}"
+  TEST_TIMEOUT: "{{ .TestTimeout }}"
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: ingress-conformance
+  namespace: {{ .Namespace }}
+spec:
+  backoffLimit: 0
+  template:
+    spec:
+      serviceAccountName: ingress-conformance
+      restartPolicy: Never
+      containers:
+      - name: ingress-conformance
+        image: {{ .Image }}
+        envFrom:
+        - configMapRef:
+            name: ingress-conformance
 `,
 }
 
@@ -126,3 +224,34 @@ func Render(name string, data interface{}) (string, error) {
 
 	return tpl.String(), nil
 }
+
+// ManifestValues contains the values available to the manifests defined in feature files
+type ManifestValues struct {
+	// Namespace of the scenario
+	Namespace string
+	// IngressClass name of the IngressClass of the ingress controller
+	IngressClass string
+	// HostSuffix domain suffix appended to the hostnames of the Ingress rules, unique to the run and the worker
+	HostSuffix string
+	// Worker index of the worker of the suite running the scenario, from 1, or 0 outside the scenarios
+	Worker int
+	// ClusterDomain DNS domain of the cluster, used in the DNS names of the services
+	ClusterDomain string
+}
+
+// RenderManifest executes a manifest as a template using the values, so
+// the same manifest can be used in clusters with different conventions
+func RenderManifest(manifest string, values *ManifestValues) (string, error) {
+	tmpl, err := text_template.New("manifest").Option("missingkey=error").Parse(manifest)
+	if err != nil {
+		return "", fmt.Errorf("parsing manifest template: %w", err)
+	}
+
+	var tpl bytes.Buffer
+	err = tmpl.Execute(&tpl, values)
+	if err != nil {
+		return "", fmt.Errorf("rendering manifest template: %w", err)
+	}
+
+	return tpl.String(), nil
+}
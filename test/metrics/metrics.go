@@ -0,0 +1,260 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes the results of the scenarios in the Prometheus text format,
+// to run the conformance suite continuously as a canary of an ingress controller.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// DurationBuckets upper bounds, in seconds, of the buckets of the duration histograms
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	scenarios = newCounter("ingress_conformance_scenarios_total",
+		"Number of scenarios run, by result", "feature", "scenario", "result")
+	scenarioDuration = newHistogram("ingress_conformance_scenario_duration_seconds",
+		"Duration of the scenarios", "feature", "scenario")
+	captureAttempts = newCounter("ingress_conformance_capture_attempts_total",
+		"Number of round trips attempted by the scenarios, by result", "feature", "scenario", "result")
+	roundTripDuration = newHistogram("ingress_conformance_round_trip_duration_seconds",
+		"Duration of the round trips captured by the scenarios", "feature", "scenario")
+)
+
+// Register records the result and duration of the scenarios run in the context
+func Register(ctx *godog.ScenarioContext) {
+	var startedAt time.Time
+
+	ctx.BeforeScenario(func(*godog.Scenario) {
+		startedAt = time.Now()
+	})
+
+	ctx.AfterScenario(func(sc *godog.Scenario, err error) {
+		result := "passed"
+		if err != nil {
+			result = "failed"
+		}
+
+		scenarios.inc(sc.Uri, sc.Name, result)
+		scenarioDuration.observe(time.Since(startedAt), sc.Uri, sc.Name)
+	})
+}
+
+// ObserveAttempt records a round trip attempted by a scenario, and its duration when it did not fail
+func ObserveAttempt(sc *godog.Scenario, duration time.Duration, err error) {
+	if sc == nil {
+		return
+	}
+
+	if err != nil {
+		captureAttempts.inc(sc.Uri, sc.Name, "error")
+		return
+	}
+
+	captureAttempts.inc(sc.Uri, sc.Name, "success")
+	roundTripDuration.observe(duration, sc.Uri, sc.Name)
+}
+
+// Serve exposes the metrics in the /metrics path of the address until the suite exits
+func Serve(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("listening for metrics requests: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Write(w)
+	})
+
+	go func() {
+		_ = http.Serve(listener, mux)
+	}()
+
+	return nil
+}
+
+// Write writes the metrics in the Prometheus text format
+func Write(w io.Writer) error {
+	for _, m := range []interface{ write(io.Writer) error }{scenarios, scenarioDuration, captureAttempts, roundTripDuration} {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// metric contains the name, help and label names shared by counters and histograms
+type metric struct {
+	mu sync.Mutex
+
+	name   string
+	help   string
+	labels []string
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// key returns the labels of a series in the Prometheus text format
+func (m *metric) key(values []string) string {
+	pairs := make([]string, len(m.labels))
+	for i, label := range m.labels {
+		pairs[i] = fmt.Sprintf("%v=\"%v\"", label, labelValueEscaper.Replace(values[i]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (m *metric) header(w io.Writer, kind string) error {
+	_, err := fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", m.name, m.help, m.name, kind)
+	return err
+}
+
+// counter is a counter with labels
+type counter struct {
+	metric
+	series map[string]float64
+}
+
+func newCounter(name, help string, labels ...string) *counter {
+	return &counter{
+		metric: metric{name: name, help: help, labels: labels},
+		series: map[string]float64{},
+	}
+}
+
+func (c *counter) inc(values ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.series[c.key(values)]++
+}
+
+func (c *counter) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.header(w, "counter"); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.series) {
+		if _, err := fmt.Fprintf(w, "%v{%v} %v\n", c.name, key, formatFloat(c.series[key])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// histogram is a histogram of durations with labels, using DurationBuckets
+type histogram struct {
+	metric
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(name, help string, labels ...string) *histogram {
+	return &histogram{
+		metric: metric{name: name, help: help, labels: labels},
+		series: map[string]*histogramSeries{},
+	}
+}
+
+func (h *histogram) observe(duration time.Duration, values ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := h.key(values)
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{buckets: make([]uint64, len(DurationBuckets))}
+		h.series[key] = series
+	}
+
+	seconds := duration.Seconds()
+	for i, upperBound := range DurationBuckets {
+		if seconds <= upperBound {
+			series.buckets[i]++
+		}
+	}
+
+	series.count++
+	series.sum += seconds
+}
+
+func (h *histogram) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.header(w, "histogram"); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var out strings.Builder
+	for _, key := range keys {
+		series := h.series[key]
+		for i, upperBound := range DurationBuckets {
+			fmt.Fprintf(&out, "%v_bucket{%v,le=%q} %v\n", h.name, key, formatFloat(upperBound), series.buckets[i])
+		}
+
+		fmt.Fprintf(&out, "%v_bucket{%v,le=\"+Inf\"} %v\n", h.name, key, series.count)
+		fmt.Fprintf(&out, "%v_sum{%v} %v\n", h.name, key, formatFloat(series.sum))
+		fmt.Fprintf(&out, "%v_count{%v} %v\n", h.name, key, series.count)
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func sortedKeys(series map[string]float64) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Comparison contains the results of the same scenarios in the reports of several ingress controllers
+type Comparison struct {
+	// Controllers contains the ingress controllers compared, in the order of the reports
+	Controllers []ControllerInfo
+	Profiles    []ComparedProfile
+	Scenarios   []ComparedScenario
+}
+
+// ComparedProfile contains the conformance of each ingress controller to a profile
+type ComparedProfile struct {
+	Name       Profile
+	Conformant []bool
+}
+
+// ComparedScenario contains the status of a scenario for each ingress controller.
+// The status is empty when the scenario is not in the report of the ingress controller.
+type ComparedScenario struct {
+	Feature  string
+	Name     string
+	Profile  Profile
+	Statuses []Status
+}
+
+// Label returns the name, version and IngressClass of an ingress controller
+func (c ControllerInfo) Label() string {
+	var parts []string
+	for _, part := range []string{c.Name, c.Version} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if c.IngressClass != "" {
+		parts = append(parts, fmt.Sprintf("(%v)", c.IngressClass))
+	}
+
+	if len(parts) == 0 {
+		return "unknown"
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Compare returns the results of the scenarios of the reports side by side, in the order they appear
+// in the reports. The examples of a scenario outline, with the same name, are matched by position.
+func Compare(reports []*ConformanceReport) *Comparison {
+	comparison := &Comparison{}
+
+	profiles := map[Profile]int{}
+	scenarios := map[string]int{}
+
+	for i, report := range reports {
+		comparison.Controllers = append(comparison.Controllers, report.Controller)
+
+		for _, profile := range report.Profiles {
+			index, ok := profiles[profile.Name]
+			if !ok {
+				index = len(comparison.Profiles)
+				profiles[profile.Name] = index
+				comparison.Profiles = append(comparison.Profiles, ComparedProfile{
+					Name:       profile.Name,
+					Conformant: make([]bool, len(reports)),
+				})
+			}
+
+			comparison.Profiles[index].Conformant[i] = profile.Conformant
+		}
+
+		for _, feature := range report.Features {
+			occurrences := map[string]int{}
+
+			for _, scenario := range feature.Scenarios {
+				key := fmt.Sprintf("%v\x00%v\x00%v", feature.Path, scenario.Name, occurrences[scenario.Name])
+				occurrences[scenario.Name]++
+
+				index, ok := scenarios[key]
+				if !ok {
+					index = len(comparison.Scenarios)
+					scenarios[key] = index
+					comparison.Scenarios = append(comparison.Scenarios, ComparedScenario{
+						Feature:  feature.Path,
+						Name:     scenario.Name,
+						Profile:  scenario.Profile,
+						Statuses: make([]Status, len(reports)),
+					})
+				}
+
+				comparison.Scenarios[index].Statuses[i] = scenario.Status
+			}
+		}
+	}
+
+	return comparison
+}
+
+// Differs returns whether the scenario does not have the same status for all the ingress controllers
+func (s ComparedScenario) Differs() bool {
+	for _, status := range s.Statuses {
+		if status != s.Statuses[0] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Regression is a scenario passing in the baseline report, the first one compared, and failing in another report
+type Regression struct {
+	Feature    string
+	Name       string
+	Controller ControllerInfo
+	Status     Status
+}
+
+// Regressions returns the scenarios passing in the first report that fail, are pending or are undefined
+// in the other reports. Scenarios missing from a report, or skipped, are not regressions.
+func (c *Comparison) Regressions() []Regression {
+	var regressions []Regression
+	for _, scenario := range c.Scenarios {
+		if len(scenario.Statuses) == 0 || scenario.Statuses[0] != Passed {
+			continue
+		}
+
+		for i, status := range scenario.Statuses[1:] {
+			switch status {
+			case Failed, Pending, Undefined:
+				regressions = append(regressions, Regression{
+					Feature:    scenario.Feature,
+					Name:       scenario.Name,
+					Controller: c.Controllers[i+1],
+					Status:     status,
+				})
+			}
+		}
+	}
+
+	return regressions
+}
+
+// RenderText writes the comparison as a table of text, one column per ingress controller
+func (c *Comparison) RenderText(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	header := []string{"FEATURE", "SCENARIO"}
+	for _, controller := range c.Controllers {
+		header = append(header, controller.Label())
+	}
+
+	fmt.Fprintln(table, strings.Join(header, "\t"))
+
+	for _, profile := range c.Profiles {
+		row := []string{"profile " + string(profile.Name), ""}
+		for _, conformant := range profile.Conformant {
+			row = append(row, map[bool]string{true: "conformant", false: "not conformant"}[conformant])
+		}
+
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+
+	for _, scenario := range c.Scenarios {
+		row := []string{scenario.Feature, scenario.Name}
+		for _, status := range scenario.Statuses {
+			if status == "" {
+				status = "-"
+			}
+
+			row = append(row, string(status))
+		}
+
+		fmt.Fprintln(table, strings.Join(row, "\t"))
+	}
+
+	return table.Flush()
+}
+
+var comparisonTemplate = template.Must(template.New("comparison").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Ingress controller conformance comparison</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { text-align: left; padding: 0.3em 0.8em; border-bottom: 1px solid #ddd; vertical-align: top; }
+tr.differs { background: #fff8c5; }
+.passed { color: #1a7f37; }
+.failed, .pending, .undefined { color: #cf222e; }
+.skipped, .unsupported, .expected-failure { color: #6e7781; }
+</style>
+</head>
+<body>
+<h1>Ingress controller conformance comparison</h1>
+
+<h2>Profiles</h2>
+<table>
+<tr><th>Profile</th>{{ range .Controllers }}<th>{{ .Label }}</th>{{ end }}</tr>
+{{- range .Profiles }}
+<tr><td>{{ .Name }}</td>{{ range .Conformant }}<td class="{{ if . }}passed{{ else }}failed{{ end }}">{{ if . }}yes{{ else }}no{{ end }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+
+<h2>Scenarios</h2>
+<p>Scenarios without the same status for all the ingress controllers are highlighted.</p>
+<table>
+<tr><th>Feature</th><th>Scenario</th><th>Profile</th>{{ range .Controllers }}<th>{{ .Label }}</th>{{ end }}</tr>
+{{- range .Scenarios }}
+<tr{{ if .Differs }} class="differs"{{ end }}><td>{{ .Feature }}</td><td>{{ .Name }}</td><td>{{ .Profile }}</td>{{ range .Statuses }}<td class="{{ . }}">{{ with . }}{{ . }}{{ else }}-{{ end }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes the comparison as a self-contained HTML page
+func (c *Comparison) WriteHTML(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := comparisonTemplate.Execute(file, c); err != nil {
+		return fmt.Errorf("rendering HTML comparison: %w", err)
+	}
+
+	return file.Close()
+}
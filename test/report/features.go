@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/cucumber/gherkin-go/v11"
+	"github.com/cucumber/messages-go/v10"
+)
+
+// ScenarioDefinition is a scenario defined in a feature file
+type ScenarioDefinition struct {
+	// Feature path of the feature file that contains the scenario
+	Feature     string `json:"feature"`
+	FeatureName string `json:"featureName"`
+
+	Name string `json:"name"`
+	// Line of the scenario in the feature file
+	Line    int      `json:"line"`
+	Tags    []string `json:"tags"`
+	Profile Profile  `json:"profile,omitempty"`
+	Steps   []string `json:"steps"`
+
+	// Fixtures required by the steps of the scenario
+	Fixtures []string `json:"fixtures"`
+	// DefaultBackend is true when a manifest of the scenario sets the default backend of an Ingress,
+	// which receives the requests without a matching rule of the scenarios run at the same time
+	DefaultBackend bool `json:"defaultBackend,omitempty"`
+}
+
+// fixtures contains the fixtures required by the scenarios, and the steps that require them
+var fixtures = []struct {
+	name string
+	step *regexp.Regexp
+}{
+	{"namespace", regexp.MustCompile(`new random namespace`)},
+	{"ingress", regexp.MustCompile(`^an Ingress resource|^a newer Ingress resource|Ingress resource is updated|Ingress resource is deleted`)},
+	{"backend-services", regexp.MustCompile(`^an Ingress resource|^a newer Ingress resource|default backend is the|^an ExternalName service|Ingress resource is updated`)},
+	{"tls-secret", regexp.MustCompile(`TLS secret`)},
+	{"default-ingress-class", regexp.MustCompile(`without class|default IngressClass`)},
+	{"deployment-scaling", regexp.MustCompile(`is scaled to|is restarted`)},
+	{"ip-address-family", regexp.MustCompile(`an "[^"]*" address`)},
+}
+
+// defaultBackendRegexp matches the manifests, or the Ingress specs, setting the default backend of an Ingress
+var defaultBackendRegexp = regexp.MustCompile(`(?m)^\s*defaultBackend:`)
+
+// ParseFeatures returns the scenarios defined in the feature files, in order.
+// Each example of a scenario outline is a different scenario.
+func ParseFeatures(paths []string) ([]*ScenarioDefinition, error) {
+	var scenarios []*ScenarioDefinition
+
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		document, err := gherkin.ParseGherkinDocument(bytes.NewReader(data), (&messages.Incrementing{}).NewId)
+		if err != nil {
+			return nil, fmt.Errorf("parsing feature %v: %w", path, err)
+		}
+
+		if document.Feature == nil {
+			continue
+		}
+
+		lines := map[string]int{}
+		for _, child := range document.Feature.Children {
+			if sc := child.GetScenario(); sc != nil {
+				lines[sc.Id] = int(sc.Location.Line)
+			}
+		}
+
+		for _, pickle := range gherkin.Pickles(*document, path, (&messages.Incrementing{}).NewId) {
+			scenario := &ScenarioDefinition{
+				Feature:     path,
+				FeatureName: document.Feature.Name,
+				Name:        pickle.Name,
+				Line:        lines[pickle.AstNodeIds[0]],
+			}
+
+			for _, tag := range pickle.Tags {
+				scenario.Tags = append(scenario.Tags, tag.Name)
+			}
+
+			for _, step := range pickle.Steps {
+				scenario.Steps = append(scenario.Steps, step.Text)
+
+				if docString := step.Argument.GetDocString(); docString != nil && defaultBackendRegexp.MatchString(docString.Content) {
+					scenario.DefaultBackend = true
+				}
+			}
+
+			for _, fixture := range fixtures {
+				for _, step := range scenario.Steps {
+					if fixture.step.MatchString(step) {
+						scenario.Fixtures = append(scenario.Fixtures, fixture.name)
+						break
+					}
+				}
+			}
+
+			scenario.Profile = profileOf(scenario.Tags)
+			scenarios = append(scenarios, scenario)
+		}
+	}
+
+	return scenarios, nil
+}
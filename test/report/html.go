@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"seconds": func(s float64) string {
+		return fmt.Sprintf("%.2fs", s)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Ingress controller conformance report{{ with .Controller.Name }} - {{ . }}{{ end }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { text-align: left; padding: 0.3em 0.8em; border-bottom: 1px solid #ddd; vertical-align: top; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; }
+details { margin: 0.3em 0 0.8em 1em; }
+.passed { color: #1a7f37; }
+.failed, .pending, .undefined { color: #cf222e; }
+.skipped, .unsupported, .expected-failure { color: #6e7781; }
+.warning, .warnings { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>Ingress controller conformance report</h1>
+
+<h2>Environment</h2>
+<table>
+<tr><th>Suite</th><td>{{ .Suite.Name }} {{ .Suite.Version }}</td></tr>
+<tr><th>Controller</th><td>{{ .Controller.Name }} {{ .Controller.Version }}</td></tr>
+<tr><th>Ingress class</th><td>{{ .Controller.IngressClass }}{{ with .Controller.Class }} ({{ . }}){{ end }}</td></tr>
+{{- with .Controller.Deployment }}
+<tr><th>Deployment</th><td>{{ . }} ({{ $.Controller.Replicas }} replicas)</td></tr>
+{{- end }}
+{{- with .Controller.Images }}
+<tr><th>Images</th><td>{{ range . }}{{ . }}<br>{{ end }}</td></tr>
+{{- end }}
+{{- range $key, $value := .Environment }}
+<tr><th>{{ $key }}</th><td>{{ $value }}</td></tr>
+{{- end }}
+<tr><th>Started at</th><td>{{ .StartedAt.Format "2006-01-02 15:04:05 MST" }}</td></tr>
+<tr><th>Duration</th><td>{{ seconds .DurationSeconds }}</td></tr>
+</table>
+
+<h2>Profiles</h2>
+<table>
+<tr><th>Profile</th><th>Conformant</th><th>Scenarios</th></tr>
+{{- range .Profiles }}
+<tr><td>{{ .Name }}</td><td class="{{ if .Conformant }}passed{{ else }}failed{{ end }}">{{ if .Conformant }}yes{{ else }}no{{ end }}</td><td>{{ range $status, $count := .Summary.Status }}<span class="{{ $status }}">{{ $count }} {{ $status }}</span> {{ end }}</td></tr>
+{{- end }}
+</table>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Feature</th><th>Status</th><th>Scenarios</th></tr>
+{{- range $i, $feature := .Features }}
+<tr><td><a href="#feature-{{ $i }}">{{ .Path }}</a></td><td class="{{ .Status }}">{{ .Status }}</td><td>{{ range $status, $count := .Summary.Status }}<span class="{{ $status }}">{{ $count }} {{ $status }}</span> {{ end }}</td></tr>
+{{- end }}
+<tr><th>Total</th><td></td><td>{{ range $status, $count := .Summary.Status }}<span class="{{ $status }}">{{ $count }} {{ $status }}</span> {{ end }}{{ with .Summary.Warnings }}<span class="warning">{{ . }} warnings</span>{{ end }}</td></tr>
+</table>
+
+{{- range $i, $feature := .Features }}
+<h2 id="feature-{{ $i }}">{{ .Path }}</h2>
+{{- range .Scenarios }}
+<h3><span class="{{ .Status }}">[{{ .Status }}]</span> {{ .Name }}{{ with .Profile }} [{{ . }}]{{ end }} ({{ seconds .DurationSeconds }})</h3>
+{{- with .Reason }}<p>{{ . }}</p>{{ end }}
+{{- with .Warnings }}
+<ul class="warnings">
+{{- range . }}
+<li>Warning: {{ .Message }}{{ with .Step }} ({{ . }}){{ end }}</li>
+{{- end }}
+</ul>
+{{- end }}
+<table>
+{{- range .Steps }}
+<tr><td class="{{ .Status }}">{{ .Status }}</td><td>{{ .Text }}{{ with .Error }}<pre>{{ . }}</pre>{{ end }}</td><td>{{ seconds .DurationSeconds }}</td></tr>
+{{- end }}
+</table>
+{{- range .Attachments }}
+<details><summary>{{ .Name }}</summary><pre>{{ .Content }}</pre></details>
+{{- end }}
+{{- end }}
+{{- end }}
+</body>
+</html>
+`))
+
+// RenderHTML writes a self-contained HTML page with the results of the report
+func RenderHTML(w io.Writer, report *ConformanceReport) error {
+	return htmlTemplate.Execute(w, report)
+}
+
+// WriteHTML writes the results as a self-contained HTML page
+func (r *Report) WriteHTML(path string) error {
+	return writeHTML(path, r.ConformanceReport())
+}
+
+// WriteHTMLFromJSON writes a self-contained HTML page with the results of a JSON report
+func WriteHTMLFromJSON(jsonPath, path string) error {
+	report, err := LoadJSON(jsonPath)
+	if err != nil {
+		return err
+	}
+
+	return writeHTML(path, report)
+}
+
+func writeHTML(path string, report *ConformanceReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := RenderHTML(file, report); err != nil {
+		return fmt.Errorf("rendering HTML report: %w", err)
+	}
+
+	return file.Close()
+}
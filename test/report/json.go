@@ -0,0 +1,275 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// ConformanceReportVersion version of the ConformanceReport format, described by the schema conformance-report.schema.json
+const ConformanceReportVersion = "v1alpha1"
+
+var (
+	// SuiteVersion version of the conformance suite
+	SuiteVersion = "dev"
+
+	// ControllerName name of the ingress controller tested
+	ControllerName = ""
+	// ControllerVersion version of the ingress controller tested
+	ControllerVersion = ""
+	// IngressClass name of the IngressClass of the ingress controller tested
+	IngressClass = ""
+	// ControllerClass value of spec.controller in the IngressClass of the ingress controller tested
+	ControllerClass = ""
+	// ControllerDeployment namespace/name of the deployment of the ingress controller tested
+	ControllerDeployment = ""
+	// ControllerImages images of the containers of the ingress controller tested
+	ControllerImages []string
+	// ControllerReplicas number of replicas of the deployment of the ingress controller tested
+	ControllerReplicas int32
+
+	// Environment contains details about the environment of the run, like the Kubernetes version
+	Environment = map[string]string{}
+)
+
+// ConformanceReport is the machine-readable report of a run of the conformance suite
+type ConformanceReport struct {
+	APIVersion string `json:"apiVersion"`
+
+	Suite      SuiteInfo      `json:"suite"`
+	Controller ControllerInfo `json:"controller"`
+
+	Environment map[string]string `json:"environment,omitempty"`
+
+	StartedAt       time.Time `json:"startedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+
+	Summary Summary `json:"summary"`
+	// Profiles contains the results of the profiles included in the tested profile
+	Profiles []ProfileResult `json:"profiles"`
+	Features []FeatureResult `json:"features"`
+
+	// SlowestSteps contains the slowest steps of the run, the slowest first
+	SlowestSteps []SlowStep `json:"slowestSteps,omitempty"`
+
+	// Stability contains the results of the scenarios run several times to find flaky scenarios
+	Stability []ScenarioStability `json:"stability,omitempty"`
+}
+
+// SuiteInfo identifies the conformance suite
+type SuiteInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ControllerInfo identifies the ingress controller tested
+type ControllerInfo struct {
+	Name         string `json:"name,omitempty"`
+	Version      string `json:"version,omitempty"`
+	IngressClass string `json:"ingressClass,omitempty"`
+	// Class value of spec.controller in the IngressClass
+	Class string `json:"class,omitempty"`
+
+	// Deployment namespace/name of the deployment of the ingress controller
+	Deployment string   `json:"deployment,omitempty"`
+	Images     []string `json:"images,omitempty"`
+	Replicas   int32    `json:"replicas,omitempty"`
+}
+
+// Summary contains the number of scenarios of each status
+type Summary struct {
+	Total  int            `json:"total"`
+	Status map[Status]int `json:"status"`
+	// Warnings number of warnings of the scenarios
+	Warnings int `json:"warnings,omitempty"`
+}
+
+func (s *Summary) add(status Status) {
+	if s.Status == nil {
+		s.Status = map[Status]int{}
+	}
+
+	s.Total++
+	s.Status[status]++
+}
+
+// FeatureResult contains the results of the scenarios of a feature
+type FeatureResult struct {
+	// Path of the feature file
+	Path      string           `json:"path"`
+	Status    Status           `json:"status"`
+	Summary   Summary          `json:"summary"`
+	Scenarios []ScenarioResult `json:"scenarios"`
+}
+
+// ScenarioResult contains the result of a scenario
+type ScenarioResult struct {
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags,omitempty"`
+	Profile Profile  `json:"profile,omitempty"`
+	Status  Status   `json:"status"`
+	Error   string   `json:"error,omitempty"`
+	// Reason explains why the scenario was not run, or was expected to fail
+	Reason string `json:"reason,omitempty"`
+	// Repetition number of the run of the scenario, when the scenarios are run several times
+	Repetition int `json:"repetition,omitempty"`
+
+	StartedAt       time.Time `json:"startedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+
+	Steps       []StepResult `json:"steps"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Warnings contains the expectations not met that do not fail the scenario, like best practices
+	Warnings []Warning `json:"warnings,omitempty"`
+}
+
+// StepResult contains the result of a step of a scenario
+type StepResult struct {
+	Text            string  `json:"text"`
+	Status          Status  `json:"status"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+
+	Convergences []ConvergenceResult `json:"convergences,omitempty"`
+}
+
+// ConvergenceResult contains a wait of a step for a route to converge
+type ConvergenceResult struct {
+	// Target method and URL of the requests
+	Target          string  `json:"target"`
+	Attempts        int     `json:"attempts"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Converged       bool    `json:"converged"`
+}
+
+// ConformanceReport returns the machine-readable report of the results
+func (r *Report) ConformanceReport() *ConformanceReport {
+	report := &ConformanceReport{
+		APIVersion: ConformanceReportVersion,
+		Suite: SuiteInfo{
+			Name:    "ingress-controller-conformance",
+			Version: SuiteVersion,
+		},
+		Controller: ControllerInfo{
+			Name:         ControllerName,
+			Version:      ControllerVersion,
+			IngressClass: IngressClass,
+			Class:        ControllerClass,
+			Deployment:   ControllerDeployment,
+			Images:       ControllerImages,
+			Replicas:     ControllerReplicas,
+		},
+		Environment:     Environment,
+		StartedAt:       r.StartedAt,
+		DurationSeconds: time.Since(r.StartedAt).Seconds(),
+		Features:        []FeatureResult{},
+	}
+
+	features, scenarios := r.Features()
+	for _, feature := range features {
+		featureResult := FeatureResult{
+			Path:   feature,
+			Status: Passed,
+		}
+
+		for _, scenario := range scenarios[feature] {
+			scenarioResult := ScenarioResult{
+				Name:            scenario.Name,
+				Tags:            scenario.Tags,
+				Profile:         scenario.Profile,
+				Status:          scenario.Status,
+				Error:           scenario.Error,
+				Reason:          scenario.Reason,
+				Repetition:      scenario.Repetition,
+				StartedAt:       scenario.StartedAt,
+				DurationSeconds: scenario.Duration.Seconds(),
+				Steps:           []StepResult{},
+				Attachments:     scenario.Attachments,
+				Warnings:        scenario.Warnings,
+			}
+
+			for _, step := range scenario.Steps {
+				stepResult := StepResult{
+					Text:            step.Text,
+					Status:          step.Status,
+					Error:           step.Error,
+					DurationSeconds: step.Duration.Seconds(),
+				}
+
+				for _, convergence := range step.Convergences {
+					stepResult.Convergences = append(stepResult.Convergences, ConvergenceResult{
+						Target:          convergence.Target,
+						Attempts:        convergence.Attempts,
+						DurationSeconds: convergence.Duration.Seconds(),
+						Converged:       convergence.Converged,
+					})
+				}
+
+				scenarioResult.Steps = append(scenarioResult.Steps, stepResult)
+			}
+
+			if failed(scenario.Status) {
+				featureResult.Status = Failed
+			}
+
+			featureResult.Summary.add(scenario.Status)
+			report.Summary.add(scenario.Status)
+			featureResult.Summary.Warnings += len(scenario.Warnings)
+			report.Summary.Warnings += len(scenario.Warnings)
+			featureResult.Scenarios = append(featureResult.Scenarios, scenarioResult)
+		}
+
+		report.Features = append(report.Features, featureResult)
+	}
+
+	r.mu.Lock()
+	report.Profiles = profileResults(r.Scenarios)
+	r.mu.Unlock()
+
+	report.Stability = r.Stability()
+	report.SlowestSteps = r.SlowestSteps(SlowestStepsCount)
+
+	return report
+}
+
+// WriteJSON writes the results as a ConformanceReport in JSON format
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r.ConformanceReport(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializing JSON report: %w", err)
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// LoadJSON reads a ConformanceReport in JSON format
+func LoadJSON(path string) (*ConformanceReport, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ConformanceReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("reading JSON report %v: %w", path, err)
+	}
+
+	return report, nil
+}
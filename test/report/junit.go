@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, with a test suite for each feature
+// and a test case for each scenario. The steps and attachments of each scenario are
+// available in the system-out element of the test case.
+func (r *Report) WriteJUnit(path string) error {
+	suites := junitTestSuites{
+		Name: "ingress-controller-conformance",
+		Time: junitTime(time.Since(r.StartedAt)),
+	}
+
+	features, scenarios := r.Features()
+	for _, feature := range features {
+		suite := junitTestSuite{
+			Name:      feature,
+			Timestamp: scenarios[feature][0].StartedAt.Format("2006-01-02T15:04:05"),
+		}
+
+		var duration time.Duration
+		for _, scenario := range scenarios[feature] {
+			testCase := junitTestCase{
+				Name:      scenario.Name,
+				ClassName: feature,
+				Time:      junitTime(scenario.Duration),
+				SystemOut: scenarioOutput(scenario),
+			}
+
+			switch {
+			case scenario.Status == Unsupported || knownGap(scenario.Status):
+				testCase.Skipped = &junitSkipped{Message: scenario.Reason}
+				suite.Skipped++
+			case scenario.Status != Passed:
+				testCase.Failure = &junitFailure{
+					Message:  scenario.Error,
+					Type:     string(scenario.Status),
+					Contents: failedStep(scenario),
+				}
+
+				suite.Failures++
+			}
+
+			suite.Tests++
+			duration += scenario.Duration
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Time = junitTime(duration)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializing JUnit report: %w", err)
+	}
+
+	return ioutil.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
+
+// junitTime returns a duration in seconds
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// failedStep returns the text of the first step of the scenario that did not pass
+func failedStep(scenario *Scenario) string {
+	for _, step := range scenario.Steps {
+		if step.Status != Passed {
+			return step.Text
+		}
+	}
+
+	return ""
+}
+
+// scenarioOutput returns the steps and attachments of a scenario as text
+func scenarioOutput(scenario *Scenario) string {
+	var out strings.Builder
+	for _, step := range scenario.Steps {
+		fmt.Fprintf(&out, "[%v] %v (%v)\n", step.Status, step.Text, step.Duration)
+		if step.Error != "" {
+			fmt.Fprintf(&out, "    %v\n", step.Error)
+		}
+	}
+
+	for _, warning := range scenario.Warnings {
+		fmt.Fprintf(&out, "\nWarning: %v (%v)\n", warning.Message, warning.Step)
+	}
+
+	for _, attachment := range scenario.Attachments {
+		fmt.Fprintf(&out, "\n%v:\n%v\n", attachment.Name, attachment.Content)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Profile groups the scenarios of the suite by the level of support expected from
+// ingress controllers. Each feature is tagged with the profile of its scenarios.
+type Profile string
+
+const (
+	// Core scenarios must pass in every conformant ingress controller
+	Core Profile = "core"
+	// Extended scenarios are optional, portable across ingress controllers that implement them
+	Extended Profile = "extended"
+	// Experimental scenarios describe behavior that is not stable yet
+	Experimental Profile = "experimental"
+)
+
+// Profiles contains the valid profiles, each one includes the previous ones
+var Profiles = []Profile{Core, Extended, Experimental}
+
+// SelectedProfile is the profile tested by the suite
+var SelectedProfile = Experimental
+
+// ParseProfile returns the profile with the given name
+func ParseProfile(name string) (Profile, error) {
+	for _, profile := range Profiles {
+		if string(profile) == name {
+			return profile, nil
+		}
+	}
+
+	return "", fmt.Errorf("the profile '%v' is not supported (valid values are core, extended and experimental)", name)
+}
+
+// Tag returns the godog tag of the scenarios of the profile
+func (p Profile) Tag() string {
+	return "@" + string(p)
+}
+
+// Includes returns true if the scenarios of other profile are tested as part of the profile
+func (p Profile) Includes(other Profile) bool {
+	return other != "" && p.level() >= other.level()
+}
+
+func (p Profile) level() int {
+	for i, profile := range Profiles {
+		if profile == p {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ProfileTags returns the godog tag expression that selects the scenarios of the profile
+func ProfileTags(profile Profile) string {
+	var tags []string
+	for _, p := range Profiles {
+		if profile.Includes(p) {
+			tags = append(tags, p.Tag())
+		}
+	}
+
+	return strings.Join(tags, ",")
+}
+
+// profileOf returns the profile of the scenario with the given tags, empty when it has none
+func profileOf(tags []string) Profile {
+	for _, tag := range tags {
+		for _, profile := range Profiles {
+			if tag == profile.Tag() {
+				return profile
+			}
+		}
+	}
+
+	return ""
+}
+
+// ProfileResult contains the results of the scenarios of a profile. A profile is
+// conformant when all its scenarios, and the ones of the profiles it includes, passed
+// or were not run because their features are not supported. Known gaps of the skip list,
+// skipped or expected to fail, prevent claiming conformance.
+type ProfileResult struct {
+	Name       Profile `json:"name"`
+	Conformant bool    `json:"conformant"`
+	Summary    Summary `json:"summary"`
+}
+
+// profileResults returns the results of the profiles included in the selected profile
+func profileResults(scenarios []*Scenario) []ProfileResult {
+	results := []ProfileResult{}
+
+	conformant := true
+	for _, profile := range Profiles {
+		if !SelectedProfile.Includes(profile) {
+			break
+		}
+
+		result := ProfileResult{Name: profile}
+		for _, scenario := range scenarios {
+			if scenario.Profile != profile {
+				continue
+			}
+
+			result.Summary.add(scenario.Status)
+			if failed(scenario.Status) || knownGap(scenario.Status) {
+				conformant = false
+			}
+		}
+
+		result.Conformant = conformant && result.Summary.Status[Passed] != 0
+		results = append(results, result)
+	}
+
+	return results
+}
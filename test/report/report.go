@@ -0,0 +1,335 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// Status of a scenario or step
+type Status string
+
+const (
+	// Passed the scenario or step did not return an error
+	Passed Status = "passed"
+	// Failed the scenario or step returned an error
+	Failed Status = "failed"
+	// Skipped the step was not run because a previous step did not pass, or the scenario is in the skip list
+	Skipped Status = "skipped"
+	// Pending the step is not implemented yet
+	Pending Status = "pending"
+	// Undefined the step does not have a definition
+	Undefined Status = "undefined"
+	// Unsupported the scenario was not run because the ingress controller does not support its feature
+	Unsupported Status = "unsupported"
+	// ExpectedFailure the scenario failed and is listed as expected to fail in the skip list
+	ExpectedFailure Status = "expected-failure"
+)
+
+// Step contains the result of a step of a scenario
+type Step struct {
+	Text     string
+	Status   Status
+	Error    string
+	Duration time.Duration
+
+	// Convergences contains the waits of the step for routes to converge
+	Convergences []Convergence
+}
+
+// Scenario contains the result of a scenario of a feature
+type Scenario struct {
+	// Feature path of the feature file that contains the scenario
+	Feature string
+	Name    string
+	Tags    []string
+	// Profile of the scenario, from its tags
+	Profile Profile
+
+	Status Status
+	Error  string
+	// Reason explains why the scenario was not run, or was expected to fail
+	Reason string
+
+	// Repetition number of the run of the scenario, when the scenarios are run several times
+	Repetition int
+
+	StartedAt time.Time
+	Duration  time.Duration
+
+	Steps []*Step
+
+	// Attachments contains information useful to understand failures, like the captured round trips
+	Attachments []Attachment
+
+	// Warnings contains the expectations not met that do not fail the scenario, like best practices
+	Warnings []Warning
+
+	// current is the step running
+	current *Step
+}
+
+// Attachment contains information attached to the result of a scenario
+type Attachment struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Warning is an expectation of a step that was not met, without failing the scenario
+type Warning struct {
+	Step    string `json:"step"`
+	Message string `json:"message"`
+}
+
+// Report contains the results of the scenarios run by the suite
+type Report struct {
+	mu sync.Mutex
+
+	StartedAt time.Time
+	Scenarios []*Scenario
+}
+
+// Results contains the results of the scenarios run by the suite
+var Results = &Report{StartedAt: time.Now()}
+
+// RequiredTag marks the features whose scenarios are required, like the ones of the core profile
+const RequiredTag = "@required"
+
+// OnRequiredFailure is called after each failure of a required scenario. Nil when the suite runs all the scenarios.
+var OnRequiredFailure func(scenario *Scenario)
+
+// running contains the results of the scenarios running, features can run concurrently
+var running = struct {
+	sync.Mutex
+	scenarios map[*godog.Scenario]*Scenario
+}{
+	scenarios: map[*godog.Scenario]*Scenario{},
+}
+
+// Attach adds information to the result of a running scenario
+func Attach(sc *godog.Scenario, name, content string) {
+	running.Lock()
+	defer running.Unlock()
+
+	scenario, ok := running.scenarios[sc]
+	if !ok {
+		return
+	}
+
+	scenario.Attachments = append(scenario.Attachments, Attachment{Name: name, Content: content})
+}
+
+// Warn adds a warning of the running step to the result of a running scenario
+func Warn(sc *godog.Scenario, message string) {
+	running.Lock()
+	defer running.Unlock()
+
+	scenario, ok := running.scenarios[sc]
+	if !ok {
+		return
+	}
+
+	warning := Warning{Message: message}
+	if scenario.current != nil {
+		warning.Step = scenario.current.Text
+	}
+
+	scenario.Warnings = append(scenario.Warnings, warning)
+}
+
+// Warnings returns the scenarios with warnings, in the order they were run
+func (r *Report) Warnings() []*Scenario {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var scenarios []*Scenario
+	for _, scenario := range r.Scenarios {
+		if len(scenario.Warnings) != 0 {
+			scenarios = append(scenarios, scenario)
+		}
+	}
+
+	return scenarios
+}
+
+// Register records the result of the scenarios and steps run in the context
+func Register(ctx *godog.ScenarioContext) {
+	var scenario *Scenario
+	var steps map[string]*Step
+	var stepStartedAt time.Time
+
+	ctx.BeforeScenario(func(sc *godog.Scenario) {
+		scenario = &Scenario{
+			Feature:    sc.Uri,
+			Name:       sc.Name,
+			Status:     Passed,
+			Repetition: Repetition,
+			StartedAt:  time.Now(),
+		}
+
+		for _, tag := range sc.Tags {
+			scenario.Tags = append(scenario.Tags, tag.Name)
+		}
+
+		scenario.Profile = profileOf(scenario.Tags)
+
+		// steps are skipped until they run
+		steps = map[string]*Step{}
+		for _, st := range sc.Steps {
+			step := &Step{Text: st.Text, Status: Skipped}
+			scenario.Steps = append(scenario.Steps, step)
+			steps[st.Id] = step
+		}
+
+		running.Lock()
+		running.scenarios[sc] = scenario
+		running.Unlock()
+	})
+
+	ctx.BeforeStep(func(st *godog.Step) {
+		stepStartedAt = time.Now()
+
+		running.Lock()
+		scenario.current = steps[st.Id]
+		running.Unlock()
+	})
+
+	ctx.AfterStep(func(st *godog.Step, err error) {
+		step, ok := steps[st.Id]
+		if !ok {
+			return
+		}
+
+		step.Duration = time.Since(stepStartedAt)
+		step.Status = status(err)
+		if err != nil {
+			step.Error = err.Error()
+		}
+	})
+
+	ctx.AfterScenario(func(sc *godog.Scenario, err error) {
+		scenario.Duration = time.Since(scenario.StartedAt)
+		scenario.Status = status(err)
+		switch {
+		case scenario.Status == Unsupported:
+			scenario.Reason = err.Error()
+		case err != nil:
+			scenario.Error = err.Error()
+		}
+
+		KnownGaps.expectFailure(scenario)
+
+		running.Lock()
+		delete(running.scenarios, sc)
+		running.Unlock()
+
+		Results.add(scenario)
+
+		if OnRequiredFailure != nil && failed(scenario.Status) && scenario.Required() {
+			OnRequiredFailure(scenario)
+		}
+	})
+}
+
+// Required returns true if the scenario is of the core profile or of a feature tagged @required
+func (s *Scenario) Required() bool {
+	if s.Profile == Core {
+		return true
+	}
+
+	for _, tag := range s.Tags {
+		if tag == RequiredTag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Report) add(scenario *Scenario) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Scenarios = append(r.Scenarios, scenario)
+}
+
+// Features returns the features of the report, in the order they were run, with their scenarios
+func (r *Report) Features() ([]string, map[string][]*Scenario) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var features []string
+	scenarios := map[string][]*Scenario{}
+
+	for _, scenario := range r.Scenarios {
+		if _, ok := scenarios[scenario.Feature]; !ok {
+			features = append(features, scenario.Feature)
+		}
+
+		scenarios[scenario.Feature] = append(scenarios[scenario.Feature], scenario)
+	}
+
+	return features, scenarios
+}
+
+// failed returns true if the status of a scenario prevents claiming conformance
+func failed(status Status) bool {
+	return status == Failed || status == Pending || status == Undefined
+}
+
+// UnsupportedError is implemented by the errors of the scenarios that cannot run because
+// the ingress controller does not support their feature, like an annotation without translation
+type UnsupportedError interface {
+	error
+	Unsupported() bool
+}
+
+// UnsupportedFeature is embedded by the errors of the features not supported by the ingress controller,
+// so they implement UnsupportedError and godog does not count the scenarios returning them as failed
+type UnsupportedFeature struct{}
+
+// Unsupported marks the error as caused by a feature not supported by the ingress controller
+func (UnsupportedFeature) Unsupported() bool {
+	return true
+}
+
+// Unwrap returns godog.ErrPending, so godog does not count the scenario as failed
+func (UnsupportedFeature) Unwrap() error {
+	return godog.ErrPending
+}
+
+// status returns the status of a scenario or step that returned the error
+func status(err error) Status {
+	var unsupported UnsupportedError
+
+	switch {
+	case err == nil:
+		return Passed
+	case errors.As(err, &unsupported) && unsupported.Unsupported():
+		return Unsupported
+	case errors.Is(err, godog.ErrPending):
+		return Pending
+	case errors.Is(err, godog.ErrUndefined):
+		return Undefined
+	}
+
+	return Failed
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SkipList declares the known gaps of an ingress controller: scenarios that are not run, and scenarios
+// expected to fail, each with a reason and the URL of the issue tracking it. Both are reported distinctly,
+// do not fail the suite and prevent claiming the conformance to their profile.
+type SkipList struct {
+	Skip             []ListedScenario `json:"skip"`
+	ExpectedFailures []ListedScenario `json:"expectedFailures"`
+}
+
+// ListedScenario identifies scenarios of a skip list
+type ListedScenario struct {
+	// Feature path or name of the feature file (e.g. host_rules)
+	Feature string `json:"feature"`
+	// Scenario name of the scenario, all the scenarios of the feature when empty
+	Scenario string `json:"scenario,omitempty"`
+
+	Reason string `json:"reason"`
+	// URL of the issue tracking the gap
+	URL string `json:"url,omitempty"`
+}
+
+// KnownGaps contains the skip list of the run, empty when there is none
+var KnownGaps = &SkipList{}
+
+// LoadSkipList reads a skip list from a YAML file
+func LoadSkipList(path string) (*SkipList, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &SkipList{}
+	if err := yaml.UnmarshalStrict(data, list); err != nil {
+		return nil, fmt.Errorf("reading skip list %v: %w", path, err)
+	}
+
+	for _, listed := range append(list.Skip, list.ExpectedFailures...) {
+		if listed.Feature == "" || listed.Reason == "" {
+			return nil, fmt.Errorf("reading skip list %v: the feature and the reason of each scenario are required", path)
+		}
+	}
+
+	return list, nil
+}
+
+// matches returns true if the listed scenario identifies the scenario of the feature file
+func (l ListedScenario) matches(feature, name string) bool {
+	base := filepath.Base(feature)
+	if l.Feature != feature && l.Feature != base && l.Feature != strings.TrimSuffix(base, ".feature") {
+		return false
+	}
+
+	return l.Scenario == "" || l.Scenario == name
+}
+
+// explanation returns the reason of the listed scenario, followed by its URL
+func (l ListedScenario) explanation() string {
+	if l.URL == "" {
+		return l.Reason
+	}
+
+	return fmt.Sprintf("%v (%v)", l.Reason, l.URL)
+}
+
+func find(list []ListedScenario, feature, name string) *ListedScenario {
+	for i := range list {
+		if list[i].matches(feature, name) {
+			return &list[i]
+		}
+	}
+
+	return nil
+}
+
+// RecordSkipped records the scenarios selected by the tag expression that are not run because
+// they are skipped, and returns the other scenarios. The scenarios are run by line, so the
+// examples of a scenario outline are skipped together.
+func (s *SkipList) RecordSkipped(scenarios []*ScenarioDefinition, filter string) []*ScenarioDefinition {
+	skipped := map[string]*ListedScenario{}
+	for _, definition := range scenarios {
+		if listed := find(s.Skip, definition.Feature, definition.Name); listed != nil {
+			skipped[fmt.Sprintf("%v:%v", definition.Feature, definition.Line)] = listed
+		}
+	}
+
+	var run []*ScenarioDefinition
+	for _, definition := range scenarios {
+		listed, ok := skipped[fmt.Sprintf("%v:%v", definition.Feature, definition.Line)]
+		if !ok {
+			run = append(run, definition)
+			continue
+		}
+
+		if !matchesTags(filter, definition.Tags) {
+			continue
+		}
+
+		scenario := &Scenario{
+			Feature:   definition.Feature,
+			Name:      definition.Name,
+			Tags:      definition.Tags,
+			Profile:   definition.Profile,
+			Status:    Skipped,
+			Reason:    "skipped: " + listed.explanation(),
+			StartedAt: time.Now(),
+		}
+
+		for _, step := range definition.Steps {
+			scenario.Steps = append(scenario.Steps, &Step{Text: step, Status: Skipped})
+		}
+
+		Results.add(scenario)
+	}
+
+	return run
+}
+
+// expectFailure marks the scenario as an expected failure when it failed and is listed
+// as expected to fail, and notes when a scenario listed as expected to fail passed
+func (s *SkipList) expectFailure(scenario *Scenario) {
+	listed := find(s.ExpectedFailures, scenario.Feature, scenario.Name)
+	if listed == nil {
+		return
+	}
+
+	switch {
+	case failed(scenario.Status):
+		scenario.Status = ExpectedFailure
+		scenario.Reason = "expected failure: " + listed.explanation()
+	case scenario.Status == Passed:
+		scenario.Reason = "passed although listed as an expected failure: " + listed.explanation()
+	}
+}
+
+// OnlyExpectedFailures returns true if the scenarios of the feature run in the repetition that
+// did not pass are all expected failures, so the failures reported by godog do not fail the suite
+func (r *Report) OnlyExpectedFailures(feature string, repetition int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expected := false
+	for _, scenario := range r.Scenarios {
+		if scenario.Feature != feature || scenario.Repetition != repetition {
+			continue
+		}
+
+		if failed(scenario.Status) {
+			return false
+		}
+
+		if scenario.Status == ExpectedFailure {
+			expected = true
+		}
+	}
+
+	return expected
+}
+
+// knownGap returns true if the scenario is in the skip list, because it is skipped or expected to fail
+func knownGap(status Status) bool {
+	return status == Skipped || status == ExpectedFailure
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SonobuoyResultsFile name of the file that contains the results in the Sonobuoy manual results format
+const SonobuoyResultsFile = "sonobuoy_results.yaml"
+
+// sonobuoyItem is an item of the Sonobuoy manual results format.
+// https://sonobuoy.io/docs/main/results/#manual-results-format
+type sonobuoyItem struct {
+	Name    string            `json:"name"`
+	Status  Status            `json:"status"`
+	Meta    map[string]string `json:"meta,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+	Items   []sonobuoyItem    `json:"items,omitempty"`
+}
+
+// WriteSonobuoy writes the results in the Sonobuoy manual results format in the directory,
+// with an item for each feature containing an item for each scenario and step.
+func (r *Report) WriteSonobuoy(dir string) error {
+	results := sonobuoyItem{
+		Name:   "ingress-controller-conformance",
+		Status: Passed,
+	}
+
+	features, scenarios := r.Features()
+	for _, feature := range features {
+		featureItem := sonobuoyItem{
+			Name:   feature,
+			Status: Passed,
+		}
+
+		for _, scenario := range scenarios[feature] {
+			scenarioItem := sonobuoyItem{
+				Name:   scenario.Name,
+				Status: sonobuoyStatus(scenario.Status),
+				Meta: map[string]string{
+					"duration": scenario.Duration.String(),
+				},
+			}
+
+			if scenario.Error != "" {
+				scenarioItem.Details = map[string]string{"error": scenario.Error}
+			}
+
+			for _, step := range scenario.Steps {
+				stepItem := sonobuoyItem{
+					Name:   step.Text,
+					Status: sonobuoyStatus(step.Status),
+				}
+
+				if step.Error != "" {
+					stepItem.Details = map[string]string{"error": step.Error}
+				}
+
+				scenarioItem.Items = append(scenarioItem.Items, stepItem)
+			}
+
+			if scenarioItem.Status == Failed {
+				featureItem.Status = Failed
+				results.Status = Failed
+			}
+
+			featureItem.Items = append(featureItem.Items, scenarioItem)
+		}
+
+		results.Items = append(results.Items, featureItem)
+	}
+
+	data, err := yaml.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("serializing sonobuoy results: %w", err)
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, SonobuoyResultsFile), data, 0644)
+}
+
+// sonobuoyStatus returns the Sonobuoy status of a scenario or step. Sonobuoy only
+// supports passed, failed and skipped, so pending and undefined steps are failures
+// and unsupported scenarios and expected failures are skipped.
+func sonobuoyStatus(status Status) Status {
+	switch status {
+	case Pending, Undefined:
+		return Failed
+	case Unsupported, ExpectedFailure:
+		return Skipped
+	}
+
+	return status
+}
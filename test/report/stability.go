@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Repetition is the number of the run of the scenarios, from one, when the suite runs them several
+// times to find flaky scenarios. It is zero when the scenarios are run once.
+var Repetition int
+
+// ScenarioStability contains the results of the runs of a scenario repeated several times
+type ScenarioStability struct {
+	// Feature path of the feature file that contains the scenario
+	Feature string `json:"feature"`
+	Name    string `json:"name"`
+
+	Runs     int            `json:"runs"`
+	Statuses map[Status]int `json:"statuses"`
+	// PassRatio fraction of the runs that passed
+	PassRatio float64 `json:"passRatio"`
+
+	MeanDurationSeconds   float64 `json:"meanDurationSeconds"`
+	StdDevDurationSeconds float64 `json:"stdDevDurationSeconds"`
+
+	// Flaky is true when the runs of the scenario do not all have the same status
+	Flaky bool `json:"flaky"`
+}
+
+// Stability returns the results of the scenarios run several times, in the order they were first run.
+// The examples of a scenario outline, with the same name, are matched by position in each run.
+// It returns nil when the scenarios were run once.
+func (r *Report) Stability() []ScenarioStability {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stability []ScenarioStability
+	var durations [][]time.Duration
+
+	index := map[string]int{}
+	occurrences := map[string]int{}
+
+	repeated := false
+	for _, scenario := range r.Scenarios {
+		// scenarios of unsupported features are recorded once, without running them
+		if scenario.Repetition == 0 {
+			continue
+		}
+
+		if scenario.Repetition > 1 {
+			repeated = true
+		}
+
+		run := fmt.Sprintf("%v\x00%v\x00%v", scenario.Repetition, scenario.Feature, scenario.Name)
+		key := fmt.Sprintf("%v\x00%v\x00%v", scenario.Feature, scenario.Name, occurrences[run])
+		occurrences[run]++
+
+		i, ok := index[key]
+		if !ok {
+			i = len(stability)
+			index[key] = i
+			stability = append(stability, ScenarioStability{
+				Feature:  scenario.Feature,
+				Name:     scenario.Name,
+				Statuses: map[Status]int{},
+			})
+			durations = append(durations, nil)
+		}
+
+		stability[i].Runs++
+		stability[i].Statuses[scenario.Status]++
+		durations[i] = append(durations[i], scenario.Duration)
+	}
+
+	if !repeated {
+		return nil
+	}
+
+	for i := range stability {
+		s := &stability[i]
+
+		s.PassRatio = float64(s.Statuses[Passed]) / float64(s.Runs)
+		s.Flaky = len(s.Statuses) > 1
+		s.MeanDurationSeconds, s.StdDevDurationSeconds = meanStdDev(durations[i])
+	}
+
+	return stability
+}
+
+// meanStdDev returns the mean and the standard deviation, in seconds, of durations
+func meanStdDev(durations []time.Duration) (float64, float64) {
+	var sum float64
+	for _, duration := range durations {
+		sum += duration.Seconds()
+	}
+
+	mean := sum / float64(len(durations))
+
+	var variance float64
+	for _, duration := range durations {
+		variance += math.Pow(duration.Seconds()-mean, 2)
+	}
+
+	return mean, math.Sqrt(variance / float64(len(durations)))
+}
+
+// RenderStability writes the results of the scenarios run several times as a table of text,
+// followed by the list of flaky scenarios
+func RenderStability(w io.Writer, stability []ScenarioStability) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(table, "FEATURE\tSCENARIO\tRUNS\tPASS RATIO\tMEAN\tSTDDEV\tFLAKY")
+
+	var flaky []string
+	for _, s := range stability {
+		fmt.Fprintf(table, "%v\t%v\t%v\t%.2f\t%.2fs\t%.2fs\t%v\n",
+			s.Feature, s.Name, s.Runs, s.PassRatio, s.MeanDurationSeconds, s.StdDevDurationSeconds, map[bool]string{true: "yes", false: "no"}[s.Flaky])
+
+		if s.Flaky {
+			flaky = append(flaky, fmt.Sprintf("  %v: %v (%v/%v passed)", s.Feature, s.Name, s.Statuses[Passed], s.Runs))
+		}
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	if len(flaky) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo flaky scenarios")
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nFlaky scenarios:\n%v\n", strings.Join(flaky, "\n"))
+	return err
+}
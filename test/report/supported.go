@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// SupportedFeatures declares the optional features implemented by an ingress controller.
+// Scenarios of optional profiles run only when one of their tags, without the leading @,
+// is declared. Core scenarios always run.
+type SupportedFeatures struct {
+	Features []string `json:"supportedFeatures"`
+}
+
+// LoadSupportedFeatures reads the supported features declared in a YAML file
+func LoadSupportedFeatures(path string) (*SupportedFeatures, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	supported := &SupportedFeatures{}
+	if err := yaml.UnmarshalStrict(data, supported); err != nil {
+		return nil, fmt.Errorf("reading supported features %v: %w", path, err)
+	}
+
+	return supported, nil
+}
+
+// Tags returns the godog tag expression that selects the core scenarios and the
+// scenarios of the supported features
+func (s *SupportedFeatures) Tags() string {
+	tags := []string{Core.Tag()}
+	for _, feature := range s.Features {
+		tags = append(tags, "@"+strings.TrimPrefix(feature, "@"))
+	}
+
+	return strings.Join(tags, ",")
+}
+
+// RecordUnsupported records the scenarios selected by the tag expression
+// that are not run because their features are not supported
+func (s *SupportedFeatures) RecordUnsupported(scenarios []*ScenarioDefinition, filter string) {
+	for _, definition := range scenarios {
+		if !matchesTags(filter, definition.Tags) || matchesTags(s.Tags(), definition.Tags) {
+			continue
+		}
+
+		scenario := &Scenario{
+			Feature:   definition.Feature,
+			Name:      definition.Name,
+			Tags:      definition.Tags,
+			Profile:   definition.Profile,
+			Status:    Unsupported,
+			Reason:    "the feature is not declared as supported by the ingress controller",
+			StartedAt: time.Now(),
+		}
+
+		for _, step := range definition.Steps {
+			scenario.Steps = append(scenario.Steps, &Step{Text: step, Status: Skipped})
+		}
+
+		Results.add(scenario)
+	}
+}
+
+// matchesTags returns true if the tags match a godog tag expression, where
+// groups separated by && must all match, and a group matches if any of its
+// comma separated tags is present, or absent when the tag starts with ~.
+func matchesTags(filter string, tags []string) bool {
+	if filter == "" {
+		return true
+	}
+
+	has := func(tag string) bool {
+		for _, t := range tags {
+			if strings.TrimPrefix(t, "@") == tag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, group := range strings.Split(filter, "&&") {
+		var matches bool
+		for _, tag := range strings.Split(group, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "@")
+			if strings.HasPrefix(tag, "~") {
+				matches = matches || !has(strings.TrimPrefix(tag[1:], "@"))
+			} else {
+				matches = matches || has(tag)
+			}
+		}
+
+		if !matches {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// SlowestStepsCount number of the slowest steps included in the JSON report
+var SlowestStepsCount = 10
+
+// Convergence contains the wait of a step for a route to converge
+type Convergence struct {
+	// Target method and URL of the requests
+	Target    string
+	Attempts  int
+	Duration  time.Duration
+	Converged bool
+}
+
+// ObserveConvergence records a wait for a route to converge in the step running in the scenario
+func ObserveConvergence(sc *godog.Scenario, convergence Convergence) {
+	running.Lock()
+	defer running.Unlock()
+
+	scenario, ok := running.scenarios[sc]
+	if !ok || scenario.current == nil {
+		return
+	}
+
+	scenario.current.Convergences = append(scenario.current.Convergences, convergence)
+}
+
+// SlowStep is one of the slowest steps of the run
+type SlowStep struct {
+	// Feature path of the feature file that contains the scenario of the step
+	Feature         string  `json:"feature"`
+	Scenario        string  `json:"scenario"`
+	Step            string  `json:"step"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	// ConvergenceSeconds time spent by the step waiting for routes to converge
+	ConvergenceSeconds  float64 `json:"convergenceSeconds"`
+	ConvergenceAttempts int     `json:"convergenceAttempts"`
+}
+
+// SlowestSteps returns the n slowest steps of the run, the slowest first
+func (r *Report) SlowestSteps(n int) []SlowStep {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var steps []SlowStep
+	for _, scenario := range r.Scenarios {
+		for _, step := range scenario.Steps {
+			if step.Duration == 0 {
+				continue
+			}
+
+			slow := SlowStep{
+				Feature:         scenario.Feature,
+				Scenario:        scenario.Name,
+				Step:            step.Text,
+				DurationSeconds: step.Duration.Seconds(),
+			}
+
+			for _, convergence := range step.Convergences {
+				slow.ConvergenceSeconds += convergence.Duration.Seconds()
+				slow.ConvergenceAttempts += convergence.Attempts
+			}
+
+			steps = append(steps, slow)
+		}
+	}
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].DurationSeconds > steps[j].DurationSeconds
+	})
+
+	if len(steps) > n {
+		steps = steps[:n]
+	}
+
+	return steps
+}
+
+// RenderSlowestSteps writes the slowest steps as a table of text
+func RenderSlowestSteps(w io.Writer, steps []SlowStep) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(table, "DURATION\tCONVERGENCE\tATTEMPTS\tFEATURE\tSCENARIO\tSTEP")
+	for _, step := range steps {
+		fmt.Fprintf(table, "%.2fs\t%.2fs\t%v\t%v\t%v\t%v\n",
+			step.DurationSeconds, step.ConvergenceSeconds, step.ConvergenceAttempts, step.Feature, step.Scenario, step.Step)
+	}
+
+	return table.Flush()
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/diff"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+// Assert is an expectation on the state of a scenario, like its captured round trip. It returns
+// an error describing the difference with the expected state when the expectation is not met.
+type Assert interface {
+	Assert(s *Scenario) error
+}
+
+// AssertFunc is a function used as an Assert
+type AssertFunc func(s *Scenario) error
+
+// Assert calls the function
+func (f AssertFunc) Assert(s *Scenario) error {
+	return f(s)
+}
+
+// ExpectationsError contains the expectations of a step that were not met
+type ExpectationsError struct {
+	Errors []error
+}
+
+func (e *ExpectationsError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%v expectations were not met:", len(e.Errors))
+	for _, err := range e.Errors {
+		out.WriteString("\n- " + strings.ReplaceAll(err.Error(), "\n", "\n  "))
+	}
+
+	return out.String()
+}
+
+// Unwrap returns the first expectation not met, so the errors returned by AssertAll can be inspected
+func (e *ExpectationsError) Unwrap() error {
+	return e.Errors[0]
+}
+
+// AssertAll checks all the expectations, instead of stopping at the first one not met,
+// and returns an ExpectationsError with the ones that were not met
+func (s *Scenario) AssertAll(asserts ...Assert) error {
+	var errs []error
+	for _, assert := range asserts {
+		if err := assert.Assert(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ExpectationsError{Errors: errs}
+}
+
+// Warn checks the expectations, like best practices, recording the ones not met as warnings
+// of the running step in the report, without failing the scenario
+func (s *Scenario) Warn(asserts ...Assert) {
+	for _, assert := range asserts {
+		if err := assert.Assert(s); err != nil {
+			s.Log(LogScenario, "Expectation not met", "warning", err)
+			report.Warn(s.scenario, err.Error())
+		}
+	}
+}
+
+// ResponseHeaderPresent expects the captured response to have the header
+func ResponseHeaderPresent(key string) Assert {
+	return AssertFunc(func(s *Scenario) error {
+		if _, ok := s.CapturedResponse.Headers[nethttp.CanonicalHeaderKey(key)]; !ok {
+			return fmt.Errorf("expected the response to have the %v header", key)
+		}
+
+		return nil
+	})
+}
+
+// versionRegexp matches software versions, like nginx/1.19.0
+var versionRegexp = regexp.MustCompile(`\d+\.\d+`)
+
+// ServerHeaderWithoutVersion expects the Server header of the captured response, if any,
+// not to reveal the version of the server
+func ServerHeaderWithoutVersion() Assert {
+	return AssertFunc(func(s *Scenario) error {
+		for _, value := range s.CapturedResponse.Headers["Server"] {
+			if versionRegexp.MatchString(value) {
+				return fmt.Errorf("expected the Server header of the response not to reveal a version but it was %q", value)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ResponseHeaders expects the captured response to have the headers, with the same values in the same order.
+// The differences are shown as a diff of the expected headers.
+func ResponseHeaders(headers nethttp.Header) Assert {
+	return AssertFunc(func(s *Scenario) error {
+		return diffHeaders("response", headers, s.CapturedResponse.Headers)
+	})
+}
+
+// RequestHeaders expects the captured request to have the headers, with the same values in the same order.
+// The differences are shown as a diff of the expected headers.
+func RequestHeaders(headers nethttp.Header) Assert {
+	return AssertFunc(func(s *Scenario) error {
+		return diffHeaders("request", headers, s.CapturedRequest.Headers)
+	})
+}
+
+// ResponseBody expects the body of the captured response, after it is decoded, to be the body.
+// The differences are shown as a diff of the lines of the bodies.
+func ResponseBody(body string) Assert {
+	return AssertFunc(func(s *Scenario) error {
+		return diffText("the response body", body, string(s.CapturedResponse.Body))
+	})
+}
+
+// diffHeaders returns an error with the differences between the expected headers and the same headers
+// of the actual ones, ignoring the other headers
+func diffHeaders(kind string, expected, actual nethttp.Header) error {
+	subset := nethttp.Header{}
+	for key := range expected {
+		if values, ok := actual[nethttp.CanonicalHeaderKey(key)]; ok {
+			subset[key] = values
+		}
+	}
+
+	return diffText(fmt.Sprintf("the %v headers", kind), formatHeaders(expected), formatHeaders(subset))
+}
+
+// formatHeaders returns the headers as text, one line per value, sorted by key
+func formatHeaders(headers nethttp.Header) string {
+	var keys []string
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var out strings.Builder
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			fmt.Fprintf(&out, "%v: %v\n", nethttp.CanonicalHeaderKey(key), value)
+		}
+	}
+
+	return out.String()
+}
+
+// diffText returns an error with the differences between the expected and the actual texts, nil when they are equal
+func diffText(what, expected, actual string) error {
+	if expected == actual {
+		return nil
+	}
+
+	// texts of a single line are easier to compare quoted
+	if !strings.Contains(expected+actual, "\n") {
+		return fmt.Errorf("expected %v to be %q but it was %q", what, expected, actual)
+	}
+
+	return fmt.Errorf("expected %v to be equal (- expected, + actual):\n%v", what, strings.TrimSuffix(diff.Lines(expected, actual), "\n"))
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+// DiagnosticsTimeout maximum time to collect the state of the cluster after a failure
+var DiagnosticsTimeout = 30 * time.Second
+
+// DumpDiagnostics logs the status of the Ingress of the scenario, the events of its namespace and
+// the logs written by the ingress controller pods since the scenario started, and attaches them
+// to the report of the scenario. Errors collecting them are logged and do not fail the scenario.
+func (s *Scenario) DumpDiagnostics() {
+	if kubernetes.KubeClient == nil || s.Namespace == "" {
+		return
+	}
+
+	// the context of the scenario can be done already
+	ctx, cancel := context.WithTimeout(context.Background(), DiagnosticsTimeout)
+	defer cancel()
+
+	if s.IngressName != "" {
+		status, err := kubernetes.IngressStatus(ctx, kubernetes.KubeClient, s.Namespace, s.IngressName)
+		s.dumpDiagnostic(fmt.Sprintf("Ingress %v/%v", s.Namespace, s.IngressName), status, err)
+	}
+
+	events, err := kubernetes.NamespaceEvents(ctx, kubernetes.KubeClient, s.Namespace)
+	s.dumpDiagnostic(fmt.Sprintf("Events of namespace %v", s.Namespace), events, err)
+
+	logs, err := kubernetes.ControllerLogs(ctx, kubernetes.KubeClient, s.started)
+	if err != nil {
+		s.dumpDiagnostic("Ingress controller logs", "", err)
+		return
+	}
+
+	containers := make([]string, 0, len(logs))
+	for container := range logs {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+
+	for _, container := range containers {
+		s.dumpDiagnostic(fmt.Sprintf("Ingress controller logs %v", container), logs[container], nil)
+	}
+}
+
+// dumpDiagnostic logs a piece of the state of the cluster and attaches it to the report of the scenario
+func (s *Scenario) dumpDiagnostic(name, content string, err error) {
+	if err != nil {
+		klog.ErrorS(err, "Collecting diagnostics", "scenario", s.name(), "diagnostic", name)
+		return
+	}
+
+	if content == "" {
+		return
+	}
+
+	klog.Infof("%v:\n%v", name, content)
+	report.Attach(s.scenario, name, content)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/http"
+	"sigs.k8s.io/ingress-controller-conformance/test/metrics"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+)
+
+// HistorySize maximum number of capture attempts kept in the history of a scenario
+var HistorySize = 50
+
+// CaptureAttempt contains a round trip captured in a scenario, or the error returned trying to do it.
+type CaptureAttempt struct {
+	Time   time.Time
+	Method string
+	URL    string
+
+	RoundTrip *http.CapturedRoundTrip
+	Err       error
+}
+
+func (a *CaptureAttempt) String() string {
+	prefix := fmt.Sprintf("%v %v %v", a.Time.Format("15:04:05.000"), a.Method, a.URL)
+
+	if a.Err != nil {
+		return fmt.Sprintf("%v -> error: %v", prefix, a.Err)
+	}
+
+	request, response := a.RoundTrip.Request, a.RoundTrip.Response
+	if response.Certificate != nil {
+		return fmt.Sprintf("%v -> %v (service=%q pod=%q certificate=%v %v)",
+			prefix, response.StatusCode, request.Service, request.Pod, response.Certificate.SerialNumber, response.Timings)
+	}
+
+	return fmt.Sprintf("%v -> %v (service=%q pod=%q %v)",
+		prefix, response.StatusCode, request.Service, request.Pod, response.Timings)
+}
+
+// history is a ring buffer containing the last capture attempts of a scenario
+type history struct {
+	mu       sync.Mutex
+	attempts []*CaptureAttempt
+	next     int
+	full     bool
+}
+
+func (h *history) add(attempt *CaptureAttempt) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if HistorySize < 1 {
+		return
+	}
+
+	if len(h.attempts) != HistorySize {
+		h.attempts = make([]*CaptureAttempt, HistorySize)
+		h.next = 0
+		h.full = false
+	}
+
+	h.attempts[h.next] = attempt
+	h.next = (h.next + 1) % HistorySize
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the attempts in the history, oldest first
+func (h *history) list() []*CaptureAttempt {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]*CaptureAttempt{}, h.attempts[:h.next]...)
+	}
+
+	return append(append([]*CaptureAttempt{}, h.attempts[h.next:]...), h.attempts[:h.next]...)
+}
+
+// recordAttempt adds a capture attempt to the history of the scenario
+func (s *Scenario) recordAttempt(method, scheme, hostname, path string, roundTrip *http.CapturedRoundTrip, err error) {
+	if hostname == "" {
+		hostname = s.Addresses[""]
+	}
+
+	attempt := &CaptureAttempt{
+		Time:      time.Now(),
+		Method:    method,
+		URL:       fmt.Sprintf("%v://%v/%v", scheme, hostname, strings.TrimPrefix(path, "/")),
+		RoundTrip: roundTrip,
+		Err:       err,
+	}
+
+	s.history.add(attempt)
+
+	if err != nil {
+		metrics.ObserveAttempt(s.scenario, 0, err)
+		s.Log(LogCaptures, "Capture attempt failed", "method", method, "url", attempt.URL, "err", err)
+		return
+	}
+
+	metrics.ObserveAttempt(s.scenario, roundTrip.Response.Timings.Total, nil)
+
+	s.Log(LogCaptures, "Captured round trip", "method", method, "url", attempt.URL,
+		"request", *roundTrip.Request, "response", *roundTrip.Response)
+}
+
+// History returns the last capture attempts of the scenario, oldest first
+func (s *Scenario) History() []*CaptureAttempt {
+	return s.history.list()
+}
+
+// DumpHistory logs the last capture attempts of the scenario, useful
+// to understand if a route flapped between states before a failed assertion.
+// The history, the last captured round trip and the log entries of the scenario
+// are attached to the report of the scenario.
+func (s *Scenario) DumpHistory() {
+	if logs := s.Logs(); logs != "" {
+		report.Attach(s.scenario, "Scenario log", logs)
+	}
+
+	attempts := s.History()
+	if len(attempts) == 0 {
+		return
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "Last %v captured round trips (oldest first):\n", len(attempts))
+	for i, attempt := range attempts {
+		fmt.Fprintf(&out, "  #%v %v\n", i+1, attempt)
+	}
+
+	klog.Info(out.String())
+
+	report.Attach(s.scenario, "Captured round trips", out.String())
+
+	if s.CapturedRequest != nil {
+		request, _ := json.MarshalIndent(s.CapturedRequest, "", "  ")
+		report.Attach(s.scenario, "Captured request", string(request))
+	}
+
+	if s.CapturedResponse != nil {
+		response := s.CapturedResponse
+		report.Attach(s.scenario, "Captured response", fmt.Sprintf("%v %v\nRemote address: %v\nTLS hostname: %v\nTimings: %v\nHeaders: %v",
+			response.Proto, response.StatusCode, response.RemoteAddress, response.TLSHostname, response.Timings, response.Headers))
+	}
+}
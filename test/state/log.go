@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Log verbosity levels of the scenarios
+const (
+	// LogScenario logs the progress of the scenarios and the routes that do not converge
+	LogScenario klog.Level = 1
+	// LogCaptures logs every capture attempt with the full request and response
+	LogCaptures klog.Level = 2
+)
+
+// logBuffer keeps all the log entries of a scenario, whatever the verbosity
+// level, to attach them to the report when the scenario fails
+type logBuffer struct {
+	mu  sync.Mutex
+	out strings.Builder
+}
+
+func (b *logBuffer) add(level klog.Level, msg string, keysAndValues []interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fmt.Fprintf(&b.out, "%v v=%v %q", time.Now().Format("15:04:05.000"), level, msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		// the same format used by klog for structured logs
+		if s, ok := value.(string); ok {
+			fmt.Fprintf(&b.out, " %v=%q", keysAndValues[i], s)
+		} else {
+			fmt.Fprintf(&b.out, " %v=%+v", keysAndValues[i], value)
+		}
+	}
+
+	b.out.WriteString("\n")
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.out.String()
+}
+
+// Log writes a structured log entry of the scenario, with the message and key/value pairs,
+// when the verbosity level is enabled. The entry is also kept in the log buffer of the
+// scenario, attached to the report when the scenario fails.
+func (s *Scenario) Log(level klog.Level, msg string, keysAndValues ...interface{}) {
+	s.logs.add(level, msg, keysAndValues)
+
+	if v := klog.V(level); v.Enabled() {
+		v.InfoS(msg, append([]interface{}{"scenario", s.name()}, keysAndValues...)...)
+	}
+}
+
+// Logs returns the log entries of the scenario
+func (s *Scenario) Logs() string {
+	return s.logs.String()
+}
+
+// name returns the name of the scenario
+func (s *Scenario) name() string {
+	if s.scenario == nil {
+		return ""
+	}
+
+	return s.scenario.Name
+}
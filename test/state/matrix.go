@@ -0,0 +1,191 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cucumber/godog"
+)
+
+// requestMatrixColumns are the columns of a request matrix table. The host, path and status
+// columns are required; the other columns are optional and their empty cells are not checked.
+var (
+	requestMatrixColumns         = []string{"scheme", "host", "path", "status", "service", "request host"}
+	requiredRequestMatrixColumns = []string{"host", "path", "status"}
+)
+
+// RequestMatrixRow is a request of a request matrix, with the expectations on its round trip
+type RequestMatrixRow struct {
+	Scheme      string
+	Host        string
+	Path        string
+	Status      int
+	Service     string
+	RequestHost string
+}
+
+func (r RequestMatrixRow) String() string {
+	return fmt.Sprintf("%v://%v%v", r.Scheme, r.Host, r.Path)
+}
+
+// ParseRequestMatrix returns the rows of a table with a header row naming its columns, like
+//
+//	| host        | path | status | service     |
+//	| foo.bar.com | /foo | 200    | foo-bar-com |
+func ParseRequestMatrix(table *godog.Table) ([]RequestMatrixRow, error) {
+	if len(table.Rows) < 2 {
+		return nil, fmt.Errorf("expected a table with a header row and at least one request")
+	}
+
+	var columns []string
+	for _, cell := range table.Rows[0].Cells {
+		column := strings.ToLower(strings.TrimSpace(cell.Value))
+		if !containsString(requestMatrixColumns, column) {
+			return nil, fmt.Errorf("unexpected column %q in the request table", cell.Value)
+		}
+
+		columns = append(columns, column)
+	}
+
+	for _, column := range requiredRequestMatrixColumns {
+		if !containsString(columns, column) {
+			return nil, fmt.Errorf("expected a %q column in the request table", column)
+		}
+	}
+
+	var rows []RequestMatrixRow
+	for i, row := range table.Rows[1:] {
+		if len(row.Cells) != len(columns) {
+			return nil, fmt.Errorf("expected %v cells in row %v of the request table but it contained %v", len(columns), i+1, len(row.Cells))
+		}
+
+		r := RequestMatrixRow{Scheme: "http"}
+		for j, cell := range row.Cells {
+			value := strings.TrimSpace(cell.Value)
+			switch columns[j] {
+			case "scheme":
+				if value != "" {
+					r.Scheme = value
+				}
+			case "host":
+				r.Host = value
+			case "path":
+				r.Path = value
+			case "status":
+				status, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid status %q in row %v of the request table", value, i+1)
+				}
+				r.Status = status
+			case "service":
+				r.Service = value
+			case "request host":
+				r.RequestHost = value
+			}
+		}
+
+		rows = append(rows, r)
+	}
+
+	return rows, nil
+}
+
+// CaptureRequestMatrix sends the requests of the matrix in order, checking all the expectations
+// of every request instead of stopping at the first one not met, and returns an ExpectationsError
+// with the expectations not met prefixed by their request
+func (s *Scenario) CaptureRequestMatrix(method string, rows []RequestMatrixRow) error {
+	var errs []error
+	for _, row := range rows {
+		if err := s.CaptureRoundTrip(method, row.Scheme, row.Host, row.Path); err != nil {
+			errs = append(errs, fmt.Errorf("%v %v: %w", method, row, err))
+			continue
+		}
+
+		asserts := []Assert{
+			AssertFunc(func(s *Scenario) error {
+				return s.AssertStatusCode(row.Status)
+			}),
+		}
+
+		if row.Service != "" {
+			service := row.Service
+			asserts = append(asserts, AssertFunc(func(s *Scenario) error {
+				if s.CapturedRequest == nil {
+					return fmt.Errorf("expected the request to be served by %v but it was not served by a backend", service)
+				}
+				return s.AssertServedBy(service)
+			}))
+		}
+
+		if row.RequestHost != "" {
+			host := row.RequestHost
+			asserts = append(asserts, AssertFunc(func(s *Scenario) error {
+				if s.CapturedRequest == nil {
+					return fmt.Errorf("expected the request host to be %v but it was not served by a backend", host)
+				}
+				return s.AssertRequestHost(host)
+			}))
+		}
+
+		if err := s.AssertAll(asserts...); err != nil {
+			for _, e := range err.(*ExpectationsError).Errors {
+				errs = append(errs, fmt.Errorf("%v %v: %w", method, row, e))
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	s.Log(LogScenario, "Request matrix expectations not met", "requests", len(rows), "failures", len(errs))
+	return &ExpectationsError{Errors: errs}
+}
+
+// RegisterRequestMatrix adds the step sending the requests of a table and checking
+// their responses, available to the features creating a scenario state
+func RegisterRequestMatrix(ctx *godog.ScenarioContext) {
+	var sc *godog.Scenario
+
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		sc = scenario
+	})
+
+	ctx.Step(`^I send "([^"]*)" requests to the hosts and paths, the responses must match$`, func(method string, table *godog.Table) error {
+		return withRunningScenario(sc, func(s *Scenario) error {
+			rows, err := ParseRequestMatrix(table)
+			if err != nil {
+				return err
+			}
+
+			return s.CaptureRequestMatrix(method, rows)
+		})
+	})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
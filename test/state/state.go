@@ -17,16 +17,54 @@ limitations under the License.
 package state
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	nethttp "net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/cucumber/godog"
+
 	"sigs.k8s.io/ingress-controller-conformance/test/http"
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+	"sigs.k8s.io/ingress-controller-conformance/test/report"
+	"sigs.k8s.io/ingress-controller-conformance/test/tracing"
 )
 
-const (
-	retryCount   = 3
-	maxRetryTime = 30 * time.Second
+var (
+	// ConvergenceSuccesses number of consecutive equal responses required to consider a route converged
+	ConvergenceSuccesses = 3
+	// ConvergenceMaxWait maximum time to wait for a route to converge
+	ConvergenceMaxWait = 30 * time.Second
+	// ConvergenceRetryDelay time to wait before retrying a request after a response that differs from the previous one
+	ConvergenceRetryDelay = time.Second
+
+	// MaxErrorRate maximum fraction of the requests sent in the background that can fail
+	MaxErrorRate = 0.01
+
+	// RateLimitStatusCode status code of the responses to the requests rejected by a rate limit
+	RateLimitStatusCode = nethttp.StatusTooManyRequests
+
+	// CanaryWeightTolerance maximum difference, in percentage points, between the share of the
+	// requests served by a service of a weighted split and its weight
+	CanaryWeightTolerance = 10
+
+	// ScenarioTimeout maximum duration of a scenario. Zero means no limit
+	ScenarioTimeout time.Duration
+	// SuiteContext is the parent context of the scenarios, done when the suite
+	// timeout expires or the suite is interrupted
+	SuiteContext = context.Background()
 )
 
 // Scenario holds state for a test scenario
@@ -39,58 +77,588 @@ type Scenario struct {
 	CapturedRequest  *http.CapturedRequest
 	CapturedResponse *http.CapturedResponse
 
-	IPOrFQDN string
+	CapturedStream *http.CapturedStream
+
+	// CapturedRoundTrips contains the round trips of the last CaptureMultipleRoundTrips call
+	CapturedRoundTrips []*http.CapturedRoundTrip
+
+	// CapturedConnection contains the result of the last raw TCP or TLS exchange
+	CapturedConnection *http.CapturedConnection
+
+	// LoadResult contains the outcome of the requests sent in the background, once they are stopped
+	LoadResult *http.LoadResult
+
+	// Addresses maps hostnames to the address (IP or FQDN) of the ingress controller used
+	// to send their requests. The empty hostname sets the address used for any other hostname.
+	Addresses map[string]string
+
+	// CookieJar keeps cookies between requests when set
+	CookieJar nethttp.CookieJar
+
+	// ConnectionPool keeps the connections open between requests when set
+	ConnectionPool *http.ConnectionPool
+	// FreshConnections opens a new connection for each request, instead of reusing the connections of the suite
+	FreshConnections bool
+
+	// RequestHeaders contains headers added to all the requests of the scenario
+	RequestHeaders nethttp.Header
+
+	// RequestTrailers contains trailers sent after the body of all the requests of the scenario
+	RequestTrailers nethttp.Header
+	// RequestBody is the body of all the requests of the scenario, when set
+	RequestBody []byte
+
+	// SourceAddress local IP address or network interface used to send the requests of the scenario
+	SourceAddress string
+
+	// ProxyProtocolVersion PROXY protocol version sent in the requests of the scenario
+	ProxyProtocolVersion int
+
+	// IPFamily address family used to connect to the ingress controller in the requests of the scenario
+	IPFamily string
+
+	// ServerName name sent in the TLS handshake (SNI) of the requests of the scenario instead of their hostname
+	ServerName string
+
+	// NoRedirects returns the redirect responses of the requests of the scenario instead of following them
+	NoRedirects bool
+
+	// Convergence compares consecutive round trips while waiting for a route to converge
+	Convergence ResponseComparator
+
+	ConvergenceSuccesses  int
+	ConvergenceMaxWait    time.Duration
+	ConvergenceRetryDelay time.Duration
+
+	history history
+	logs    logBuffer
+
+	// variables contains the values captured by the steps, available as ${name} in the following steps
+	variables   map[string]string
+	variablesMu sync.Mutex
+
+	// load sends requests in the background between StartLoad and StopLoad
+	load *http.LoadGenerator
+
+	// scenario is the godog scenario, used to attach information to its report
+	scenario *godog.Scenario
+	// started is the time the scenario started
+	started time.Time
+
+	// ctx cancels the requests in flight when the scenario or the suite are done
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // New creates a new state to use in a test Scenario
-func New() *Scenario {
-	return &Scenario{}
+func New(scenario *godog.Scenario) *Scenario {
+	ctx, cancel := context.WithCancel(SuiteContext)
+	if ScenarioTimeout > 0 {
+		ctx, cancel = context.WithTimeout(SuiteContext, ScenarioTimeout)
+	}
+
+	ctx = tracing.StartScenario(ctx, scenario)
+
+	s := &Scenario{
+		scenario: scenario,
+		started:  time.Now(),
+		ctx:      ctx,
+		cancel:   cancel,
+
+		Convergence: DefaultConvergence,
+
+		ConvergenceSuccesses:  ConvergenceSuccesses,
+		ConvergenceMaxWait:    ConvergenceMaxWait,
+		ConvergenceRetryDelay: ConvergenceRetryDelay,
+	}
+
+	s.Log(LogScenario, "Starting scenario", "uri", scenario.Uri)
+
+	running.Lock()
+	running.scenarios[scenario] = s
+	running.Unlock()
+
+	return s
+}
+
+// Context returns the context of the scenario, done when the scenario
+// or the suite time out or the suite is interrupted
+func (s *Scenario) Context() context.Context {
+	return s.ctx
+}
+
+// Close cancels the requests of the scenario still in flight
+func (s *Scenario) Close() {
+	s.cancel()
+
+	running.Lock()
+	delete(running.scenarios, s.scenario)
+	running.Unlock()
+
+	if s.ConnectionPool != nil {
+		s.ConnectionPool.Close()
+	}
+
+	if s.SecretName != "" {
+		http.SharedPool.Release(s.certificates())
+	}
+}
+
+// certificates identifies the certificates of the TLS secret of the scenario, so the TLS connections of the
+// previous scenarios, which issued their own self-signed certificates for the same hostnames, are not reused
+func (s *Scenario) certificates() string {
+	return s.Namespace + "/" + s.SecretName
+}
+
+// contextError explains the errors of requests cancelled because the scenario or the suite are done
+func (s *Scenario) contextError(err error) error {
+	switch {
+	case err == nil || s.ctx.Err() == nil:
+		return err
+	case errors.Is(SuiteContext.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("the suite timeout expired: %w", err)
+	case SuiteContext.Err() != nil:
+		return fmt.Errorf("the suite was interrupted: %w", err)
+	case errors.Is(s.ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("the scenario timeout (%v) expired: %w", ScenarioTimeout, err)
+	}
+
+	return err
+}
+
+// UseStrictConvergence requires consecutive round trips to be served by the same pod to consider a route converged
+func (s *Scenario) UseStrictConvergence() {
+	s.Convergence = StrictConvergence
+}
+
+// UseRelaxedConvergence only requires consecutive round trips to return the same status code to consider a route converged
+func (s *Scenario) UseRelaxedConvergence() {
+	s.Convergence = RelaxedConvergence
+}
+
+// EnableCookieJar makes the requests of the scenario send the cookies set by previous responses
+func (s *Scenario) EnableCookieJar() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+
+	s.CookieJar = jar
+	return nil
+}
+
+// EnableKeepAlive makes the requests of the scenario reuse the connections of previous requests
+func (s *Scenario) EnableKeepAlive() {
+	s.ConnectionPool = http.NewConnectionPool()
+}
+
+// UseFreshConnections makes each request of the scenario open a new connection, to test the behavior of
+// the first request of a connection, like the certificate presented in the TLS handshake after a change
+func (s *Scenario) UseFreshConnections() {
+	s.FreshConnections = true
+}
+
+// SetAddress sends the requests to the hostname to the address (IP or FQDN) of the ingress controller
+func (s *Scenario) SetAddress(hostname, address string) {
+	if s.Addresses == nil {
+		s.Addresses = map[string]string{}
+	}
+
+	s.Addresses[hostname] = address
+}
+
+// SetDefaultAddress sends the requests to any hostname without address to the address (IP or FQDN) of the ingress controller
+func (s *Scenario) SetDefaultAddress(address string) {
+	s.SetAddress("", address)
+}
+
+// AddRequestHeader adds a header to all the requests of the scenario
+func (s *Scenario) AddRequestHeader(key, value string) {
+	if s.RequestHeaders == nil {
+		s.RequestHeaders = nethttp.Header{}
+	}
+
+	s.RequestHeaders.Add(key, value)
+}
+
+// AddRequestTrailer adds a trailer to all the requests of the scenario, which are sent with a chunked body
+func (s *Scenario) AddRequestTrailer(key, value string) {
+	if s.RequestTrailers == nil {
+		s.RequestTrailers = nethttp.Header{}
+	}
+
+	s.RequestTrailers.Add(key, value)
+}
+
+// SetRequestBodySize sets the body of all the requests of the scenario to a body of
+// the size in bytes, repeating 0123456789abcdef
+func (s *Scenario) SetRequestBodySize(size int) {
+	pattern := []byte("0123456789abcdef")
+	s.RequestBody = bytes.Repeat(pattern, size/len(pattern)+1)[:size]
+}
+
+// SetBasicAuth sets the Authorization header of all the requests of the scenario
+// to the credentials of a user, using the Basic authentication scheme
+func (s *Scenario) SetBasicAuth(username, password string) {
+	if s.RequestHeaders == nil {
+		s.RequestHeaders = nethttp.Header{}
+	}
+
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	s.RequestHeaders.Set("Authorization", "Basic "+credentials)
+}
+
+// AddRequestHeaderFromResponse adds a header to all the requests of the scenario with the value
+// of a header of the captured response, like the ETag of a resource in an If-None-Match header
+func (s *Scenario) AddRequestHeaderFromResponse(key, responseKey string) error {
+	if s.CapturedResponse == nil {
+		return fmt.Errorf("the %v header requires a previous response", key)
+	}
+
+	value := nethttp.Header(s.CapturedResponse.Headers).Get(responseKey)
+	if value == "" {
+		return fmt.Errorf("expected the response to contain the %v header but it only contained %v", responseKey, s.CapturedResponse.Headers)
+	}
+
+	s.AddRequestHeader(key, value)
+	return nil
+}
+
+// roundTripOptions returns the HTTP round trip options configured in the scenario
+func (s *Scenario) roundTripOptions() []http.RoundTripOption {
+	var opts []http.RoundTripOption
+	if len(s.Addresses) != 0 {
+		opts = append(opts, http.WithAddresses(s.Addresses))
+	}
+
+	if s.CookieJar != nil {
+		opts = append(opts, http.WithCookieJar(s.CookieJar))
+	}
+
+	if s.ConnectionPool != nil {
+		opts = append(opts, http.WithConnectionPool(s.ConnectionPool))
+	} else if s.FreshConnections {
+		opts = append(opts, http.WithFreshConnection())
+	}
+
+	if s.SecretName != "" {
+		opts = append(opts, http.WithCertificates(s.certificates()))
+	}
+
+	if len(s.RequestHeaders) != 0 {
+		opts = append(opts, http.WithHeaders(s.RequestHeaders))
+	}
+
+	if len(s.RequestTrailers) != 0 {
+		opts = append(opts, http.WithTrailers(s.RequestTrailers))
+	}
+
+	if s.RequestBody != nil {
+		opts = append(opts, http.WithBody(s.RequestBody))
+	}
+
+	if s.SourceAddress != "" {
+		opts = append(opts, http.WithSourceAddress(s.SourceAddress))
+	}
+
+	if s.ProxyProtocolVersion != 0 {
+		opts = append(opts, http.WithProxyProtocol(s.ProxyProtocolVersion))
+	}
+
+	if s.ServerName != "" {
+		opts = append(opts, http.WithServerName(s.ServerName))
+	}
+
+	if s.IPFamily != "" {
+		opts = append(opts, http.WithIPFamily(s.IPFamily))
+	}
+
+	if s.NoRedirects {
+		opts = append(opts, http.WithoutRedirects())
+	}
+
+	return opts
 }
 
 // CaptureRoundTrip will perform an HTTP request and return the CapturedRequest and CapturedResponse tuple
 func (s *Scenario) CaptureRoundTrip(method, scheme, hostname, path string) error {
+	return s.CaptureRoundTripOnPort(method, scheme, hostname, path, 0)
+}
+
+// CaptureRoundTripOnPort will perform an HTTP request to a specific port of the ingress address
+// and return the CapturedRequest and CapturedResponse tuple. A zero port uses the default port of the scheme.
+func (s *Scenario) CaptureRoundTripOnPort(method, scheme, hostname, path string, port int) error {
+	opts := s.roundTripOptions()
+	if port != 0 {
+		opts = append(opts, http.WithPort(port))
+	}
+
 	var capturedRequest *http.CapturedRequest
 	var capturedResponse *http.CapturedResponse
 	var err error
 
-	err = awaitConvergence(retryCount, maxRetryTime, func(elapsed time.Duration) bool {
-		capturedRequest, capturedResponse, err = http.CaptureRoundTrip(method, scheme, hostname, path, s.IPOrFQDN)
+	ctx, span := tracing.Start(s.ctx, "awaitConvergence", "http.method", method, "http.scheme", scheme, "http.host", hostname, "http.target", path)
+
+	attempts := 0
+	start := time.Now()
+
+	err = awaitConvergence(ctx, s.ConvergenceSuccesses, s.ConvergenceMaxWait, s.ConvergenceRetryDelay, func(elapsed time.Duration) bool {
+		attempts++
+
+		capturedRequest, capturedResponse, err = http.CaptureRoundTrip(ctx, method, scheme, hostname, path, opts...)
 		if err != nil {
+			s.recordAttempt(method, scheme, hostname, path, nil, err)
 			return false
 		}
 
+		s.recordAttempt(method, scheme, hostname, path, &http.CapturedRoundTrip{Request: capturedRequest, Response: capturedResponse}, nil)
+
 		defer func() {
 			s.CapturedRequest = capturedRequest
 			s.CapturedResponse = capturedResponse
 		}()
 
-		return compareResponse(s.CapturedResponse, capturedResponse)
+		if s.CapturedResponse == nil {
+			return false
+		}
+
+		compare := s.Convergence
+		if compare == nil {
+			compare = DefaultConvergence
+		}
+
+		return compare(
+			&http.CapturedRoundTrip{Request: s.CapturedRequest, Response: s.CapturedResponse},
+			&http.CapturedRoundTrip{Request: capturedRequest, Response: capturedResponse},
+		)
+	})
+	span.End(err)
+
+	report.ObserveConvergence(s.scenario, report.Convergence{
+		Target:    fmt.Sprintf("%v %v://%v%v", method, scheme, hostname, path),
+		Attempts:  attempts,
+		Duration:  time.Since(start),
+		Converged: err == nil,
 	})
+
 	if err != nil {
-		return err
+		s.Log(LogScenario, "Route did not converge", "method", method, "scheme", scheme, "hostname", hostname, "path", path, "err", err)
+		return s.contextError(err)
+	}
+	return nil
+}
+
+// CaptureRoundTripUntil performs HTTP requests, like CaptureRoundTrip, until the assertion passes
+// for the captured round trip or the convergence window expires. It waits for the changes of an
+// Ingress to be propagated to the ingress controller.
+func (s *Scenario) CaptureRoundTripUntil(method, scheme, hostname, path string, assert func() error) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.ConvergenceMaxWait)
+	defer cancel()
+
+	for {
+		err := s.CaptureRoundTrip(method, scheme, hostname, path)
+		if err == nil {
+			err = assert()
+			if err == nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return s.contextError(fmt.Errorf("the route did not change in %v: %w", s.ConvergenceMaxWait, err))
+		case <-time.After(s.ConvergenceRetryDelay):
+		}
+	}
+}
+
+// CaptureMultipleRoundTrips will perform n HTTP requests, running at most concurrency of them
+// at the same time, and keep the CapturedRequest and CapturedResponse of each one of them.
+// Requests are not retried, so an error is returned if any of the round trips failed.
+func (s *Scenario) CaptureMultipleRoundTrips(method, scheme, hostname, path string, n, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	roundTrips := make([]*http.CapturedRoundTrip, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			capturedRequest, capturedResponse, err := http.CaptureRoundTrip(s.ctx, method, scheme, hostname, path, s.roundTripOptions()...)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			roundTrips[i] = &http.CapturedRoundTrip{
+				Request:  capturedRequest,
+				Response: capturedResponse,
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	s.CapturedRoundTrips = nil
+	for i, roundTrip := range roundTrips {
+		s.recordAttempt(method, scheme, hostname, path, roundTrip, errs[i])
+
+		if roundTrip != nil {
+			s.CapturedRoundTrips = append(s.CapturedRoundTrips, roundTrip)
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return s.contextError(fmt.Errorf("%v of %v requests failed: %w", n-len(s.CapturedRoundTrips), n, err))
+		}
+	}
+
+	return nil
+}
+
+// CaptureStream will perform an HTTP request to a Server-Sent Events endpoint and keep
+// the CapturedStream with the first events received
+func (s *Scenario) CaptureStream(method, scheme, hostname, path string, events int) error {
+	capturedStream, err := http.CaptureStream(s.ctx, method, scheme, hostname, path, events, s.roundTripOptions()...)
+	if err != nil {
+		s.recordAttempt(method, scheme, hostname, path, nil, err)
+		return s.contextError(err)
+	}
+
+	s.recordAttempt(method, scheme, hostname, path, &http.CapturedRoundTrip{Request: &http.CapturedRequest{}, Response: capturedStream.Response}, nil)
+
+	s.CapturedStream = capturedStream
+	s.CapturedResponse = capturedStream.Response
+
+	return nil
+}
+
+// CaptureRawRoundTrip writes lines, terminated by CRLF, over a TCP or TLS connection to a port
+// of the hostname and keeps the response lines. Network must be "tcp" or "tls".
+func (s *Scenario) CaptureRawRoundTrip(network, hostname string, port int, lines []string) error {
+	payload := strings.Join(lines, "\r\n") + "\r\n"
+
+	capturedConnection, err := http.CaptureRawRoundTrip(s.ctx, network, hostname, port, []byte(payload), 0, s.roundTripOptions()...)
+	if err != nil {
+		s.Log(LogCaptures, "Raw round trip failed", "network", network, "hostname", hostname, "port", port, "payload", payload, "err", err)
+		return s.contextError(err)
+	}
+
+	s.Log(LogCaptures, "Captured raw round trip", "network", network, "hostname", hostname, "port", port, "payload", payload,
+		"remoteAddress", capturedConnection.RemoteAddress, "lines", capturedConnection.Lines)
+
+	s.CapturedConnection = capturedConnection
+	return nil
+}
+
+// CaptureRawRequest writes an HTTP request as is over a TCP or TLS connection to a port of the hostname
+// and keeps the status line of the response. Network must be "tcp" or "tls".
+func (s *Scenario) CaptureRawRequest(network, hostname string, port int, request *http.RawRequest) error {
+	capturedConnection, err := http.SendRawRequest(s.ctx, network, hostname, port, request, s.roundTripOptions()...)
+	if err != nil {
+		s.Log(LogCaptures, "Raw request failed", "network", network, "hostname", hostname, "port", port,
+			"method", request.Method, "target", request.Target, "headers", len(request.Headers), "err", err)
+		return s.contextError(err)
+	}
+
+	s.Log(LogCaptures, "Captured raw request", "network", network, "hostname", hostname, "port", port,
+		"method", request.Method, "target", request.Target, "headers", len(request.Headers), "lines", capturedConnection.Lines)
+
+	s.CapturedConnection = capturedConnection
+	return nil
+}
+
+// StartLoad starts sending requests in the background, until StopLoad is called or the scenario is done
+func (s *Scenario) StartLoad(method, scheme, hostname, path string) error {
+	if s.load != nil {
+		return fmt.Errorf("requests are already being sent in the background")
 	}
+
+	s.Log(LogScenario, "Starting requests in the background", "method", method, "scheme", scheme, "hostname", hostname, "path", path)
+
+	s.load = http.StartLoad(s.ctx, method, scheme, hostname, path, s.roundTripOptions()...)
 	return nil
 }
 
-// compareResponse compares two captured responses and returns true if they are equal.
-// Currently, only status code is compared.
-func compareResponse(prev *http.CapturedResponse, curr *http.CapturedResponse) bool {
-	if prev == nil || curr == nil {
+// StopLoad stops the requests sent in the background and keeps their outcome in LoadResult
+func (s *Scenario) StopLoad() error {
+	if s.load == nil {
+		return fmt.Errorf("no requests are being sent in the background")
+	}
+
+	s.LoadResult = s.load.Stop()
+	s.load = nil
+
+	s.Log(LogScenario, "Stopped requests in the background", "result", s.LoadResult.String())
+
+	return s.contextError(s.ctx.Err())
+}
+
+// ResponseComparator returns true if two consecutive round trips are equal,
+// meaning the route did not change between them.
+type ResponseComparator func(prev, curr *http.CapturedRoundTrip) bool
+
+// ConvergenceHeaders contains the response headers compared by DefaultConvergence and StrictConvergence
+var ConvergenceHeaders = []string{"Content-Type"}
+
+// RelaxedConvergence compares only the status code of the responses.
+func RelaxedConvergence(prev, curr *http.CapturedRoundTrip) bool {
+	return prev.Response.StatusCode == curr.Response.StatusCode
+}
+
+// DefaultConvergence compares the status code, the service that served the request
+// and the ConvergenceHeaders of the responses, so routes flapping between backends
+// are not considered converged.
+func DefaultConvergence(prev, curr *http.CapturedRoundTrip) bool {
+	if !RelaxedConvergence(prev, curr) {
+		return false
+	}
+
+	if prev.Request.Service != curr.Request.Service {
 		return false
 	}
-	return prev.StatusCode == curr.StatusCode
+
+	for _, header := range ConvergenceHeaders {
+		if nethttp.Header(prev.Response.Headers).Get(header) != nethttp.Header(curr.Response.Headers).Get(header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// StrictConvergence compares the same fields than DefaultConvergence and also requires
+// the requests to be served by the same pod. Only useful for backends with a single replica
+// or session affinity.
+func StrictConvergence(prev, curr *http.CapturedRoundTrip) bool {
+	return DefaultConvergence(prev, curr) && prev.Request.Pod == curr.Request.Pod
 }
 
 // awaitConvergence runs the given function until it returns 'true' `threshold` times in a row.
-// Each failed attempt has a 1s delay; successful attempts have no delay.
-func awaitConvergence(threshold int, maxTimeToConsistency time.Duration, fn func(elapsed time.Duration) bool) error {
+// Each failed attempt is followed by the given delay; successful attempts have no delay.
+// Waiting stops when the context is done.
+func awaitConvergence(ctx context.Context, threshold int, maxTimeToConsistency, delay time.Duration, fn func(elapsed time.Duration) bool) error {
 	successes := 0
 	attempts := 0
 	start := time.Now()
 	to := time.After(maxTimeToConsistency)
-	delay := time.Second
 	for {
 		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for convergence after %d attempts: %w", attempts, ctx.Err())
 		case <-to:
 			return fmt.Errorf("timed out waiting for convergence")
 		default:
@@ -109,6 +677,8 @@ func awaitConvergence(threshold int, maxTimeToConsistency time.Duration, fn func
 
 		successes = 0
 		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for convergence after %d attempts: %w", attempts, ctx.Err())
 		// Capture the overall timeout
 		case <-to:
 			return fmt.Errorf("timeout while waiting after %d attempts, %d/%d sucessess", attempts, successes, threshold)
@@ -127,64 +697,177 @@ func (s *Scenario) AssertStatusCode(statusCode int) error {
 	return nil
 }
 
-// AssertServedBy returns an error if the captured request was not served by the expected service
-func (s *Scenario) AssertServedBy(service string) error {
-	if s.CapturedRequest.Service != service {
-		return fmt.Errorf("expected the request to be served by %v but it was served by %v", service, s.CapturedRequest.Service)
+// AssertStatusCodeOneOf returns an error if the captured response status code does not match any of the expected values
+func (s *Scenario) AssertStatusCodeOneOf(statusCodes ...int) error {
+	for _, statusCode := range statusCodes {
+		if s.CapturedResponse.StatusCode == statusCode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected one of the status codes %v but %v was returned", statusCodes, s.CapturedResponse.StatusCode)
+}
+
+// AssertErrorRateUnder returns an error if the fraction of the requests sent in the background that failed
+// or returned a 5xx status code is greater than the maximum
+func (s *Scenario) AssertErrorRateUnder(maxErrorRate float64) error {
+	if s.LoadResult == nil || s.LoadResult.Requests == 0 {
+		return fmt.Errorf("no requests were sent in the background")
+	}
+
+	if s.LoadResult.ErrorRate() > maxErrorRate {
+		return fmt.Errorf("expected an error rate of at most %.2f%% but got %v", 100*maxErrorRate, s.LoadResult)
 	}
 
 	return nil
 }
 
-// AssertRequestHost returns an error if the captured request host does not match the expected value
-func (s *Scenario) AssertRequestHost(host string) error {
-	if s.CapturedRequest.Host != host {
-		return fmt.Errorf("expected the request host to be %v but was %v", host, s.CapturedRequest.Host)
+// AssertResponseTimeUnder returns an error if the captured round trip took longer than the expected duration
+func (s *Scenario) AssertResponseTimeUnder(duration time.Duration) error {
+	if s.CapturedResponse.Timings.Total >= duration {
+		return fmt.Errorf("expected the response to be received in less than %v but it took %v (%v)",
+			duration, s.CapturedResponse.Timings.Total, s.CapturedResponse.Timings)
 	}
 
 	return nil
 }
 
-// AssertTLSHostname returns an error if the captured TLS response hostname does not match the expected value
-func (s *Scenario) AssertTLSHostname(hostname string) error {
-	if s.CapturedResponse.TLSHostname != hostname {
-		return fmt.Errorf("expected the response TLS hostname to be %v but was %v", hostname, s.CapturedResponse.TLSHostname)
+// AssertResponseTimeAtLeast returns an error if the captured round trip took less than the expected duration
+func (s *Scenario) AssertResponseTimeAtLeast(duration time.Duration) error {
+	if s.CapturedResponse.Timings.Total < duration {
+		return fmt.Errorf("expected the response to be received in at least %v but it took %v (%v)",
+			duration, s.CapturedResponse.Timings.Total, s.CapturedResponse.Timings)
 	}
 
 	return nil
 }
 
-// AssertResponseProto returns an error if the captured response proto does not match the expected value
-func (s *Scenario) AssertResponseProto(proto string) error {
-	if s.CapturedResponse.Proto != proto {
-		return fmt.Errorf("expected the response protocol to be %v but it was %v", proto, s.CapturedResponse.Proto)
+// AssertConnectionReused returns an error if the captured round trip did not reuse the connection of a previous round trip
+func (s *Scenario) AssertConnectionReused() error {
+	if !s.CapturedResponse.ConnectionReused {
+		return fmt.Errorf("expected the request to reuse a connection to %v but a new connection was opened", s.CapturedResponse.RemoteAddress)
 	}
 
 	return nil
 }
 
-// AssertRequestProto returns an error if the captured request proto does not match the expected value
-func (s *Scenario) AssertRequestProto(proto string) error {
-	if s.CapturedRequest.Proto != proto {
-		return fmt.Errorf("expected the request protocol to be %v but it was %v", proto, s.CapturedRequest.Proto)
+// AssertResponseNotEncoded returns an error if the captured response body has a content encoding
+func (s *Scenario) AssertResponseNotEncoded() error {
+	if encoding := s.CapturedResponse.ContentEncoding; encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return fmt.Errorf("expected the response body not to be encoded but it was encoded with %v", encoding)
 	}
 
 	return nil
 }
 
-// AssertMethod returns an error if the captured request method does not match the expected value
-func (s *Scenario) AssertMethod(method string) error {
-	if s.CapturedRequest.Method != method {
-		return fmt.Errorf("expected the request method to be %v but it was %v", method, s.CapturedRequest.Method)
+// AssertDecodedBodySize returns an error if the size of the captured response body, after it is decoded, is not the expected size
+func (s *Scenario) AssertDecodedBodySize(size int64) error {
+	if s.CapturedResponse.DecodedBodySize != size {
+		return fmt.Errorf("expected the decoded response body to be %v bytes but it was %v bytes (%v bytes received with content encoding %q)",
+			size, s.CapturedResponse.DecodedBodySize, s.CapturedResponse.BodySize, s.CapturedResponse.ContentEncoding)
 	}
 
 	return nil
 }
 
-// AssertRequestPath returns an error if the captured request path does not match the expected value
-func (s *Scenario) AssertRequestPath(path string) error {
-	if !strings.HasPrefix(path, "/") {
-		path = fmt.Sprintf("/%s", path)
+// AssertContentLengthMatchesBody returns an error if the Content-Length of the captured response, when
+// present, is not the size of the response body received, before it is decoded
+func (s *Scenario) AssertContentLengthMatchesBody() error {
+	if s.CapturedResponse.ContentLength >= 0 && s.CapturedResponse.ContentLength != s.CapturedResponse.BodySize {
+		return fmt.Errorf("expected the Content-Length of the response to be the size of its body (%v bytes) but it was %v",
+			s.CapturedResponse.BodySize, s.CapturedResponse.ContentLength)
+	}
+
+	return nil
+}
+
+// AssertResponseBody returns an error if the captured response body, after it is decoded, is not the expected body
+func (s *Scenario) AssertResponseBody(body string) error {
+	return ResponseBody(body).Assert(s)
+}
+
+// AssertContentTypeDeclared returns an error if the captured response has a body without a Content-Type header
+func (s *Scenario) AssertContentTypeDeclared() error {
+	if len(s.CapturedResponse.Body) == 0 {
+		return nil
+	}
+
+	if nethttp.Header(s.CapturedResponse.Headers).Get("Content-Type") == "" {
+		return fmt.Errorf("expected the response to declare the Content-Type of its body (%v bytes) but it only contained %v",
+			len(s.CapturedResponse.Body), s.CapturedResponse.Headers)
+	}
+
+	return nil
+}
+
+// AssertServedBy returns an error if the captured request was not served by the expected service
+func (s *Scenario) AssertServedBy(service string) error {
+	if s.CapturedRequest.Service != service {
+		return fmt.Errorf("expected the request to be served by %v but it was served by %v", service, s.CapturedRequest.Service)
+	}
+
+	return nil
+}
+
+// AssertNotServedBy returns an error if the captured request was served by the service,
+// e.g. when the ingress controller must answer the request without sending it to the backend
+func (s *Scenario) AssertNotServedBy(service string) error {
+	if s.CapturedRequest != nil && s.CapturedRequest.Service == service {
+		return fmt.Errorf("expected the request not to be served by %v but it was", service)
+	}
+
+	return nil
+}
+
+// AssertRequestHost returns an error if the captured request host does not match the expected value
+func (s *Scenario) AssertRequestHost(host string) error {
+	if s.CapturedRequest.Host != host {
+		return fmt.Errorf("expected the request host to be %v but was %v", host, s.CapturedRequest.Host)
+	}
+
+	return nil
+}
+
+// AssertTLSHostname returns an error if the captured TLS response hostname does not match the expected value
+func (s *Scenario) AssertTLSHostname(hostname string) error {
+	if s.CapturedResponse.TLSHostname != hostname {
+		return fmt.Errorf("expected the response TLS hostname to be %v but was %v", hostname, s.CapturedResponse.TLSHostname)
+	}
+
+	return nil
+}
+
+// AssertResponseProto returns an error if the captured response proto does not match the expected value
+func (s *Scenario) AssertResponseProto(proto string) error {
+	if s.CapturedResponse.Proto != proto {
+		return fmt.Errorf("expected the response protocol to be %v but it was %v", proto, s.CapturedResponse.Proto)
+	}
+
+	return nil
+}
+
+// AssertRequestProto returns an error if the captured request proto does not match the expected value
+func (s *Scenario) AssertRequestProto(proto string) error {
+	if s.CapturedRequest.Proto != proto {
+		return fmt.Errorf("expected the request protocol to be %v but it was %v", proto, s.CapturedRequest.Proto)
+	}
+
+	return nil
+}
+
+// AssertMethod returns an error if the captured request method does not match the expected value
+func (s *Scenario) AssertMethod(method string) error {
+	if s.CapturedRequest.Method != method {
+		return fmt.Errorf("expected the request method to be %v but it was %v", method, s.CapturedRequest.Method)
+	}
+
+	return nil
+}
+
+// AssertRequestPath returns an error if the captured request path does not match the expected value
+func (s *Scenario) AssertRequestPath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		path = fmt.Sprintf("/%s", path)
 	}
 
 	if s.CapturedRequest.Path != path {
@@ -194,6 +877,81 @@ func (s *Scenario) AssertRequestPath(path string) error {
 	return nil
 }
 
+// AssertRedirectLocation returns an error if the Location header of the captured response does not redirect to
+// the expected URL. The port of the location is ignored, since it depends on the ports of the ingress controller.
+func (s *Scenario) AssertRedirectLocation(location string) error {
+	expected, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+
+	header := nethttp.Header(s.CapturedResponse.Headers).Get("Location")
+	if header == "" {
+		return fmt.Errorf("expected the response to redirect to %v but it had no Location header", location)
+	}
+
+	actual, err := url.Parse(header)
+	if err != nil {
+		return fmt.Errorf("invalid Location header %q: %w", header, err)
+	}
+
+	if actual.Scheme != expected.Scheme || actual.Hostname() != expected.Hostname() || actual.RequestURI() != expected.RequestURI() {
+		return fmt.Errorf("expected the response to redirect to %v but it redirected to %v", location, header)
+	}
+
+	return nil
+}
+
+// AssertRequestTLS returns an error if the captured request did not reach the backend over TLS
+func (s *Scenario) AssertRequestTLS() error {
+	if s.CapturedRequest.TLS == nil {
+		return fmt.Errorf("expected the request to reach the backend over TLS but it was sent in plain text (protocol %v)", s.CapturedRequest.Protocol)
+	}
+
+	return nil
+}
+
+// AssertRequestClientCertificate returns an error if the backend did not receive and verify a client certificate
+// with the expected common name. Verification requires the client CA certificates of the echoserver.
+func (s *Scenario) AssertRequestClientCertificate(commonName string, verified bool) error {
+	if err := s.AssertRequestTLS(); err != nil {
+		return err
+	}
+
+	certificate := s.CapturedRequest.TLS.ClientCertificate
+	if certificate == nil {
+		return fmt.Errorf("expected the backend to receive a client certificate for %v but none was presented", commonName)
+	}
+
+	if certificate.CommonName != commonName {
+		return fmt.Errorf("expected the backend to receive a client certificate for %v but it was for %v (%v)", commonName, certificate.CommonName, certificate.Subject)
+	}
+
+	if verified && !certificate.Verified {
+		return fmt.Errorf("expected the backend to verify the client certificate of %v but it could not (issued by %v)", commonName, certificate.Issuer)
+	}
+
+	return nil
+}
+
+// AssertRequestRawPath returns an error if the path received by the backend, before it is decoded, does not match the expected value
+func (s *Scenario) AssertRequestRawPath(path string) error {
+	if s.CapturedRequest.RawPath != path {
+		return fmt.Errorf("expected the raw request path to be %v but it was %v (%v)", path, s.CapturedRequest.RawPath, s.CapturedRequest.RequestLine)
+	}
+
+	return nil
+}
+
+// AssertRequestRawQuery returns an error if the query received by the backend, before it is decoded, does not match the expected value
+func (s *Scenario) AssertRequestRawQuery(query string) error {
+	if s.CapturedRequest.RawQuery != query {
+		return fmt.Errorf("expected the raw request query to be %v but it was %v (%v)", query, s.CapturedRequest.RawQuery, s.CapturedRequest.RequestLine)
+	}
+
+	return nil
+}
+
 // AssertResponseHeader returns an error if the captured response headers do not contain the expected headerKey,
 // or if the matching response header value does not match the expected headerValue.
 // If the headerValue string equals `*`, the header value check is ignored.
@@ -213,6 +971,51 @@ func (s *Scenario) AssertResponseHeader(headerKey string, headerValue string) er
 	return nil
 }
 
+// AssertResponseTrailer returns an error if the captured response trailers do not contain the expected trailerKey
+// with the expected trailerValue
+func (s *Scenario) AssertResponseTrailer(trailerKey string, trailerValue string) error {
+	trailerValues := s.CapturedResponse.Trailers[trailerKey]
+	for _, value := range trailerValues {
+		if value == trailerValue {
+			return nil
+		}
+	}
+
+	if trailerValues == nil {
+		return fmt.Errorf("expected response trailers to contain %v but it only contained %v (response headers %v)",
+			trailerKey, s.CapturedResponse.Trailers, s.CapturedResponse.Headers)
+	}
+
+	return fmt.Errorf("expected response trailers %v to contain a %v value but it contained %v", trailerKey, trailerValue, trailerValues)
+}
+
+// AssertRequestBodySize returns an error if the size of the body of the captured request is not the expected size
+func (s *Scenario) AssertRequestBodySize(size int64) error {
+	if s.CapturedRequest.BodySize != size {
+		return fmt.Errorf("expected the request body to have %v bytes but it had %v", size, s.CapturedRequest.BodySize)
+	}
+
+	return nil
+}
+
+// AssertRequestTrailer returns an error if the trailers of the captured request do not contain the expected
+// trailerKey with the expected trailerValue
+func (s *Scenario) AssertRequestTrailer(trailerKey string, trailerValue string) error {
+	trailerValues := s.CapturedRequest.Trailers[trailerKey]
+	for _, value := range trailerValues {
+		if value == trailerValue {
+			return nil
+		}
+	}
+
+	if trailerValues == nil {
+		return fmt.Errorf("expected request trailers to contain %v but it only contained %v (request headers %v)",
+			trailerKey, s.CapturedRequest.Trailers, s.CapturedRequest.Headers)
+	}
+
+	return fmt.Errorf("expected request trailers %v to contain a %v value but it contained %v", trailerKey, trailerValue, trailerValues)
+}
+
 // AssertRequestHeader returns an error if the captured request headers do not contain the expected headerKey,
 // or if the matching request header value does not match the expected headerValue.
 // If the headerValue string equals `*`, the header value check is ignored.
@@ -232,6 +1035,351 @@ func (s *Scenario) AssertRequestHeader(headerKey string, headerValue string) err
 	return nil
 }
 
+// AssertResponseHeaderMatches returns an error if none of the captured response headerKey values matches the regular expression
+func (s *Scenario) AssertResponseHeaderMatches(headerKey string, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid header value regular expression %v: %w", pattern, err)
+	}
+
+	return matchHeader("response", s.CapturedResponse.Headers, headerKey, fmt.Sprintf("matching %v", pattern), re.MatchString)
+}
+
+// AssertRequestHeaderMatches returns an error if none of the captured request headerKey values matches the regular expression
+func (s *Scenario) AssertRequestHeaderMatches(headerKey string, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid header value regular expression %v: %w", pattern, err)
+	}
+
+	return matchHeader("request", s.CapturedRequest.Headers, headerKey, fmt.Sprintf("matching %v", pattern), re.MatchString)
+}
+
+// AssertResponseHeaderPrefix returns an error if none of the captured response headerKey values starts with prefix
+func (s *Scenario) AssertResponseHeaderPrefix(headerKey string, prefix string) error {
+	return matchHeader("response", s.CapturedResponse.Headers, headerKey, fmt.Sprintf("starting with %v", prefix), func(value string) bool {
+		return strings.HasPrefix(value, prefix)
+	})
+}
+
+// AssertRequestHeaderPrefix returns an error if none of the captured request headerKey values starts with prefix
+func (s *Scenario) AssertRequestHeaderPrefix(headerKey string, prefix string) error {
+	return matchHeader("request", s.CapturedRequest.Headers, headerKey, fmt.Sprintf("starting with %v", prefix), func(value string) bool {
+		return strings.HasPrefix(value, prefix)
+	})
+}
+
+// AssertResponseHeaderSuffix returns an error if none of the captured response headerKey values ends with suffix
+func (s *Scenario) AssertResponseHeaderSuffix(headerKey string, suffix string) error {
+	return matchHeader("response", s.CapturedResponse.Headers, headerKey, fmt.Sprintf("ending with %v", suffix), func(value string) bool {
+		return strings.HasSuffix(value, suffix)
+	})
+}
+
+// AssertRequestHeaderSuffix returns an error if none of the captured request headerKey values ends with suffix
+func (s *Scenario) AssertRequestHeaderSuffix(headerKey string, suffix string) error {
+	return matchHeader("request", s.CapturedRequest.Headers, headerKey, fmt.Sprintf("ending with %v", suffix), func(value string) bool {
+		return strings.HasSuffix(value, suffix)
+	})
+}
+
+// AssertResponseHeaderCount returns an error if the captured response does not contain headerKey exactly count times
+func (s *Scenario) AssertResponseHeaderCount(headerKey string, count int) error {
+	if headerValues := nethttp.Header(s.CapturedResponse.Headers).Values(headerKey); len(headerValues) != count {
+		return fmt.Errorf("expected response header %v to appear %v times but it appeared %v times: %v", headerKey, count, len(headerValues), headerValues)
+	}
+
+	return nil
+}
+
+// AssertRequestHeaderCount returns an error if the captured request does not contain headerKey exactly count times
+func (s *Scenario) AssertRequestHeaderCount(headerKey string, count int) error {
+	if headerValues := nethttp.Header(s.CapturedRequest.Headers).Values(headerKey); len(headerValues) != count {
+		return fmt.Errorf("expected request header %v to appear %v times but it appeared %v times: %v", headerKey, count, len(headerValues), headerValues)
+	}
+
+	return nil
+}
+
+// matchHeader returns an error if none of the headerKey values in headers satisfies the match function
+func matchHeader(kind string, headers map[string][]string, headerKey, description string, match func(string) bool) error {
+	headerValues := nethttp.Header(headers).Values(headerKey)
+	if len(headerValues) == 0 {
+		return fmt.Errorf("expected %v headers to contain %v but it only contained %v", kind, headerKey, headers)
+	}
+
+	for _, value := range headerValues {
+		if match(value) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected %v headers %v to contain a value %v but it contained %v", kind, headerKey, description, headerValues)
+}
+
+// AssertBasicAuthChallenge returns an error if the captured response does not contain
+// a WWW-Authenticate header asking for the credentials of the realm with the Basic scheme
+func (s *Scenario) AssertBasicAuthChallenge(realm string) error {
+	re := regexp.MustCompile(`(?i)^basic\s+(?:.*,\s*)?realm="` + regexp.QuoteMeta(realm) + `"`)
+
+	return matchHeader("response", s.CapturedResponse.Headers, "WWW-Authenticate", fmt.Sprintf("with the Basic scheme and the realm %q", realm), re.MatchString)
+}
+
+// AssertResponseHeaderListContains returns an error if none of the captured response headerKey values is a
+// comma separated list containing the element, compared without case, like the methods of Access-Control-Allow-Methods
+func (s *Scenario) AssertResponseHeaderListContains(headerKey string, element string) error {
+	return matchHeader("response", s.CapturedResponse.Headers, headerKey, fmt.Sprintf("listing %v", element), func(value string) bool {
+		for _, listed := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(listed), element) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// AssertResponseHeaderNotValue returns an error if any of the captured response headerKey values is the value.
+// Responses without the header do not have the value.
+func (s *Scenario) AssertResponseHeaderNotValue(headerKey string, value string) error {
+	for _, headerValue := range nethttp.Header(s.CapturedResponse.Headers).Values(headerKey) {
+		if headerValue == value {
+			return fmt.Errorf("expected response header %v to not be %v", headerKey, value)
+		}
+	}
+
+	return nil
+}
+
+// AssertResponseHeaderAbsent returns an error if the captured response headers contain the headerKey
+func (s *Scenario) AssertResponseHeaderAbsent(headerKey string) error {
+	if headerValues := nethttp.Header(s.CapturedResponse.Headers).Values(headerKey); len(headerValues) != 0 {
+		return fmt.Errorf("expected response headers to not contain %v but it contained %v", headerKey, headerValues)
+	}
+
+	return nil
+}
+
+// AssertRequestHeaderAbsent returns an error if the captured request headers contain the headerKey,
+// meaning the header was forwarded to the backend service
+func (s *Scenario) AssertRequestHeaderAbsent(headerKey string) error {
+	if headerValues := nethttp.Header(s.CapturedRequest.Headers).Values(headerKey); len(headerValues) != 0 {
+		return fmt.Errorf("expected request headers to not contain %v but it contained %v", headerKey, headerValues)
+	}
+
+	return nil
+}
+
+// AssertForwardedProto returns an error if the protocol forwarded to the backend service does not match the expected value.
+// The X-Forwarded-Proto header is checked first, falling back to the last element of the Forwarded header.
+func (s *Scenario) AssertForwardedProto(proto string) error {
+	forwarded := s.CapturedRequest.ForwardedHeaders()
+
+	received := forwarded.XForwardedProto
+	if received == "" && len(forwarded.Forwarded) != 0 {
+		received = forwarded.Forwarded[len(forwarded.Forwarded)-1].Proto
+	}
+
+	if received != proto {
+		return fmt.Errorf("expected the forwarded protocol to be %v but it was %q (headers %v)", proto, received, s.CapturedRequest.Headers)
+	}
+
+	return nil
+}
+
+// AssertForwardedHost returns an error if the host forwarded to the backend service does not match the expected value.
+// The X-Forwarded-Host header is checked first, falling back to the last element of the Forwarded header.
+func (s *Scenario) AssertForwardedHost(host string) error {
+	forwarded := s.CapturedRequest.ForwardedHeaders()
+
+	received := forwarded.XForwardedHost
+	if received == "" && len(forwarded.Forwarded) != 0 {
+		received = forwarded.Forwarded[len(forwarded.Forwarded)-1].Host
+	}
+
+	if received != host {
+		return fmt.Errorf("expected the forwarded host to be %v but it was %q (headers %v)", host, received, s.CapturedRequest.Headers)
+	}
+
+	return nil
+}
+
+// AssertForwardedPort returns an error if the X-Forwarded-Port header received by the backend service does not match the expected value
+func (s *Scenario) AssertForwardedPort(port string) error {
+	forwarded := s.CapturedRequest.ForwardedHeaders()
+	if forwarded.XForwardedPort != port {
+		return fmt.Errorf("expected the forwarded port to be %v but it was %q (headers %v)", port, forwarded.XForwardedPort, s.CapturedRequest.Headers)
+	}
+
+	return nil
+}
+
+// AssertClientIPAppended returns an error if the X-Forwarded-For chain received by the backend service
+// does not end with a valid IP address, the client address seen by the ingress controller.
+// When the scenario sends its own X-Forwarded-For header, the last address sent cannot be
+// the last one received, because that means the controller forwarded the chain unchanged.
+func (s *Scenario) AssertClientIPAppended() error {
+	chain := s.CapturedRequest.ForwardedHeaders().XForwardedFor
+	if len(chain) == 0 {
+		return fmt.Errorf("expected the request to contain an X-Forwarded-For header but it only contained %v", s.CapturedRequest.Headers)
+	}
+
+	last := chain[len(chain)-1]
+	if net.ParseIP(last) == nil {
+		return fmt.Errorf("expected the X-Forwarded-For chain %v to end with a client IP address but it ends with %v", chain, last)
+	}
+
+	sent := s.RequestHeaders.Values("X-Forwarded-For")
+	if len(sent) != 0 {
+		sentChain := strings.Split(sent[len(sent)-1], ",")
+		if strings.TrimSpace(sentChain[len(sentChain)-1]) == last {
+			return fmt.Errorf("expected the client IP address to be appended to the X-Forwarded-For chain %v but it was forwarded unchanged", chain)
+		}
+	}
+
+	return nil
+}
+
+// AssertProxyProtocolVersion returns an error if the backend service did not receive a PROXY protocol header of
+// the expected version. A zero version asserts the backend did not receive a PROXY protocol header.
+func (s *Scenario) AssertProxyProtocolVersion(version int) error {
+	received := 0
+	if s.CapturedRequest.ProxyProtocol != nil {
+		received = s.CapturedRequest.ProxyProtocol.Version
+	}
+
+	if received != version {
+		return fmt.Errorf("expected the backend to receive PROXY protocol version %v but it received %v", version, received)
+	}
+
+	return nil
+}
+
+// AssertRemoteAddressFamily returns an error if the connection of the captured round trip
+// did not use an address of the expected family (ipv4 or ipv6) to reach the ingress controller
+func (s *Scenario) AssertRemoteAddressFamily(family string) error {
+	host, _, err := net.SplitHostPort(s.CapturedResponse.RemoteAddress)
+	if err != nil {
+		return fmt.Errorf("unexpected remote address %q: %w", s.CapturedResponse.RemoteAddress, err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !http.IsIPFamily(ip, strings.ToLower(family)) {
+		return fmt.Errorf("expected the request to be sent to an %v address but it was sent to %v", family, host)
+	}
+
+	return nil
+}
+
+// AssertResponseCookie returns an error if the captured response does not set a cookie with the expected name
+func (s *Scenario) AssertResponseCookie(name string) error {
+	response := &nethttp.Response{Header: s.CapturedResponse.Headers}
+
+	var names []string
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == name {
+			return nil
+		}
+
+		names = append(names, cookie.Name)
+	}
+
+	return fmt.Errorf("expected the response to set a cookie named %v but it only set %v", name, names)
+}
+
+// AssertRawClientError returns an error if the status code of the HTTP response received in the last raw exchange
+// is not a client error (4xx), e.g. when the ingress controller forwarded an ambiguous request to the backend
+func (s *Scenario) AssertRawClientError() error {
+	if s.CapturedConnection == nil {
+		return fmt.Errorf("raw response assertions require a raw TCP or TLS exchange first")
+	}
+
+	if statusCode := s.CapturedConnection.StatusCode(); statusCode < 400 || statusCode > 499 {
+		statusLine := ""
+		if len(s.CapturedConnection.Lines) != 0 {
+			statusLine = s.CapturedConnection.Lines[0]
+		}
+
+		return fmt.Errorf("expected a client error (4xx) status code in the raw response but its status line was %q", statusLine)
+	}
+
+	return nil
+}
+
+// AssertRawResponseLine returns an error if none of the lines received in the last raw exchange contains the expected text
+func (s *Scenario) AssertRawResponseLine(text string) error {
+	if s.CapturedConnection == nil {
+		return fmt.Errorf("raw response assertions require a raw TCP or TLS exchange first")
+	}
+
+	for _, line := range s.CapturedConnection.Lines {
+		if strings.Contains(line, text) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected the raw response to contain a line with %q but it contained %q", text, s.CapturedConnection.Lines)
+}
+
+// AssertRawStatusCodeOneOf returns an error if the status code of the HTTP response received in the
+// last raw exchange does not match any of the expected values
+func (s *Scenario) AssertRawStatusCodeOneOf(statusCodes ...int) error {
+	if s.CapturedConnection == nil {
+		return fmt.Errorf("raw response assertions require a raw TCP or TLS exchange first")
+	}
+
+	for _, statusCode := range statusCodes {
+		if s.CapturedConnection.StatusCode() == statusCode {
+			return nil
+		}
+	}
+
+	statusLine := ""
+	if len(s.CapturedConnection.Lines) != 0 {
+		statusLine = s.CapturedConnection.Lines[0]
+	}
+
+	return fmt.Errorf("expected one of the status codes %v in the raw response but its status line was %q", statusCodes, statusLine)
+}
+
+// AssertRawServedBy returns an error if the echo payload received in the last raw exchange was not sent by the
+// expected service. With served set to false, it returns an error if the payload was sent by the service.
+func (s *Scenario) AssertRawServedBy(service string, served bool) error {
+	if s.CapturedConnection == nil {
+		return fmt.Errorf("raw response assertions require a raw TCP or TLS exchange first")
+	}
+
+	// the echoserver indents its payload, so the service is alone in its line
+	field := fmt.Sprintf("%q: %q", "service", service)
+
+	found := false
+	for _, line := range s.CapturedConnection.Lines {
+		if strings.Contains(line, field) {
+			found = true
+			break
+		}
+	}
+
+	switch {
+	case served && !found:
+		return fmt.Errorf("expected the raw request to be served by %v but the response was %q", service, s.CapturedConnection.Lines)
+	case !served && found:
+		return fmt.Errorf("expected the raw request not to be served by %v but it was", service)
+	}
+
+	return nil
+}
+
+// AssertConnectionCertificate returns an error if the certificate presented in the last raw TLS exchange is not valid
+// for the hostname. With TLS passthrough, the certificate must be the one of the backend, not the ingress controller.
+func (s *Scenario) AssertConnectionCertificate(hostname string) error {
+	if s.CapturedConnection == nil || s.CapturedConnection.Certificate == nil {
+		return fmt.Errorf("certificate verification requires a raw TLS exchange first")
+	}
+
+	return s.CapturedConnection.Certificate.VerifyHostname(hostname)
+}
+
 // AssertResponseCertificate returns nil if the captured certificate for the named host is valid.
 // Otherwise it returns an error describing the mismatch.
 func (s *Scenario) AssertResponseCertificate(hostname string) error {
@@ -241,3 +1389,424 @@ func (s *Scenario) AssertResponseCertificate(hostname string) error {
 
 	return s.CapturedResponse.Certificate.VerifyHostname(hostname)
 }
+
+// AssertResponseCertificateIs returns an error if the certificate presented in the captured response
+// is not the expected one
+func (s *Scenario) AssertResponseCertificateIs(certificate *x509.Certificate) error {
+	if s.CapturedResponse == nil || s.CapturedResponse.Certificate == nil {
+		return fmt.Errorf("certificate verification requires executing a request and also target an HTTPS URL")
+	}
+
+	if !s.CapturedResponse.Certificate.Equal(certificate) {
+		return fmt.Errorf("expected the certificate of %v (serial number %v) but the certificate of %v (serial number %v) was presented",
+			certificate.Subject, certificate.SerialNumber, s.CapturedResponse.Certificate.Subject, s.CapturedResponse.Certificate.SerialNumber)
+	}
+
+	return nil
+}
+
+// AssertResponseCertificateNotValidFor returns an error if the certificate presented in the captured
+// response is valid for the hostname
+func (s *Scenario) AssertResponseCertificateNotValidFor(hostname string) error {
+	if s.CapturedResponse == nil || s.CapturedResponse.Certificate == nil {
+		return fmt.Errorf("certificate verification requires executing a request and also target an HTTPS URL")
+	}
+
+	if s.CapturedResponse.Certificate.VerifyHostname(hostname) == nil {
+		return fmt.Errorf("expected a certificate not valid for %v but the certificate of %v (serial number %v) was presented",
+			hostname, s.CapturedResponse.Certificate.Subject, s.CapturedResponse.Certificate.SerialNumber)
+	}
+
+	return nil
+}
+
+// AssertSameCertificate returns an error if the round trips captured in the scenario did not all present
+// the same certificate. Round trips without certificate, like failed TLS handshakes, are ignored.
+func (s *Scenario) AssertSameCertificate() error {
+	var first *CaptureAttempt
+	for _, attempt := range s.History() {
+		if attempt.RoundTrip == nil || attempt.RoundTrip.Response.Certificate == nil {
+			continue
+		}
+
+		if first == nil {
+			first = attempt
+			continue
+		}
+
+		if !attempt.RoundTrip.Response.Certificate.Equal(first.RoundTrip.Response.Certificate) {
+			return fmt.Errorf("expected the same certificate in all the round trips but %v presented serial number %v and %v presented serial number %v",
+				first.URL, first.RoundTrip.Response.Certificate.SerialNumber, attempt.URL, attempt.RoundTrip.Response.Certificate.SerialNumber)
+		}
+	}
+
+	return nil
+}
+
+// AssertStreamEventCount returns an error if the captured stream does not contain the expected number of events
+func (s *Scenario) AssertStreamEventCount(events int) error {
+	if s.CapturedStream == nil {
+		return fmt.Errorf("stream assertions require capturing a stream first")
+	}
+
+	if len(s.CapturedStream.Events) != events {
+		return fmt.Errorf("expected the stream to contain %v events but %v were received", events, len(s.CapturedStream.Events))
+	}
+
+	return nil
+}
+
+// AssertStreamNotBuffered returns an error if the events of the captured stream were delivered
+// together, which happens when a proxy buffers the response until the backend completes it.
+// The first event must be received at least minSpread before the last one.
+func (s *Scenario) AssertStreamNotBuffered(minSpread time.Duration) error {
+	if s.CapturedStream == nil {
+		return fmt.Errorf("stream assertions require capturing a stream first")
+	}
+
+	events := s.CapturedStream.Events
+	if len(events) < 2 {
+		return fmt.Errorf("expected at least two stream events to check buffering but %v were received", len(events))
+	}
+
+	spread := events[len(events)-1].ReceivedAt.Sub(events[0].ReceivedAt)
+	if spread < minSpread {
+		return fmt.Errorf("expected the stream events to be received over at least %v but all arrived within %v (response buffered?)", minSpread, spread)
+	}
+
+	return nil
+}
+
+// AssertAllStatusCodes returns an error if any of the round trips captured
+// with CaptureMultipleRoundTrips returned an unexpected status code
+func (s *Scenario) AssertAllStatusCodes(statusCode int) error {
+	if len(s.CapturedRoundTrips) == 0 {
+		return fmt.Errorf("no responses were captured")
+	}
+
+	statusCodes := map[int]int{}
+	for _, roundTrip := range s.CapturedRoundTrips {
+		statusCodes[roundTrip.Response.StatusCode]++
+	}
+
+	if statusCodes[statusCode] != len(s.CapturedRoundTrips) {
+		return fmt.Errorf("expected all the responses to return status code %v but got %v", statusCode, statusCodes)
+	}
+
+	return nil
+}
+
+// AssertRateLimited returns an error if the round trips captured with CaptureMultipleRoundTrips were not
+// partially rejected with RateLimitStatusCode. The requests allowed by the rate limit must succeed.
+func (s *Scenario) AssertRateLimited() error {
+	if len(s.CapturedRoundTrips) == 0 {
+		return fmt.Errorf("no responses were captured")
+	}
+
+	statusCodes := map[int]int{}
+	for _, roundTrip := range s.CapturedRoundTrips {
+		statusCodes[roundTrip.Response.StatusCode]++
+	}
+
+	if statusCodes[RateLimitStatusCode] == 0 {
+		return fmt.Errorf("expected some responses to return the rate limit status code %v but got %v", RateLimitStatusCode, statusCodes)
+	}
+
+	if statusCodes[nethttp.StatusOK] == 0 || statusCodes[nethttp.StatusOK]+statusCodes[RateLimitStatusCode] != len(s.CapturedRoundTrips) {
+		return fmt.Errorf("expected the responses to return status code %v until the rate limit and %v after it but got %v",
+			nethttp.StatusOK, RateLimitStatusCode, statusCodes)
+	}
+
+	return nil
+}
+
+// AssertValidRetryAfter returns an error if a response rejected by the rate limit, in the round trips captured
+// with CaptureMultipleRoundTrips, contains a Retry-After header that is neither a number of seconds nor an HTTP date
+func (s *Scenario) AssertValidRetryAfter() error {
+	for _, roundTrip := range s.CapturedRoundTrips {
+		if roundTrip.Response.StatusCode != RateLimitStatusCode {
+			continue
+		}
+
+		retryAfter := nethttp.Header(roundTrip.Response.Headers).Get("Retry-After")
+		if retryAfter == "" {
+			continue
+		}
+
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			continue
+		}
+
+		if _, err := nethttp.ParseTime(retryAfter); err != nil {
+			return fmt.Errorf("expected the Retry-After header to be a number of seconds or an HTTP date but it was %q", retryAfter)
+		}
+	}
+
+	return nil
+}
+
+// AssertServedByAtLeastNPods returns an error if the round trips captured
+// with CaptureMultipleRoundTrips were served by less than the expected number of pods
+func (s *Scenario) AssertServedByAtLeastNPods(pods int) error {
+	servedBy := s.servedByPods()
+	if len(servedBy) < pods {
+		return fmt.Errorf("expected the requests to be served by at least %v different pods but %v were used: %v", pods, len(servedBy), servedBy)
+	}
+
+	return nil
+}
+
+// AssertRoughlyEvenDistribution returns an error if a ready endpoint of the service served none of the round trips
+// captured with CaptureMultipleRoundTrips, or if the number of round trips served by any of them deviates from the
+// mean over all the ready endpoints more than the tolerance, expressed as a percentage of the mean.
+// The round trips served by other pods are ignored.
+func (s *Scenario) AssertRoughlyEvenDistribution(service string, tolerance int) error {
+	endpoints, err := kubernetes.ReadyEndpoints(s.ctx, kubernetes.KubeClient, s.Namespace, service)
+	if err != nil {
+		return err
+	}
+
+	if len(endpoints) == 0 {
+		return fmt.Errorf("the service %v does not have ready endpoints", service)
+	}
+
+	servedBy := map[string]int{}
+	for _, endpoint := range endpoints {
+		servedBy[endpoint] = 0
+	}
+
+	total := 0
+	for _, roundTrip := range s.CapturedRoundTrips {
+		endpoint := roundTrip.Request.Pod
+		if _, ok := servedBy[endpoint]; !ok {
+			endpoint = roundTrip.Request.PodIP
+		}
+
+		if _, ok := servedBy[endpoint]; !ok {
+			continue
+		}
+
+		servedBy[endpoint]++
+		total++
+	}
+
+	mean := float64(total) / float64(len(endpoints))
+	maxDeviation := mean * float64(tolerance) / 100
+
+	for _, endpoint := range endpoints {
+		if servedBy[endpoint] == 0 {
+			return fmt.Errorf("expected every ready endpoint of the service %v to serve requests but %v served none: %v",
+				service, endpoint, servedBy)
+		}
+
+		if deviation := math.Abs(float64(servedBy[endpoint]) - mean); deviation > maxDeviation {
+			return fmt.Errorf("expected every ready endpoint of the service %v to serve %.1f (±%v%%) requests but %v served %v: %v",
+				service, mean, tolerance, endpoint, servedBy[endpoint], servedBy)
+		}
+	}
+
+	return nil
+}
+
+// AssertServiceShare returns an error if the share of the round trips captured with CaptureMultipleRoundTrips
+// served by the service differs from the expected percentage more than CanaryWeightTolerance percentage points
+func (s *Scenario) AssertServiceShare(service string, percentage int) error {
+	servedBy := s.servedBy(func(request *http.CapturedRequest) string { return request.Service })
+
+	total := 0
+	for _, count := range servedBy {
+		total += count
+	}
+
+	if total == 0 {
+		return fmt.Errorf("no requests were served by a backend pod")
+	}
+
+	share := float64(servedBy[service]) * 100 / float64(total)
+	if math.Abs(share-float64(percentage)) > float64(CanaryWeightTolerance) {
+		return fmt.Errorf("expected the service %v to serve %v%% (±%v) of the requests but it served %.1f%%: %v",
+			service, percentage, CanaryWeightTolerance, share, servedBy)
+	}
+
+	return nil
+}
+
+// AssertServedBySamePod returns an error if the last captured request and the round trips
+// captured with CaptureMultipleRoundTrips were not all served by the same backend pod
+func (s *Scenario) AssertServedBySamePod() error {
+	pod := ""
+	if s.CapturedRequest != nil {
+		pod = s.CapturedRequest.PodIdentity()
+	}
+
+	for _, roundTrip := range s.CapturedRoundTrips {
+		if pod == "" {
+			pod = roundTrip.Request.PodIdentity()
+		}
+
+		if roundTrip.Request.PodIdentity() != pod {
+			return fmt.Errorf("expected all the requests to be served by pod %v but some were served by other pods: %v", pod, s.servedByPods())
+		}
+	}
+
+	if pod == "" {
+		return fmt.Errorf("no requests were served by a backend pod")
+	}
+
+	return nil
+}
+
+// AssertServedByAtLeastNNodes returns an error if the round trips captured with CaptureMultipleRoundTrips
+// were served by pods running in fewer than the expected number of nodes
+func (s *Scenario) AssertServedByAtLeastNNodes(nodes int) error {
+	servedBy := s.servedBy(func(request *http.CapturedRequest) string { return request.Node })
+	if len(servedBy) < nodes {
+		return fmt.Errorf("expected the requests to be served by pods in at least %v different nodes but %v were used: %v", nodes, len(servedBy), servedBy)
+	}
+
+	return nil
+}
+
+// AssertServedByZone returns an error if any of the round trips captured with CaptureMultipleRoundTrips
+// was served by a pod outside of the zone. Pods without zone do not belong to any zone.
+func (s *Scenario) AssertServedByZone(zone string) error {
+	servedBy := s.servedBy(func(request *http.CapturedRequest) string { return request.Zone })
+	if len(servedBy) == 0 {
+		return fmt.Errorf("no requests were served by a backend pod")
+	}
+
+	for servedByZone := range servedBy {
+		if servedByZone != zone {
+			return fmt.Errorf("expected all the requests to be served by pods in zone %v but they were served in zones %v", zone, servedBy)
+		}
+	}
+
+	return nil
+}
+
+// AssertNotCached returns an error if any of the round trips captured with CaptureMultipleRoundTrips
+// did not reach a backend pod, because its response was served from a cache
+func (s *Scenario) AssertNotCached() error {
+	nonces, err := s.responseNonces()
+	if err != nil {
+		return err
+	}
+
+	for nonce, count := range nonces {
+		if count > 1 {
+			return fmt.Errorf("expected every request to reach a backend pod but the response %v was sent %v times", nonce, count)
+		}
+	}
+
+	return nil
+}
+
+// AssertCached returns an error if every round trip captured with CaptureMultipleRoundTrips
+// reached a backend pod, so none of the responses was served from a cache
+func (s *Scenario) AssertCached() error {
+	nonces, err := s.responseNonces()
+	if err != nil {
+		return err
+	}
+
+	if len(nonces) == len(s.CapturedRoundTrips) {
+		return fmt.Errorf("expected the ingress controller to send cached responses but the %v requests reached a backend pod", len(s.CapturedRoundTrips))
+	}
+
+	return nil
+}
+
+// responseNonces returns the number of round trips captured with CaptureMultipleRoundTrips
+// that received the echo response with each nonce
+func (s *Scenario) responseNonces() (map[string]int, error) {
+	nonces := map[string]int{}
+	for _, roundTrip := range s.CapturedRoundTrips {
+		if roundTrip.Request.Nonce == "" {
+			return nil, fmt.Errorf("the response did not contain the nonce of an echo response (status code %v)", roundTrip.Response.StatusCode)
+		}
+
+		nonces[roundTrip.Request.Nonce]++
+	}
+
+	if len(nonces) == 0 {
+		return nil, fmt.Errorf("no responses were captured")
+	}
+
+	return nonces, nil
+}
+
+// AssertUpstreamKeepAlive returns an error if none of the connections from the ingress controller to the backend
+// pods received more than one of the round trips captured with CaptureMultipleRoundTrips
+func (s *Scenario) AssertUpstreamKeepAlive() error {
+	requests, err := s.upstreamConnectionRequests()
+	if err != nil {
+		return err
+	}
+
+	for _, count := range requests {
+		if count > 1 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected the ingress controller to reuse its connections to the backend pods but every request used a new connection: %v", requests)
+}
+
+// AssertUpstreamConnectionPerRequest returns an error if a connection from the ingress controller to a backend
+// pod received more than one request, including requests sent before the round trips captured with CaptureMultipleRoundTrips
+func (s *Scenario) AssertUpstreamConnectionPerRequest() error {
+	requests, err := s.upstreamConnectionRequests()
+	if err != nil {
+		return err
+	}
+
+	for connection, count := range requests {
+		if count > 1 {
+			return fmt.Errorf("expected the ingress controller to open a connection to the backend pods per request but connection %v received %v requests", connection, count)
+		}
+	}
+
+	return nil
+}
+
+// upstreamConnectionRequests returns the highest number of requests received by each connection to the backend pods
+// in the round trips captured with CaptureMultipleRoundTrips, by pod and connection
+func (s *Scenario) upstreamConnectionRequests() (map[string]uint64, error) {
+	requests := map[string]uint64{}
+	for _, roundTrip := range s.CapturedRoundTrips {
+		if roundTrip.Request.Connection == nil {
+			return nil, fmt.Errorf("the backend of the request did not report its connection (served by %v)", roundTrip.Request.Service)
+		}
+
+		connection := fmt.Sprintf("%v#%v", roundTrip.Request.PodIdentity(), roundTrip.Request.Connection.ID)
+		if roundTrip.Request.Connection.Requests > requests[connection] {
+			requests[connection] = roundTrip.Request.Connection.Requests
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("no requests were served by a backend pod")
+	}
+
+	return requests, nil
+}
+
+// servedByPods returns the number of captured round trips served by each backend pod
+func (s *Scenario) servedByPods() map[string]int {
+	return s.servedBy((*http.CapturedRequest).PodIdentity)
+}
+
+// servedBy returns the number of captured round trips served by the backend pods with each identity
+// (e.g. their name or their node). Round trips not served by a backend pod are ignored.
+func (s *Scenario) servedBy(identity func(*http.CapturedRequest) string) map[string]int {
+	servedBy := map[string]int{}
+	for _, roundTrip := range s.CapturedRoundTrips {
+		if roundTrip.Request.Pod == "" {
+			continue
+		}
+
+		servedBy[identity(roundTrip.Request)]++
+	}
+
+	return servedBy
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"regexp"
+	"sync"
+
+	"github.com/cucumber/godog"
+
+	"sigs.k8s.io/ingress-controller-conformance/test/kubernetes"
+)
+
+// variableRegexp matches the references to scenario variables in the steps, like ${firstPod}
+var variableRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_-]*)\}`)
+
+// running contains the state of the scenarios running, so the steps shared by all the features can find it
+var running = struct {
+	sync.Mutex
+	scenarios map[*godog.Scenario]*Scenario
+}{
+	scenarios: map[*godog.Scenario]*Scenario{},
+}
+
+// SetVariable sets the value of a scenario variable, available as ${name} in the following steps
+func (s *Scenario) SetVariable(name, value string) {
+	s.variablesMu.Lock()
+	defer s.variablesMu.Unlock()
+
+	if s.variables == nil {
+		s.variables = map[string]string{}
+	}
+
+	s.Log(LogScenario, "Setting variable", "name", name, "value", value)
+	s.variables[name] = value
+}
+
+// Variable returns the value of a scenario variable
+func (s *Scenario) Variable(name string) (string, bool) {
+	s.variablesMu.Lock()
+	defer s.variablesMu.Unlock()
+
+	value, ok := s.variables[name]
+	return value, ok
+}
+
+// Expand replaces the references to scenario variables in the text by their values. The references to
+// undefined variables are kept, so the steps using them fail with the reference in their error.
+func (s *Scenario) Expand(text string) string {
+	return variableRegexp.ReplaceAllStringFunc(text, func(reference string) string {
+		name := variableRegexp.FindStringSubmatch(reference)[1]
+
+		value, ok := s.Variable(name)
+		if !ok {
+			s.Log(LogScenario, "Undefined variable", "name", name)
+			return reference
+		}
+
+		return value
+	})
+}
+
+// CaptureResponseHeader sets a scenario variable to the value of a header of the captured response
+func (s *Scenario) CaptureResponseHeader(name, key string) error {
+	if s.CapturedResponse == nil {
+		return fmt.Errorf("the %v variable requires a previous response", name)
+	}
+
+	value := nethttp.Header(s.CapturedResponse.Headers).Get(key)
+	if value == "" {
+		return fmt.Errorf("expected the response to contain the %v header but it only contained %v", key, s.CapturedResponse.Headers)
+	}
+
+	s.SetVariable(name, value)
+	return nil
+}
+
+// CaptureResponseCookie sets a scenario variable to the value of a cookie set by the captured response
+func (s *Scenario) CaptureResponseCookie(name, cookieName string) error {
+	if s.CapturedResponse == nil {
+		return fmt.Errorf("the %v variable requires a previous response", name)
+	}
+
+	response := &nethttp.Response{Header: s.CapturedResponse.Headers}
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == cookieName {
+			s.SetVariable(name, cookie.Value)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("expected the response to set a cookie named %v", cookieName)
+}
+
+// CaptureServingPod sets a scenario variable to the name of the pod that served the captured request
+func (s *Scenario) CaptureServingPod(name string) error {
+	if s.CapturedRequest == nil || s.CapturedRequest.Pod == "" {
+		return fmt.Errorf("the %v variable requires a previous request served by a backend pod", name)
+	}
+
+	s.SetVariable(name, s.CapturedRequest.Pod)
+	return nil
+}
+
+// AssertServedByPod returns an error if the captured request was not served by the pod
+func (s *Scenario) AssertServedByPod(pod string) error {
+	if s.CapturedRequest == nil || s.CapturedRequest.Pod != pod {
+		served := ""
+		if s.CapturedRequest != nil {
+			served = s.CapturedRequest.Pod
+		}
+
+		return fmt.Errorf("expected the request to be served by pod %v but it was served by %q", pod, served)
+	}
+
+	return nil
+}
+
+// RegisterVariables expands the references to scenario variables in the text, doc strings and tables
+// of the steps before they run, and adds the steps capturing values of the responses into variables
+func RegisterVariables(ctx *godog.ScenarioContext) {
+	var sc *godog.Scenario
+
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		sc = scenario
+	})
+
+	ctx.BeforeStep(func(step *godog.Step) {
+		s := runningScenario(sc)
+		if s == nil {
+			return
+		}
+
+		step.Text = s.Expand(step.Text)
+
+		if step.Argument == nil {
+			return
+		}
+
+		if docString := step.Argument.GetDocString(); docString != nil {
+			docString.Content = s.Expand(docString.Content)
+		}
+
+		if table := step.Argument.GetDataTable(); table != nil {
+			for _, row := range table.Rows {
+				for _, cell := range row.Cells {
+					cell.Value = s.Expand(cell.Value)
+				}
+			}
+		}
+	})
+
+	ctx.Step(`^I save the "([^"]*)" header of the response as "([^"]*)"$`, func(key, name string) error {
+		return withRunningScenario(sc, func(s *Scenario) error {
+			return s.CaptureResponseHeader(name, key)
+		})
+	})
+
+	ctx.Step(`^I save the "([^"]*)" cookie of the response as "([^"]*)"$`, func(cookie, name string) error {
+		return withRunningScenario(sc, func(s *Scenario) error {
+			return s.CaptureResponseCookie(name, cookie)
+		})
+	})
+
+	ctx.Step(`^I save the pod serving the request as "([^"]*)"$`, func(name string) error {
+		return withRunningScenario(sc, func(s *Scenario) error {
+			return s.CaptureServingPod(name)
+		})
+	})
+
+	ctx.Step(`^the request must be served by the "([^"]*)" pod$`, func(pod string) error {
+		return withRunningScenario(sc, func(s *Scenario) error {
+			return s.AssertServedByPod(pod)
+		})
+	})
+}
+
+// RegisterWorker makes the scenarios run by a worker of the suite, numbered from 1, use the hostname suffix
+// of the worker in the manifests of their namespaces, also available to the steps as ${hostSuffix}
+func RegisterWorker(ctx *godog.ScenarioContext, worker int) {
+	ctx.BeforeScenario(func(scenario *godog.Scenario) {
+		s := runningScenario(scenario)
+		if s == nil {
+			return
+		}
+
+		s.ctx = kubernetes.WithWorker(s.ctx, worker)
+		s.SetVariable("hostSuffix", kubernetes.WorkerHostSuffix(worker))
+	})
+}
+
+// runningScenario returns the state of a running scenario, nil when the feature does not use a state
+func runningScenario(sc *godog.Scenario) *Scenario {
+	running.Lock()
+	defer running.Unlock()
+
+	return running.scenarios[sc]
+}
+
+func withRunningScenario(sc *godog.Scenario, fn func(s *Scenario) error) error {
+	s := runningScenario(sc)
+	if s == nil {
+		return fmt.Errorf("the scenario %v does not have a state", sc.Name)
+	}
+
+	return fn(s)
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records a trace of each scenario, with spans for its steps, round trips
+// and Kubernetes operations, and exports it to an OpenTelemetry collector using OTLP/HTTP.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cucumber/godog"
+)
+
+// Endpoint base URL of the OTLP/HTTP endpoint of the OpenTelemetry collector (e.g. http://localhost:4318).
+// Tracing is disabled when empty.
+var Endpoint = ""
+
+// Span is an operation of a scenario. The methods of a nil Span do nothing, so
+// the instrumented code does not need to check if tracing is enabled.
+type Span struct {
+	trace *trace
+
+	id       string
+	parentID string
+	name     string
+	kind     int
+
+	start time.Time
+	end   time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        error
+}
+
+// span kinds of the OTLP protocol
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// trace contains the spans of a scenario
+type trace struct {
+	id string
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+type spanKey struct{}
+
+// scenarios contains the root span of the scenarios running, features can run concurrently
+var scenarios = struct {
+	sync.Mutex
+	spans map[*godog.Scenario]*Span
+}{
+	spans: map[*godog.Scenario]*Span{},
+}
+
+// StartScenario starts the trace of a scenario, with a root span that ends when
+// the scenario finishes, and returns a context containing the root span
+func StartScenario(ctx context.Context, sc *godog.Scenario) context.Context {
+	if Endpoint == "" || sc == nil {
+		return ctx
+	}
+
+	span := &Span{
+		trace: &trace{id: randomID(16)},
+		id:    randomID(8),
+		name:  sc.Name,
+		kind:  kindInternal,
+		start: time.Now(),
+		attributes: map[string]interface{}{
+			"scenario.feature": sc.Uri,
+		},
+	}
+
+	scenarios.Lock()
+	scenarios.spans[sc] = span
+	scenarios.Unlock()
+
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// Start starts a span, child of the span in the context, and returns a context containing
+// the new span. It returns a nil span when the context does not belong to a traced scenario.
+func Start(ctx context.Context, name string, attributes ...interface{}) (context.Context, *Span) {
+	return start(ctx, name, kindInternal, attributes)
+}
+
+// StartClient starts a span of a request sent to a remote server, like Start
+func StartClient(ctx context.Context, name string, attributes ...interface{}) (context.Context, *Span) {
+	return start(ctx, name, kindClient, attributes)
+}
+
+func start(ctx context.Context, name string, kind int, attributes []interface{}) (context.Context, *Span) {
+	parent, ok := ctx.Value(spanKey{}).(*Span)
+	if !ok || parent == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		trace:      parent.trace,
+		id:         randomID(8),
+		parentID:   parent.id,
+		name:       name,
+		kind:       kind,
+		start:      time.Now(),
+		attributes: map[string]interface{}{},
+	}
+
+	span.SetAttributes(attributes...)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds key/value pairs to the attributes of the span
+func (s *Span) SetAttributes(keysAndValues ...interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		s.attributes[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+}
+
+// End ends the span, failed with the error if it is not nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.end = time.Now()
+	s.err = err
+	s.mu.Unlock()
+
+	s.trace.mu.Lock()
+	s.trace.spans = append(s.trace.spans, s)
+	s.trace.mu.Unlock()
+}
+
+// Register records a span for each step of the scenarios run in the context,
+// and exports the trace of each scenario when it finishes
+func Register(ctx *godog.ScenarioContext) {
+	var scenario *godog.Scenario
+	var steps map[string]*Span
+
+	ctx.BeforeScenario(func(sc *godog.Scenario) {
+		scenario = sc
+		steps = map[string]*Span{}
+	})
+
+	ctx.BeforeStep(func(st *godog.Step) {
+		scenarios.Lock()
+		root, ok := scenarios.spans[scenario]
+		scenarios.Unlock()
+
+		if !ok {
+			return
+		}
+
+		_, span := Start(context.WithValue(context.Background(), spanKey{}, root), st.Text)
+		steps[st.Id] = span
+	})
+
+	ctx.AfterStep(func(st *godog.Step, err error) {
+		steps[st.Id].End(err)
+	})
+
+	ctx.AfterScenario(func(sc *godog.Scenario, err error) {
+		scenarios.Lock()
+		root, ok := scenarios.spans[sc]
+		delete(scenarios.spans, sc)
+		scenarios.Unlock()
+
+		if !ok {
+			return
+		}
+
+		root.End(err)
+		export(root.trace)
+	})
+}
+
+// randomID returns a random identifier of n bytes, hex encoded
+func randomID(n int) string {
+	id := make([]byte, n)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}